@@ -1,17 +1,32 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"math"
 	"os"
 	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"songbattle/internal/analysis"
 	"songbattle/internal/auth"
+	"songbattle/internal/config"
+	"songbattle/internal/elo"
+	"songbattle/internal/export"
+	"songbattle/internal/logging"
+	"songbattle/internal/matchmaker"
 	"songbattle/internal/models"
 	"songbattle/internal/spotify"
 	"songbattle/internal/store"
 	"songbattle/internal/ui"
+	"strconv"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	spotifyapi "github.com/zmb3/spotify/v2"
@@ -21,7 +36,32 @@ const (
 	AppName         = "Song Battle"
 	AppVersion      = "1.0.1"
 	DBName          = "songbattle.db"
+	ConfigName      = "config.yaml"
 	DefaultClientID = "c0bf7a0584f544dbb3e6fc14dce4716c" // Public default Client ID
+
+	// MaxArtistImport caps the number of tracks imported by -import-artist
+	MaxArtistImport = 200
+
+	// MaxRankingDisagreements caps the disagreements listed by -compare-ranking
+	MaxRankingDisagreements = 10
+
+	// MaxAutoCalibrateDuels stops -auto-calibrate if calibration never completes
+	MaxAutoCalibrateDuels = 10000
+
+	// DefaultDecisiveMinBattles is the default -min-battles for -min-winrate exports
+	DefaultDecisiveMinBattles = 5
+
+	// DefaultDecisiveLimit caps -min-winrate exports when -limit is not set
+	DefaultDecisiveLimit = 50
+
+	// ListFieldWidth caps artist and title lengths in -list output
+	ListFieldWidth = 40
+
+	// RecomputeReportSize caps the Elo changes printed after -recompute
+	RecomputeReportSize = 10
+
+	// FsckReportSize caps the IDs listed per inconsistency by -fsck
+	FsckReportSize = 10
 )
 
 func main() {
@@ -31,12 +71,57 @@ func main() {
 		redirectURI = flag.String("redirect-uri", "", "Redirect URI (default: auto-detect)")
 		useCustom   = flag.Bool("use-custom-scheme", false, "Force custom scheme 'songbattle://'")
 		useHTTPS    = flag.Bool("use-https", false, "Force HTTPS on localhost:8080")
+		manualAuth  = flag.Bool("manual-auth", false, "Paste the redirect URL instead of using the local callback")
+		reauth      = flag.Bool("reauth", false, "Forget the stored Spotify token and ask for consent again with the current scopes")
+		public      = flag.Bool("public", false, "Create public playlists when exporting (asks for the playlist-modify-public scope)")
 		dbPath      = flag.String("db-path", getDefaultDBPath(), "SQLite database path")
+		configPath  = flag.String("config", getDefaultConfigPath(), "YAML configuration file path")
+		logFile     = flag.String("log-file", "", "Write debug logs (auth, database, Spotify) to this file")
+		noSummary   = flag.Bool("no-summary", false, "Quit instantly without the session summary")
+		noAnimation = flag.Bool("no-animation", false, "Show Elo changes instantly after a vote")
+		skipIntro   = flag.Bool("skip-intro", false, "Never show the first-run introduction")
+		rounds      = flag.Int("rounds", 0, "Quick session: show the session summary after N battles (skips included)")
+		queueFile   = flag.String("queue", "", "Play the battles listed in this file first (one \"left right\" track pair per line)")
+		snipStart   = flag.Duration("snippet-start", 0, "Start playback at this offset (e.g. 45s)")
+		snipLen     = flag.Duration("snippet-len", 0, "Pause playback after this duration (e.g. 20s)")
+		cooldown    = flag.Int("appearance-cooldown", 0, "Keep a track out of the next N battles after it appears (when enough other tracks remain)")
+		matchMode   = flag.String("match-mode", "", "Matchmaking strategy: balanced (default) or uncertainty (most uncertain Elo first)")
+		layout      = flag.String("layout", "", "Duel card layout: horizontal, vertical (stacked) or auto (stacked on narrow terminals)")
 		importData  = flag.Bool("import", false, "Import data from Spotify")
+		finishImp   = flag.Bool("finish-import", false, "Retry tracks deferred by a previous import")
+		importArt   = flag.String("import-artist", "", "Import an artist's catalog (Spotify ID, URI or URL)")
+		listMode    = flag.Bool("list", false, "Print the ranking one line per track and exit")
+		statsMode   = flag.Bool("stats", false, "Print Elo and matchmaking stats and exit")
+		cleanUnav   = flag.Bool("clean-unavailable", false, "Delete the tracks no longer available on Spotify")
+		recluster   = flag.Bool("recluster", false, "Recompute the mood of every track from its stored audio features")
+		recompute   = flag.Bool("recompute", false, "Recompute all ratings by replaying the battle log with the current Elo rules")
+		fsck        = flag.Bool("fsck", false, "Check the database for orphan ratings and battles and tracks without a rating, and offer to repair them")
+		renormalize = flag.Bool("renormalize", false, "Shift every rating by the same amount so the mean Elo is back to 1200 (ranking unchanged)")
+		mergeVers   = flag.Bool("merge-versions", false, "Merge the clean and explicit versions of the same song into the higher-rated one")
+		yes         = flag.Bool("yes", false, "Skip the confirmation prompt of destructive commands")
+		listLimit   = flag.Int("limit", 0, "Maximum number of tracks printed by -list or exported by -min-winrate (0 = all, 50 for exports)")
+		minWinRate  = flag.Float64("min-winrate", 0, "Export tracks winning at least this percentage of their battles (e.g. 70)")
+		minBattles  = flag.Int("min-battles", DefaultDecisiveMinBattles, "Minimum battles for a track to be exported by -min-winrate")
+		diffSince   = flag.String("diff-since", "", "Show ranking changes since a duration (7d, 36h) or date (2006-01-02)")
+		exportRank  = flag.String("export-ranking", "", "Write the ranking to a JSON file to share it")
+		compareRank = flag.String("compare-ranking", "", "Compare the ranking with a friend's -export-ranking or -export-json file")
+		exportCSV   = flag.String("export-csv", "", "Write every track with its rank, Elo and record to a CSV file")
+		exportJSON  = flag.String("export-json", "", "Write every track with its rating to a JSON file")
+		exportH2H   = flag.String("export-h2h", "", "Write the head-to-head record of every pair that has met to a CSV file")
+		exportYears = flag.String("export-years", "", "Write the top track of every release year and decade to a text file")
+		noExplicit  = flag.Bool("no-explicit", false, "Skip explicit tracks when importing")
+		minTracks   = flag.Int("min-tracks", 2, "Auto-import when fewer tracks than this are stored")
+		noAutoImp   = flag.Bool("no-auto-import", false, "Never import automatically at launch")
+		autoCalib   = flag.Bool("auto-calibrate", false, "Resolve battles automatically (higher popularity wins) on a copy of the database until calibrated, then print the ranking")
+		djOrder     = flag.Int("dj-order", 0, "Export the top N tracks as a playlist ordered by tempo and key")
+		exportTier  = flag.Bool("export-tiered", false, "Export the ranking as several playlists at once (top 25, 50 and 100 unless -tiers is set)")
+		tierSizes   = flag.String("tiers", "", "Comma-separated playlist sizes for -export-tiered (e.g. 10,40)")
+		forceExport = flag.Bool("force-export", false, "Export playlists even while the ranking is still calibrating")
 		showHelp    = flag.Bool("help", false, "Show help")
 		version     = flag.Bool("version", false, "Show version")
 	)
 	flag.Parse()
+	assumeYes = *yes
 
 	// Show version
 	if *version {
@@ -50,6 +135,69 @@ func main() {
 		return
 	}
 
+	// Debug logs: never on stdout, which would garble the TUI
+	if *logFile != "" {
+		file, err := logging.OpenFile(*logFile)
+		if err != nil {
+			log.Fatalf("Failed to open log file: %v", err)
+		}
+		defer file.Close()
+		logging.Printf("[main] %s v%s starting", AppName, AppVersion)
+	} else if os.Getenv("SONGBATTLE_DEBUG") != "" {
+		logging.SetOutput(os.Stderr)
+	}
+
+	// Load configuration (optional file)
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	if *noSummary {
+		cfg.UI.SessionSummary = false
+	}
+	if *noAnimation {
+		cfg.UI.Animation = false
+	}
+	if *skipIntro {
+		cfg.UI.SkipIntro = true
+	}
+	if *rounds > 0 {
+		cfg.UI.Rounds = *rounds
+	}
+	if *manualAuth {
+		cfg.Auth.ManualAuth = true
+	}
+	if *public {
+		cfg.Export.Public = true
+	}
+	if *forceExport {
+		cfg.Export.MinCalibration = 0
+	}
+	if *snipStart > 0 {
+		cfg.Playback.SnippetStart = *snipStart
+	}
+	if *snipLen > 0 {
+		cfg.Playback.SnippetLength = *snipLen
+	}
+	if *cooldown > 0 {
+		cfg.Matchmaking.AppearanceCooldown = *cooldown
+	}
+	if *matchMode != "" {
+		cfg.Matchmaking.Mode = *matchMode
+	}
+	if !config.ValidMatchMode(cfg.Matchmaking.Mode) {
+		log.Fatalf("Invalid match mode %q: use balanced or uncertainty", cfg.Matchmaking.Mode)
+	}
+	if *layout != "" {
+		cfg.UI.Layout.Orientation = *layout
+	}
+	if !config.ValidOrientation(cfg.UI.Layout.Orientation) {
+		log.Fatalf("Invalid layout %q: use horizontal, vertical or auto", cfg.UI.Layout.Orientation)
+	}
+	if _, err := ui.NewKeymap(cfg.Keymap); err != nil {
+		log.Fatalf("Invalid keymap in %s: %v", *configPath, err)
+	}
+
 	// Initialize database
 	db, err := store.NewDB(*dbPath)
 	if err != nil {
@@ -57,6 +205,124 @@ func main() {
 	}
 	defer db.Close()
 
+	// Compact ranking dump (offline, no Spotify needed)
+	if *listMode {
+		if err := runListMode(db, *listLimit); err != nil {
+			log.Fatalf("Failed to list tracks: %v", err)
+		}
+		return
+	}
+
+	// Library stats (offline, no Spotify needed)
+	if *statsMode {
+		if err := runStats(db); err != nil {
+			log.Fatalf("Failed to compute stats: %v", err)
+		}
+		return
+	}
+
+	// Library cleanup (offline, no Spotify needed)
+	if *cleanUnav {
+		if err := runCleanUnavailable(db); err != nil {
+			log.Fatalf("Failed to clean unavailable tracks: %v", err)
+		}
+		return
+	}
+
+	// Mood clustering (offline, no Spotify needed)
+	if *recluster {
+		if err := runRecluster(db); err != nil {
+			log.Fatalf("Failed to recompute moods: %v", err)
+		}
+		return
+	}
+
+	// Database integrity check (offline, no Spotify needed)
+	if *fsck {
+		if err := runFsck(db); err != nil {
+			log.Fatalf("Failed to check the database: %v", err)
+		}
+		return
+	}
+
+	// Clean/explicit version merge (offline, no Spotify needed)
+	if *mergeVers {
+		if err := runMergeVersions(db); err != nil {
+			log.Fatalf("Failed to merge versions: %v", err)
+		}
+		return
+	}
+
+	// Elo renormalization (offline, no Spotify needed)
+	if *renormalize {
+		if err := runRenormalize(db); err != nil {
+			log.Fatalf("Failed to renormalize ratings: %v", err)
+		}
+		return
+	}
+
+	// Rating recomputation (offline, no Spotify needed)
+	if *recompute {
+		if err := runRecompute(db); err != nil {
+			log.Fatalf("Failed to recompute ratings: %v", err)
+		}
+		return
+	}
+
+	// Ranking diff report (offline, no Spotify needed)
+	if *diffSince != "" {
+		if err := runDiffReport(db, *diffSince); err != nil {
+			log.Fatalf("Failed to compute ranking diff: %v", err)
+		}
+		return
+	}
+
+	// Shared ranking export and comparison (offline, no Spotify needed)
+	if *exportRank != "" {
+		if err := runExportRanking(db, *exportRank); err != nil {
+			log.Fatalf("Failed to export ranking: %v", err)
+		}
+		return
+	}
+	if *compareRank != "" {
+		if err := runCompareRanking(db, *compareRank); err != nil {
+			log.Fatalf("Failed to compare rankings: %v", err)
+		}
+		return
+	}
+	if *exportCSV != "" {
+		if err := runExportLeaderboard(db, *exportCSV, export.ExportLeaderboardCSV); err != nil {
+			log.Fatalf("Failed to export the leaderboard: %v", err)
+		}
+		return
+	}
+	if *exportJSON != "" {
+		if err := runExportLeaderboard(db, *exportJSON, export.ExportLeaderboardJSON); err != nil {
+			log.Fatalf("Failed to export the leaderboard: %v", err)
+		}
+		return
+	}
+	if *exportH2H != "" {
+		if err := runExportHeadToHead(db, *exportH2H); err != nil {
+			log.Fatalf("Failed to export head-to-head records: %v", err)
+		}
+		return
+	}
+	if *exportYears != "" {
+		if err := runExportYears(db, *exportYears); err != nil {
+			log.Fatalf("Failed to export champions by year: %v", err)
+		}
+		return
+	}
+
+	// Headless calibration (offline, no Spotify needed)
+	if *autoCalib {
+		if err := runAutoCalibrate(db, cfg, popularityOracle); err != nil {
+			log.Fatalf("Failed to auto-calibrate: %v", err)
+		}
+		return
+	}
+
 	// Check Client ID - priority order:
 	// 1. -client-id flag
 	// 2. Environment variable
@@ -65,7 +331,7 @@ func main() {
 	if *clientID == "" {
 		if envClientID := os.Getenv("SPOTIFY_CLIENT_ID"); envClientID != "" {
 			*clientID = envClientID
-		} else if savedClientID, err := db.GetMeta("spotify_client_id"); err == nil && savedClientID != "" {
+		} else if savedClientID, err := db.GetMeta(models.MetaKeyClientID); err == nil && savedClientID != "" {
 			*clientID = savedClientID
 			fmt.Println("✓ Using saved Client ID from configuration")
 		} else if DefaultClientID != "" {
@@ -84,14 +350,71 @@ func main() {
 	}
 
 	// Save Client ID for next time
-	if err := db.SetMeta("spotify_client_id", *clientID); err != nil {
+	if err := db.SetMeta(models.MetaKeyClientID, *clientID); err != nil {
 		// Non-blocking, just a warning
 		fmt.Printf("⚠️  Failed to save Client ID: %v\n", err)
 	}
 
+	// Forget the stored token and ask for consent again with the current scopes
+	if *reauth {
+		if err := runReauth(db, cfg, *clientID, *redirectURI, *useCustom, *useHTTPS); err != nil {
+			log.Fatalf("Failed to re-authenticate with Spotify: %v", err)
+		}
+	}
+
+	importOpts := importOptions{noExplicit: *noExplicit}
+
+	// Retry deferred imports
+	if *finishImp {
+		if err := runFinishImportMode(db, *clientID, *redirectURI, *useCustom, *useHTTPS, cfg.Auth.ManualAuth, importOpts); err != nil {
+			log.Fatalf("Failed to finish import: %v", err)
+		}
+		return
+	}
+
+	// DJ set export
+	if *djOrder != 0 {
+		if err := runDJExportMode(db, cfg, *clientID, *redirectURI, *useCustom, *useHTTPS, *djOrder); err != nil {
+			log.Fatalf("Failed to export DJ set: %v", err)
+		}
+		return
+	}
+
+	// Tiered export
+	if *exportTier {
+		sizes, err := parseTierSizes(*tierSizes)
+		if err != nil {
+			log.Fatalf("Invalid -tiers: %v", err)
+		}
+		if err := runTieredExportMode(db, cfg, *clientID, *redirectURI, *useCustom, *useHTTPS, sizes); err != nil {
+			log.Fatalf("Failed to export tiered playlists: %v", err)
+		}
+		return
+	}
+
+	// Decisive winners export
+	if *minWinRate > 0 {
+		limit := *listLimit
+		if limit <= 0 {
+			limit = DefaultDecisiveLimit
+		}
+		if err := runDecisiveExportMode(db, cfg, *clientID, *redirectURI, *useCustom, *useHTTPS, *minWinRate, *minBattles, limit); err != nil {
+			log.Fatalf("Failed to export decisive winners: %v", err)
+		}
+		return
+	}
+
+	// Artist catalog import
+	if *importArt != "" {
+		if err := runArtistImportMode(db, *clientID, *redirectURI, *useCustom, *useHTTPS, cfg.Auth.ManualAuth, *importArt, importOpts); err != nil {
+			log.Fatalf("Failed to import artist: %v", err)
+		}
+		fmt.Println("\n🎵 Starting battles...")
+	}
+
 	// Explicit import mode
 	if *importData {
-		if err := runImportMode(db, *clientID, *redirectURI, *useCustom, *useHTTPS); err != nil {
+		if err := runImportMode(db, *clientID, *redirectURI, *useCustom, *useHTTPS, cfg.Auth.ManualAuth, importOpts); err != nil {
 			log.Fatalf("Failed to import data: %v", err)
 		}
 		fmt.Println("\n🎵 Starting battles...")
@@ -103,28 +426,63 @@ func main() {
 		log.Fatalf("Failed to check data: %v", err)
 	}
 
-	// Not enough tracks, auto-import
-	if len(tracks) < 2 {
-		fmt.Printf("📥 No songs detected (%d tracks)\n", len(tracks))
-		fmt.Println("🔄 Auto-importing your Spotify top tracks...\n")
+	// Not enough tracks, auto-import (the UI shows an "import needed" view otherwise)
+	if !*noAutoImp && len(tracks) < *minTracks {
+		fmt.Printf("📥 Not enough songs (%d tracks, minimum %d)\n", len(tracks), *minTracks)
+		fmt.Println("🔄 Auto-importing your Spotify top tracks...")
+		fmt.Println()
 
-		if err := runImportMode(db, *clientID, *redirectURI, *useCustom, *useHTTPS); err != nil {
+		if err := runImportMode(db, *clientID, *redirectURI, *useCustom, *useHTTPS, cfg.Auth.ManualAuth, importOpts); err != nil {
 			log.Fatalf("Failed to auto-import: %v", err)
 		}
 
+		// The import may add nothing new (e.g. top tracks already known)
+		tracks, err = db.GetAllTracksWithRatings()
+		if err != nil {
+			log.Fatalf("Failed to check data: %v", err)
+		}
+		if len(tracks) < 2 {
+			printNotEnoughTracks(len(tracks))
+			return
+		}
+
 		fmt.Println("\n🎵 Starting battles...")
 	}
 
+	// Predetermined battles, played before regular matchmaking
+	var queue [][2]int64
+	if *queueFile != "" {
+		queue, err = loadBattleQueue(db, *queueFile)
+		if err != nil {
+			log.Fatalf("Failed to load battle queue: %v", err)
+		}
+		fmt.Printf("📋 %d queued battles loaded from %s\n", len(queue), filepath.Base(*queueFile))
+	}
+
 	// Launch TUI
-	if err := runTUI(db, *clientID, *redirectURI, *useCustom, *useHTTPS); err != nil {
+	if err := runTUI(db, *dbPath, cfg, *clientID, *redirectURI, *useCustom, *useHTTPS, queue); err != nil {
+		if errors.Is(err, errCrashed) {
+			db.Close()
+			os.Exit(1)
+		}
 		log.Fatalf("Failed to start UI: %v", err)
 	}
 }
 
+// printNotEnoughTracks explains how to grow a library too small to battle
+func printNotEnoughTracks(count int) {
+	fmt.Printf("\n⚠️  Still only %d track(s) after the import: at least 2 are needed to battle\n", count)
+	fmt.Println("Add more tracks with one of:")
+	fmt.Println("   song-battle -import-artist=ARTIST_URL     Import an artist's catalog")
+	fmt.Println("   song-battle -no-auto-import               Open the app and press '/' to search and add tracks")
+}
+
 // runTUI launches the Bubble Tea user interface
-func runTUI(db *store.DB, clientID, redirectURI string, useCustom, useHTTPS bool) error {
-	// Create model with URI options
-	model := ui.NewModelWithOptions(db, clientID, redirectURI, useCustom, useHTTPS)
+func runTUI(db *store.DB, dbPath string, cfg *config.Config, clientID, redirectURI string, useCustom, useHTTPS bool, queue [][2]int64) error {
+	// Create model with URI options and configuration
+	model := ui.NewModelWithConfig(db, clientID, redirectURI, useCustom, useHTTPS, cfg)
+	model.SetBattleQueue(queue)
+	model.SetDBPath(dbPath)
 
 	// Program options
 	opts := []tea.ProgramOption{
@@ -132,135 +490,1341 @@ func runTUI(db *store.DB, clientID, redirectURI string, useCustom, useHTTPS bool
 		tea.WithMouseCellMotion(),
 	}
 
-	// Create and launch program
-	program := tea.NewProgram(model, opts...)
+	// Create and launch program, guarded so a panic quits cleanly with a crash report
+	state := &crashState{}
+	program := tea.NewProgram(crashGuard{model: model, state: state}, opts...)
+	state.quit = program.Quit
 
 	fmt.Printf("🎵 Starting %s v%s...\n", AppName, AppVersion)
 
-	if _, err := program.Run(); err != nil {
+	final, err := program.Run()
+	if err != nil {
 		return fmt.Errorf("failed to start TUI: %w", err)
 	}
 
+	// A normal exit: the saved session must not be resumed on the next launch
+	if guard, ok := final.(crashGuard); ok && state.report == nil {
+		if session, ok := guard.model.(interface{ EndSession() error }); ok {
+			if err := session.EndSession(); err != nil {
+				logging.Printf("[main] saving session: %v", err)
+			}
+		}
+	}
+
+	// Another library may have been opened from the UI: close it too (the original one is closed by main)
+	if guard, ok := final.(crashGuard); ok {
+		if library, ok := guard.model.(interface{ DB() *store.DB }); ok && library.DB() != db {
+			library.DB().Close()
+		}
+	}
+
+	// The terminal is restored at this point: report the crash in plain text
+	if state.report != nil {
+		path, err := writeCrashReport(state.report)
+		if err != nil {
+			fmt.Printf("💥 %s crashed: %v (crash report could not be saved: %v)\n", AppName, state.report.value, err)
+		} else {
+			fmt.Printf("💥 %s crashed: %v\n", AppName, state.report.value)
+			fmt.Printf("   Crash report saved to %s\n", path)
+			fmt.Println("   It stays on this machine: attach it to a bug report to help fix the problem.")
+		}
+		return errCrashed
+	}
+
 	return nil
 }
 
-// runImportMode runs the data import mode
-func runImportMode(db *store.DB, clientID, redirectURI string, useCustom, useHTTPS bool) error {
-	ctx := context.Background()
+// errCrashed is returned by runTUI after a panic was caught and reported
+var errCrashed = errors.New("the UI crashed")
 
-	fmt.Printf("🎵 %s - Data Import v%s\n", AppName, AppVersion)
-	fmt.Println("════════════════════════════════════════")
+// assumeYes skips the CONFIRM prompt of destructive commands (-yes)
+var assumeYes bool
+
+// crashReport captures a panic caught in the UI
+type crashReport struct {
+	value   interface{}
+	stack   []byte
+	context string // UI state when the panic happened
+	at      time.Time
+}
+
+// crashState is shared by every copy of crashGuard
+type crashState struct {
+	report *crashReport
+	quit   func()
+}
+
+// crashMsg carries a panic caught in a command goroutine back to the event loop
+type crashMsg struct{ report *crashReport }
+
+// crashGuard wraps the UI model: a panic in Update, View or a command stops the
+// program through tea.Quit, so Bubble Tea restores the terminal before the report is printed
+type crashGuard struct {
+	model tea.Model
+	state *crashState
+}
+
+// Init starts the wrapped model
+func (g crashGuard) Init() tea.Cmd {
+	return guardCmd(g.model.Init())
+}
+
+// Update forwards messages to the wrapped model and catches its panics
+func (g crashGuard) Update(msg tea.Msg) (result tea.Model, cmd tea.Cmd) {
+	if g.state.report != nil {
+		return g, nil // Already crashed, waiting for the program to quit
+	}
+	if crash, ok := msg.(crashMsg); ok {
+		crash.report.context = g.context()
+		g.state.report = crash.report
+		return g, tea.Quit
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			g.state.report = newCrashReport(r, g.context())
+			result, cmd = g, tea.Quit
+		}
+	}()
+
+	model, cmd := g.model.Update(msg)
+	g.model = model
+	return g, guardCmd(cmd)
+}
+
+// View renders the wrapped model and catches its panics
+func (g crashGuard) View() (view string) {
+	if g.state.report != nil {
+		return ""
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			g.state.report = newCrashReport(r, g.context())
+			view = ""
+			go g.state.quit() // Quit sends a message: never from the event loop itself
+		}
+	}()
+
+	return g.model.View()
+}
+
+// context describes the wrapped model's state for the crash report
+func (g crashGuard) context() (context string) {
+	defer func() {
+		if recover() != nil {
+			context = "unavailable (the UI state could not be read)"
+		}
+	}()
+
+	if describer, ok := g.model.(interface{ CrashContext() string }); ok {
+		return describer.CrashContext()
+	}
+	return "unavailable"
+}
+
+// guardCmd runs a command with panic recovery, including the commands of a batch
+func guardCmd(cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+
+	return func() (msg tea.Msg) {
+		defer func() {
+			if r := recover(); r != nil {
+				msg = crashMsg{report: newCrashReport(r, "")}
+			}
+		}()
+
+		msg = cmd()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			guarded := make(tea.BatchMsg, len(batch))
+			for i, c := range batch {
+				guarded[i] = guardCmd(c)
+			}
+			msg = guarded
+		}
+		return msg
+	}
+}
+
+// newCrashReport records a recovered panic; call it from the deferred recover
+// so the stack trace still includes the panicking frames
+func newCrashReport(value interface{}, context string) *crashReport {
+	return &crashReport{
+		value:   value,
+		stack:   debug.Stack(),
+		context: context,
+		at:      time.Now(),
+	}
+}
+
+// writeCrashReport saves a crash report next to the database (~/.songbattle) and returns its path
+// Nothing is sent anywhere: the file is only meant to be attached to a bug report
+func writeCrashReport(report *crashReport) (string, error) {
+	dir := filepath.Dir(getDefaultDBPath())
+	path := filepath.Join(dir, fmt.Sprintf("crash-%s.log", report.at.Format("20060102-150405")))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s v%s crash report\n", AppName, AppVersion)
+	fmt.Fprintf(&b, "Time: %s\n", report.at.Format(time.RFC3339))
+	fmt.Fprintf(&b, "Go: %s %s/%s\n\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "Panic: %v\n\n", report.value)
+	fmt.Fprintf(&b, "UI state:\n%s\n\n", report.context)
+	fmt.Fprintf(&b, "Stack trace:\n%s", report.stack)
+
+	if err := os.WriteFile(path, []byte(b.String()), 0600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// connectSpotify authenticates and returns a ready-to-use Spotify client
+// extraScopes are requested on top of auth.RequiredScopes
+func connectSpotify(db *store.DB, clientID, redirectURI string, useCustom, useHTTPS, manualAuth bool, extraScopes ...string) (*spotify.Client, error) {
+	ctx := context.Background()
 
 	// Initialize authentication with URI options
 	auth := auth.NewSpotifyAuthWithOptions(clientID, db, redirectURI, useCustom, useHTTPS)
+	auth.ManualAuth = manualAuth
+	auth.AddScopes(extraScopes...)
 
 	fmt.Println("🔐 Authenticating with Spotify...")
 	token, err := auth.GetValidToken(ctx)
 	if err != nil {
-		return fmt.Errorf("authentication failed: %w", err)
+		return nil, fmt.Errorf("authentication failed: %w", err)
 	}
 
-	// Create Spotify client
-	spotifyClient := spotify.NewClient(ctx, token, clientID)
+	return spotify.NewClient(ctx, token, clientID), nil
+}
+
+// importOptions holds the import filters chosen on the command line
+type importOptions struct {
+	noExplicit bool // Skip tracks flagged explicit by Spotify
+}
+
+// runImportMode runs the data import mode
+func runImportMode(db *store.DB, clientID, redirectURI string, useCustom, useHTTPS, manualAuth bool, opts importOptions) error {
+	fmt.Printf("🎵 %s - Data Import v%s\n", AppName, AppVersion)
+	fmt.Println("════════════════════════════════════════")
+
+	// Top tracks need user-top-read: check it up front, as import errors are only reported per range
+	var spotifyClient *spotify.Client
+	err := withScopeRetry(db, clientID, redirectURI, useCustom, useHTTPS, manualAuth, nil, "share your top tracks", func(client *spotify.Client) error {
+		spotifyClient = client
+		_, err := client.GetUserTopTracks(1, spotifyapi.ShortTermRange)
+		if spotify.IsMissingScope(err) {
+			return err
+		}
+		return nil // Other failures are reported range by range below
+	})
+	if err != nil {
+		return err
+	}
 
 	// Import user's top tracks
 	fmt.Println("📥 Importing top tracks...")
-	if err := importUserTopTracks(db, spotifyClient); err != nil {
+	if err := importUserTopTracks(db, spotifyClient, opts); err != nil {
 		return fmt.Errorf("failed to import top tracks: %w", err)
 	}
 
 	// Import recommendations (non-blocking)
 	fmt.Println("🎲 Importing recommendations...")
-	if err := importRecommendations(db, spotifyClient); err != nil {
+	if err := importRecommendations(db, spotifyClient, opts); err != nil {
 		fmt.Printf("   ⚠️  Failed to import recommendations: %v\n", err)
 		fmt.Println("   → No worries, you have enough tracks to play!")
 	}
 
 	fmt.Println("✅ Import completed successfully!")
+	printDeferredSummary(db)
 	fmt.Printf("You can now run: songbattle -client-id=%s\n", clientID)
 
 	return nil
 }
 
-// importUserTopTracks imports user's top tracks
-func importUserTopTracks(db *store.DB, client *spotify.Client) error {
-	// Import short term top tracks
-	shortTermTracks, err := client.GetUserTopTracks(25, spotifyapi.ShortTermRange)
+// runFinishImportMode retries the tracks deferred by previous imports
+func runFinishImportMode(db *store.DB, clientID, redirectURI string, useCustom, useHTTPS, manualAuth bool, opts importOptions) error {
+	fmt.Printf("🎵 %s - Finish Import v%s\n", AppName, AppVersion)
+	fmt.Println("════════════════════════════════════════")
+
+	queue, err := db.GetImportQueue()
 	if err != nil {
-		fmt.Printf("⚠️  Failed to get short term tracks: %v\n", err)
-	} else {
-		if err := saveTracks(db, shortTermTracks, client); err != nil {
-			return err
-		}
-		fmt.Printf("   ✓ %d short term tracks imported\n", len(shortTermTracks))
+		return fmt.Errorf("failed to read import queue: %w", err)
 	}
 
-	// Import medium term top tracks
-	mediumTermTracks, err := client.GetUserTopTracks(25, spotifyapi.MediumTermRange)
-	if err != nil {
-		fmt.Printf("⚠️  Failed to get medium term tracks: %v\n", err)
-	} else {
-		if err := saveTracks(db, mediumTermTracks, client); err != nil {
-			return err
-		}
-		fmt.Printf("   ✓ %d medium term tracks imported\n", len(mediumTermTracks))
+	if len(queue) == 0 {
+		fmt.Println("✅ No deferred tracks, nothing to do")
+		return nil
 	}
 
-	// Import long term top tracks
-	longTermTracks, err := client.GetUserTopTracks(25, spotifyapi.LongTermRange)
+	spotifyClient, err := connectSpotify(db, clientID, redirectURI, useCustom, useHTTPS, manualAuth)
 	if err != nil {
-		fmt.Printf("⚠️  Failed to get long term tracks: %v\n", err)
-	} else {
-		if err := saveTracks(db, longTermTracks, client); err != nil {
-			return err
-		}
-		fmt.Printf("   ✓ %d long term tracks imported\n", len(longTermTracks))
+		return err
 	}
 
-	return nil
-}
-
-// importRecommendations imports recommendations based on existing tracks
-func importRecommendations(db *store.DB, client *spotify.Client) error {
-	// Get some existing tracks as seeds
-	existingTracks, err := db.GetTopTracks(5)
-	if err != nil || len(existingTracks) == 0 {
-		fmt.Println("   ⚠️  No existing tracks for recommendations")
-		return nil
+	sources, err := db.GetImportSources()
+	if err != nil {
+		return fmt.Errorf("failed to read import sources: %w", err)
 	}
 
-	// Use Spotify IDs as seeds
-	seeds := make([]string, 0, len(existingTracks))
-	for _, track := range existingTracks {
-		seeds = append(seeds, track.Track.SpotifyID)
+	// Clear the queue: tracks still rate limited will be queued again by saveTracks
+	if err := db.SetImportQueue(nil); err != nil {
+		return fmt.Errorf("failed to reset import queue: %w", err)
+	}
+	if err := db.SetImportSources(nil); err != nil {
+		return fmt.Errorf("failed to reset import sources: %w", err)
 	}
 
-	// Get recommendations
-	recommendations, err := client.GetRecommendations(seeds[:min(2, len(seeds))], []string{}, []string{}, 20)
+	fmt.Printf("🔁 Retrying %d deferred tracks...\n", len(queue))
+	tracks, err := spotifyClient.GetTracks(queue)
 	if err != nil {
-		return err
+		// Put back everything we could not fetch
+		if qErr := db.AddToImportQueue(queue...); qErr != nil {
+			fmt.Printf("   ⚠️  Failed to restore import queue: %v\n", qErr)
+		}
+		if sErr := db.SetImportSources(sources); sErr != nil {
+			fmt.Printf("   ⚠️  Failed to restore import sources: %v\n", sErr)
+		}
+		if spotify.IsRateLimited(err) {
+			fmt.Println("   ⏳ Still rate limited by Spotify, try again later")
+			return nil
+		}
+		return fmt.Errorf("failed to fetch deferred tracks: %w", err)
+	}
+
+	// Keep the source of the import that deferred each track
+	for _, track := range tracks {
+		track.Source = sources[track.SpotifyID]
 	}
 
-	if err := saveTracks(db, recommendations, client); err != nil {
+	if err := saveTracks(db, tracks, spotifyClient, opts); err != nil {
 		return err
 	}
 
-	fmt.Printf("   ✓ %d recommendations imported\n", len(recommendations))
+	fmt.Println("✅ Deferred import completed!")
+	printDeferredSummary(db)
+
 	return nil
 }
 
-// saveTracks saves a list of tracks to database
-func saveTracks(db *store.DB, tracks []*models.Track, client *spotify.Client) error {
-	for _, track := range tracks {
-		// Check if track already exists
-		if existing, _ := db.GetTrackBySpotifyID(track.SpotifyID); existing != nil {
-			continue // Skip if already exists
-		}
+// runArtistImportMode imports the catalog of a single artist
+func runArtistImportMode(db *store.DB, clientID, redirectURI string, useCustom, useHTTPS, manualAuth bool, artist string, opts importOptions) error {
+	fmt.Printf("🎵 %s - Artist Import v%s\n", AppName, AppVersion)
+	fmt.Println("════════════════════════════════════════")
 
-		// Enrich with audio features
-		if err := client.EnrichTrackWithAudioFeatures(track); err != nil {
-			fmt.Printf("   ⚠️  Failed to enrich %s: %v\n", track.Name, err)
-		}
+	artistID, err := spotify.ParseArtistID(artist)
+	if err != nil {
+		return err
+	}
+
+	spotifyClient, err := connectSpotify(db, clientID, redirectURI, useCustom, useHTTPS, manualAuth)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("📥 Fetching artist catalog...")
+	tracks, truncated, err := spotifyClient.GetArtistCatalog(artistID, MaxArtistImport)
+	if err != nil {
+		if len(tracks) == 0 {
+			return fmt.Errorf("failed to fetch artist catalog: %w", err)
+		}
+		// Keep what we already fetched
+		fmt.Printf("   ⚠️  Catalog partially fetched: %v\n", err)
+	}
+	if truncated {
+		fmt.Printf("   ⚠️  Catalog capped at %d tracks (top tracks first)\n", MaxArtistImport)
+	}
+
+	if err := saveTracks(db, withSource(tracks, models.ArtistSource(artistID)), spotifyClient, opts); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ %d artist tracks imported\n", len(tracks))
+	printDeferredSummary(db)
+
+	return nil
+}
+
+// runDJExportMode exports the top N tracks as a playlist ordered for smooth transitions
+func runDJExportMode(db *store.DB, cfg *config.Config, clientID, redirectURI string, useCustom, useHTTPS bool, limit int) error {
+	fmt.Printf("🎵 %s - DJ Set Export v%s\n", AppName, AppVersion)
+	fmt.Println("════════════════════════════════════════")
+
+	if err := export.ValidateExportParams(limit); err != nil {
+		return err
+	}
+	if err := checkCalibration(db, cfg); err != nil {
+		return err
+	}
+
+	fmt.Printf("🎧 Ordering top %d tracks by tempo and key...\n", limit)
+	info, err := runPlaylistExport(db, cfg, clientID, redirectURI, useCustom, useHTTPS, func(exporter *export.PlaylistExporter) (*export.PlaylistInfo, error) {
+		return exporter.ExportDJSet(limit)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("✅ Playlist created")
+	fmt.Println(info.GetSummary())
+
+	return nil
+}
+
+// runDecisiveExportMode exports the tracks that win most of their battles, ordered by Elo
+func runDecisiveExportMode(db *store.DB, cfg *config.Config, clientID, redirectURI string, useCustom, useHTTPS bool, minWinRate float64, minBattles, limit int) error {
+	fmt.Printf("🎵 %s - Decisive Winners Export v%s\n", AppName, AppVersion)
+	fmt.Println("════════════════════════════════════════")
+
+	if minWinRate > 100 {
+		return fmt.Errorf("-min-winrate is a percentage (0-100), got %.0f", minWinRate)
+	}
+	if err := export.ValidateExportParams(limit); err != nil {
+		return err
+	}
+	if err := checkCalibration(db, cfg); err != nil {
+		return err
+	}
+
+	fmt.Printf("🏅 Exporting up to %d tracks winning %.0f%%+ of at least %d battles...\n", limit, minWinRate, minBattles)
+	info, err := runPlaylistExport(db, cfg, clientID, redirectURI, useCustom, useHTTPS, func(exporter *export.PlaylistExporter) (*export.PlaylistInfo, error) {
+		return exporter.ExportDecisiveWinners(minWinRate, minBattles, limit)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("✅ Playlist created")
+	fmt.Println(info.GetSummary())
+
+	return nil
+}
+
+// checkCalibration refuses to export a ranking while too few tracks are calibrated
+func checkCalibration(db *store.DB, cfg *config.Config) error {
+	progress, err := matchmaker.NewMatchmakerWithConfig(db, cfg.Matchmaking).CalibrationProgress()
+	if err != nil {
+		return fmt.Errorf("failed to compute calibration progress: %w", err)
+	}
+	if err := export.ValidateCalibration(progress, cfg.Export.MinCalibration); err != nil {
+		return fmt.Errorf("%w; keep battling or use -force-export", err)
+	}
+	return nil
+}
+
+// runTieredExportMode exports the ranking as one playlist per size, from the same ranking
+func runTieredExportMode(db *store.DB, cfg *config.Config, clientID, redirectURI string, useCustom, useHTTPS bool, sizes []int) error {
+	fmt.Printf("🎵 %s - Tiered Export v%s\n", AppName, AppVersion)
+	fmt.Println("════════════════════════════════════════")
+
+	if err := checkCalibration(db, cfg); err != nil {
+		return err
+	}
+
+	fmt.Println("📚 Creating one playlist per size...")
+	var infos []*export.PlaylistInfo
+	err := withScopeRetry(db, clientID, redirectURI, useCustom, useHTTPS, cfg.Auth.ManualAuth, exportScopes(cfg.Export), "create the playlists", func(client *spotify.Client) error {
+		exporter := export.NewPlaylistExporterWithConfig(db, client, context.Background(), cfg.Export)
+		var err error
+		infos, err = exporter.ExportTiered(sizes)
+		return err
+	})
+
+	// Playlists created before a failure are still reported
+	for _, info := range infos {
+		fmt.Println()
+		fmt.Println(info.GetSummary())
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n✅ %d playlists created\n", len(infos))
+	return nil
+}
+
+// parseTierSizes parses the comma-separated -tiers list (empty = the recommended sizes)
+func parseTierSizes(value string) ([]int, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	var sizes []int
+	for _, field := range strings.Split(value, ",") {
+		size, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", field)
+		}
+		if err := export.ValidateExportParams(size); err != nil {
+			return nil, err
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes, nil
+}
+
+// exportScopes returns the scopes needed by the export settings on top of auth.RequiredScopes
+func exportScopes(cfg config.ExportConfig) []string {
+	if cfg.Public {
+		return []string{auth.PublicPlaylistScope}
+	}
+	return nil
+}
+
+// runPlaylistExport connects to Spotify and runs an export. If the stored token was
+// authorized without the playlist scopes, it offers to re-authenticate and retries once
+func runPlaylistExport(db *store.DB, cfg *config.Config, clientID, redirectURI string, useCustom, useHTTPS bool, run func(*export.PlaylistExporter) (*export.PlaylistInfo, error)) (*export.PlaylistInfo, error) {
+	var info *export.PlaylistInfo
+	err := withScopeRetry(db, clientID, redirectURI, useCustom, useHTTPS, cfg.Auth.ManualAuth, exportScopes(cfg.Export), "create the playlist", func(client *spotify.Client) error {
+		exporter := export.NewPlaylistExporterWithConfig(db, client, context.Background(), cfg.Export)
+		var err error
+		info, err = run(exporter)
+		return err
+	})
+	return info, err
+}
+
+// withScopeRetry connects to Spotify and runs an operation. If Spotify refuses it because
+// the stored token was authorized before a scope it needs, it offers to re-authenticate
+// with the current scopes and retries once. action completes "Spotify refused to ..."
+func withScopeRetry(db *store.DB, clientID, redirectURI string, useCustom, useHTTPS, manualAuth bool, extraScopes []string, action string, run func(*spotify.Client) error) error {
+	for attempt := 0; ; attempt++ {
+		spotifyClient, err := connectSpotify(db, clientID, redirectURI, useCustom, useHTTPS, manualAuth, extraScopes...)
+		if err != nil {
+			return err
+		}
+
+		err = run(spotifyClient)
+		if err == nil || !spotify.IsMissingScope(err) || attempt > 0 {
+			return err
+		}
+
+		fmt.Printf("⚠️  Spotify refused to %s: your authorization predates the permissions it needs.\n", action)
+		fmt.Println("   Re-authenticating asks for consent again with the current scopes.")
+		if !confirm("🔐 Re-authenticate now? [y/N] ") {
+			return fmt.Errorf("%w (run again with -reauth to grant it)", err)
+		}
+
+		if err := auth.NewSpotifyAuthWithOptions(clientID, db, redirectURI, useCustom, useHTTPS).Logout(); err != nil {
+			return err
+		}
+	}
+}
+
+// runReauth forgets the stored token and asks for consent right away with the current
+// scopes, including the ones enabled by the configuration (public playlists)
+func runReauth(db *store.DB, cfg *config.Config, clientID, redirectURI string, useCustom, useHTTPS bool) error {
+	spotifyAuth := auth.NewSpotifyAuthWithOptions(clientID, db, redirectURI, useCustom, useHTTPS)
+	spotifyAuth.ManualAuth = cfg.Auth.ManualAuth
+	spotifyAuth.AddScopes(exportScopes(cfg.Export)...)
+
+	if err := spotifyAuth.Logout(); err != nil {
+		return fmt.Errorf("failed to forget Spotify token: %w", err)
+	}
+	fmt.Println("🔐 Spotify token forgotten, asking for consent again...")
+
+	if _, err := spotifyAuth.Authenticate(context.Background()); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Spotify authorized with: %s\n", strings.Join(spotifyAuth.Scopes(), ", "))
+	return nil
+}
+
+// confirm asks a yes/no question on stdin (default: no)
+func confirm(question string) bool {
+	fmt.Print(question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// confirmDestructive announces an irreversible change and the number of affected items,
+// then requires -yes or typing CONFIRM on stdin
+func confirmDestructive(action string, count int) bool {
+	fmt.Printf("⚠️  About to %s (%d affected). This cannot be undone.\n", action, count)
+	if assumeYes {
+		fmt.Println("   Confirmed with -yes")
+		return true
+	}
+
+	fmt.Print("   Type CONFIRM to proceed: ")
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	return strings.TrimSpace(answer) == "CONFIRM"
+}
+
+// duelOracle decides a battle without user input ("left", "right" or "draw")
+type duelOracle func(left, right *models.TrackWithRating) string
+
+// popularityOracle makes the more popular track win (draw when equal or unknown)
+func popularityOracle(left, right *models.TrackWithRating) string {
+	l, r := left.Track.Popularity, right.Track.Popularity
+	switch {
+	case l < 0 || r < 0 || l == r:
+		return models.WinnerDraw
+	case l > r:
+		return models.WinnerLeft
+	default:
+		return models.WinnerRight
+	}
+}
+
+// runAutoCalibrate plays battles decided by the oracle until every track is calibrated
+// The battles are played on a temporary copy of the database: the library is left untouched
+func runAutoCalibrate(library *store.DB, cfg *config.Config, decide duelOracle) error {
+	fmt.Printf("🎵 %s - Auto Calibration v%s\n", AppName, AppVersion)
+	fmt.Println("════════════════════════════════════════")
+
+	dir, err := os.MkdirTemp("", "songbattle-calibrate-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	copyPath := filepath.Join(dir, "songbattle.db")
+	if err := library.CopyTo(copyPath); err != nil {
+		return fmt.Errorf("failed to copy the database: %w", err)
+	}
+	db, err := store.NewDB(copyPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	fmt.Println("   Playing on a temporary copy: your ratings are not changed")
+
+	mm := matchmaker.NewMatchmakerWithConfig(db, cfg.Matchmaking)
+	eloSystem := elo.NewEloSystemWithConfig(db, cfg.Elo)
+
+	duels := 0
+	for ; duels < MaxAutoCalibrateDuels; duels++ {
+		progress, err := mm.CalibrationProgress()
+		if err != nil {
+			return err
+		}
+		if progress >= 100 {
+			break
+		}
+
+		left, right, err := mm.GetNextMatch()
+		if err != nil {
+			return err
+		}
+
+		if _, err := eloSystem.ProcessDuel(left.Track.ID, right.Track.ID, decide(left, right)); err != nil {
+			return err
+		}
+
+		if (duels+1)%100 == 0 {
+			fmt.Printf("   %d battles, %.0f%% calibrated\n", duels+1, progress)
+		}
+	}
+
+	if duels == MaxAutoCalibrateDuels {
+		fmt.Printf("⚠️  Stopped after %d battles before full calibration\n", duels)
+	} else {
+		fmt.Printf("✅ Calibrated after %d battles\n\n", duels)
+	}
+
+	ranking, err := eloSystem.GetEloRanking(-1)
+	if err != nil {
+		return err
+	}
+	for i, track := range ranking {
+		fmt.Printf("%4d. %4d  %s - %s\n", i+1, track.Rating.Elo, track.Track.Name, track.Track.Artist)
+	}
+
+	return nil
+}
+
+// runDiffReport prints how each track's Elo changed since the given point in time
+func runDiffReport(db *store.DB, sinceArg string) error {
+	since, err := parseSince(sinceArg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	changes, err := elo.NewEloSystem(db).RankingDiff(since)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("📈 Ranking changes since %s\n", since.Format("2006-01-02 15:04"))
+	fmt.Println("════════════════════════════════════════")
+
+	if len(changes) == 0 {
+		fmt.Println("No Elo changes in this period")
+		return nil
+	}
+
+	tracks, err := db.GetAllTracksWithRatings()
+	if err != nil {
+		return err
+	}
+	names := make(map[int64]string, len(tracks))
+	for _, track := range tracks {
+		names[track.Track.ID] = track.Track.Name + " - " + track.Track.Artist
+	}
+
+	for _, change := range changes {
+		fmt.Printf("%+5d  %4d → %-4d  %s\n", change.Change, change.OldElo, change.NewElo, names[change.TrackID])
+	}
+
+	return nil
+}
+
+// runListMode prints the ranking one line per track, ready to paste in a chat
+func runListMode(db *store.DB, limit int) error {
+	tracks, err := db.GetAllTracksWithRatings()
+	if err != nil {
+		return err
+	}
+
+	if limit > 0 && limit < len(tracks) {
+		tracks = tracks[:limit]
+	}
+
+	for i, track := range tracks {
+		fmt.Printf("%d. %s - %s (Elo %d, %d-%d)\n",
+			i+1,
+			ui.Truncate(track.Track.Artist, ListFieldWidth),
+			ui.Truncate(track.Track.Name, ListFieldWidth),
+			track.Rating.Elo,
+			track.Rating.Wins,
+			track.Rating.Losses)
+	}
+
+	return nil
+}
+
+// runStats prints the Elo and matchmaking stats of the library, even with fewer than 2 tracks
+func runStats(db *store.DB) error {
+	eloStats, err := elo.NewEloSystem(db).GetEloStats()
+	if err != nil {
+		return err
+	}
+	matchStats, err := matchmaker.NewMatchmaker(db).GetMatchmakingStats()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("📊 Library stats")
+	fmt.Printf("   Tracks:            %d\n", eloStats["total_tracks"])
+	if eloStats["total_tracks"] == 0 {
+		return nil
+	}
+	fmt.Printf("   Average Elo:       %d (min %d, max %d)\n", eloStats["average_elo"], eloStats["min_elo"], eloStats["max_elo"])
+	fmt.Printf("   New tracks:        %d (fewer than %d battles)\n", matchStats["new_tracks"], matchmaker.MinBattlesForBalance)
+	fmt.Printf("   Experienced:       %d\n", matchStats["experienced_tracks"])
+	fmt.Printf("   Exploration rate:  %.0f%% of battles include a new track\n", matchStats["exploration_rate"].(float64)*100)
+	fmt.Printf("   Balanced range:    ±%d Elo\n", matchStats["elo_range"])
+	return nil
+}
+
+// runCleanUnavailable deletes, after confirmation, the tracks flagged as removed from Spotify
+func runCleanUnavailable(db *store.DB) error {
+	tracks, err := db.GetUnavailableTracks()
+	if err != nil {
+		return err
+	}
+
+	if len(tracks) == 0 {
+		fmt.Println("✅ No unavailable tracks, nothing to clean")
+		return nil
+	}
+
+	fmt.Printf("⊘ %d track(s) no longer available on Spotify:\n", len(tracks))
+	for _, track := range tracks {
+		since := ""
+		if track.Track.UnavailableAt != nil {
+			since = ", since " + track.Track.UnavailableAt.Format("2006-01-02")
+		}
+		fmt.Printf("   %s - %s (Elo %d, %d battles%s)\n",
+			ui.Truncate(track.Track.Artist, ListFieldWidth),
+			ui.Truncate(track.Track.Name, ListFieldWidth),
+			track.Rating.Elo,
+			track.Rating.GetTotalBattles(),
+			since)
+	}
+
+	if !confirmDestructive("delete these tracks along with their battles", len(tracks)) {
+		fmt.Println("Nothing deleted")
+		return nil
+	}
+
+	deleted, err := db.DeleteUnavailableTracks()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🗑️  %d track(s) deleted\n", deleted)
+	return nil
+}
+
+// runRecluster recomputes the mood of every track from its stored audio features
+// and prints how many tracks fall into each mood
+func runRecluster(db *store.DB) error {
+	tracks, err := db.GetAllTracksWithRatings()
+	if err != nil {
+		return err
+	}
+
+	changed := make(map[int64]string)
+	counts := make(map[string]int)
+	for _, track := range tracks {
+		mood := analysis.Mood(track.Track.AudioFeaturesJSON)
+		counts[mood]++
+		if mood != track.Track.Mood {
+			changed[track.Track.ID] = mood
+		}
+	}
+
+	if err := db.UpdateMoods(changed); err != nil {
+		return err
+	}
+
+	fmt.Printf("🎭 Moods recomputed for %d tracks (%d changed)\n", len(tracks), len(changed))
+	for _, mood := range analysis.Moods {
+		fmt.Printf("   %-10s %d\n", mood, counts[mood])
+	}
+	if counts[""] > 0 {
+		fmt.Printf("   %-10s %d (no audio features stored)\n", "unknown", counts[""])
+	}
+
+	return nil
+}
+
+// runFsck reports the rows left behind by a track deleted outside the cascading deletes,
+// and the tracks missing a rating row, then repairs them after confirmation
+func runFsck(db *store.DB) error {
+	ratings, duels, err := db.FindOrphans()
+	if err != nil {
+		return err
+	}
+	unrated, err := db.FindUnratedTracks()
+	if err != nil {
+		return err
+	}
+
+	if len(ratings)+len(duels)+len(unrated) == 0 {
+		fmt.Println("✅ No orphan ratings or battles, every track has a rating")
+		return nil
+	}
+
+	fmt.Println("🩺 Database inconsistencies found:")
+	if len(ratings) > 0 {
+		fmt.Printf("   %d rating(s) of deleted tracks (track IDs %s)\n", len(ratings), formatIDs(ratings))
+	}
+	if len(duels) > 0 {
+		fmt.Printf("   %d battle(s) involving deleted tracks (battle IDs %s)\n", len(duels), formatIDs(duels))
+	}
+	if len(unrated) > 0 {
+		fmt.Printf("   %d track(s) without a rating, missing from battles and the ranking (track IDs %s)\n", len(unrated), formatIDs(unrated))
+	}
+
+	if !confirmDestructive("delete the orphan rows and give the unrated tracks a starting rating", len(ratings)+len(duels)+len(unrated)) {
+		fmt.Println("Nothing changed")
+		return nil
+	}
+
+	deleted, created, err := db.RepairOrphans()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🧹 %d orphan row(s) deleted, %d rating(s) created\n", deleted, created)
+	if len(duels) > 0 || created > 0 {
+		fmt.Println("   Run -recompute to rebuild the ratings from the remaining battles")
+	}
+	return nil
+}
+
+// formatIDs lists the first IDs of a report, with the count of the rest
+func formatIDs(ids []int64) string {
+	parts := make([]string, 0, FsckReportSize)
+	for _, id := range ids[:min(len(ids), FsckReportSize)] {
+		parts = append(parts, strconv.FormatInt(id, 10))
+	}
+	list := strings.Join(parts, ", ")
+	if len(ids) > FsckReportSize {
+		list += fmt.Sprintf(" and %d more", len(ids)-FsckReportSize)
+	}
+	return list
+}
+
+// runMergeVersions lists the clean/explicit pairs of the library and, after confirmation,
+// merges each pair into its higher-rated track with a blended rating
+func runMergeVersions(db *store.DB) error {
+	tracks, err := db.GetAllTracksWithRatings()
+	if err != nil {
+		return err
+	}
+
+	pairs := models.FindVersionPairs(tracks)
+	if len(pairs) == 0 {
+		fmt.Println("✅ No clean/explicit versions of the same song, nothing to merge")
+		return nil
+	}
+
+	fmt.Printf("🔗 %d song(s) imported in both clean and explicit versions:\n", len(pairs))
+	for _, pair := range pairs {
+		fmt.Printf("   %s - %s: keeping %s (Elo %d, %d battles), merging %s (Elo %d, %d battles)\n",
+			ui.Truncate(pair.Keep.Track.Artist, ListFieldWidth),
+			ui.Truncate(pair.Keep.Track.Name, ListFieldWidth),
+			versionLabel(pair.Keep.Track), pair.Keep.Rating.Elo, pair.Keep.Rating.GetTotalBattles(),
+			versionLabel(pair.Drop.Track), pair.Drop.Rating.Elo, pair.Drop.Rating.GetTotalBattles())
+	}
+
+	if !confirmDestructive("merge each pair into one track and delete the other version", len(pairs)) {
+		fmt.Println("Nothing merged")
+		return nil
+	}
+
+	eloSystem := elo.NewEloSystem(db)
+	for _, pair := range pairs {
+		merged, err := eloSystem.MergeTracks(pair.Keep.Track.ID, pair.Drop.Track.ID)
+		if err != nil {
+			return fmt.Errorf("merging %q: %w", pair.Keep.Track.Name, err)
+		}
+		fmt.Printf("   %s: Elo %d, %d battles\n", ui.Truncate(pair.Keep.Track.Name, ListFieldWidth), merged.Elo, merged.GetTotalBattles())
+	}
+
+	fmt.Printf("🔗 %d song(s) merged\n", len(pairs))
+	return nil
+}
+
+// versionLabel names the version of a track in the -merge-versions report
+func versionLabel(track models.Track) string {
+	if track.Explicit {
+		return "explicit"
+	}
+	return "clean"
+}
+
+// runRenormalize shifts every rating after confirmation so the mean Elo is back to the
+// starting Elo, keeping the ranking and the gaps between tracks
+func runRenormalize(db *store.DB) error {
+	tracks, err := db.GetAllTracksWithRatings()
+	if err != nil {
+		return err
+	}
+	if len(tracks) == 0 {
+		fmt.Println("✅ No tracks, nothing to renormalize")
+		return nil
+	}
+
+	total := 0
+	for _, track := range tracks {
+		total += track.Rating.Elo
+	}
+	mean := float64(total) / float64(len(tracks))
+	delta := int(math.Round(elo.InitialElo - mean))
+	if delta == 0 {
+		fmt.Printf("✅ Mean Elo is already %d, nothing to renormalize\n", elo.InitialElo)
+		return nil
+	}
+
+	fmt.Printf("📏 Mean Elo is %.0f: every rating will move by %+d (ranking unchanged)\n", mean, delta)
+	if !confirmDestructive(fmt.Sprintf("shift every rating by %+d", delta), len(tracks)) {
+		fmt.Println("Nothing changed")
+		return nil
+	}
+
+	shifted, err := elo.NewEloSystem(db).Renormalize()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ %d rating(s) shifted by %+d, mean Elo back to %d\n", len(tracks), shifted, elo.InitialElo)
+	return nil
+}
+
+// runRecompute resets every rating after confirmation and replays the battle log in
+// chronological order, then prints the tracks whose Elo moved the most
+func runRecompute(db *store.DB) error {
+	duelCount, err := db.GetDuelCount()
+	if err != nil {
+		return err
+	}
+
+	tracks, err := db.GetAllTracksWithRatings()
+	if err != nil {
+		return err
+	}
+
+	if duelCount == 0 {
+		fmt.Println("✅ No battles recorded, nothing to recompute")
+		return nil
+	}
+
+	fmt.Printf("🔁 %d battle(s) will be replayed with the current Elo rules\n", duelCount)
+	if !confirmDestructive("reset every rating and replay the battle log", len(tracks)) {
+		fmt.Println("Nothing changed")
+		return nil
+	}
+
+	replayed, changes, err := elo.NewEloSystem(db).Recompute()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ %d battle(s) replayed, %d track(s) changed\n", replayed, len(changes))
+	if len(changes) == 0 {
+		return nil
+	}
+
+	names := make(map[int64]string, len(tracks))
+	for _, track := range tracks {
+		names[track.Track.ID] = track.Track.Name + " - " + track.Track.Artist
+	}
+
+	shown := changes
+	if len(shown) > RecomputeReportSize {
+		shown = shown[:RecomputeReportSize]
+	}
+	for _, change := range shown {
+		fmt.Printf("%+5d  %4d → %-4d  %s\n", change.Change, change.OldElo, change.NewElo, names[change.TrackID])
+	}
+	if len(changes) > len(shown) {
+		fmt.Printf("   ... and %d more\n", len(changes)-len(shown))
+	}
+
+	return nil
+}
+
+// runExportRanking writes the full ranking to a JSON file
+func runExportRanking(db *store.DB, path string) error {
+	tracks, err := db.GetTopTracks(-1)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := export.ExportRankingJSON(file, tracks); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Ranking of %d tracks written to %s\n", len(tracks), path)
+	return file.Close()
+}
+
+// runExportLeaderboard writes every track with its rating to a file, in the format of write
+func runExportLeaderboard(db *store.DB, path string, write func(io.Writer, []models.TrackWithRating) error) error {
+	tracks, err := db.GetAllTracksWithRatings()
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := write(file, tracks); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Leaderboard of %d tracks written to %s\n", len(tracks), path)
+	return file.Close()
+}
+
+// runExportHeadToHead writes the head-to-head record of every pair that has met to a CSV file
+func runExportHeadToHead(db *store.DB, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	pairs, err := export.ExportHeadToHeadCSV(db, file)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Head-to-head records of %d pairs written to %s\n", pairs, path)
+	return file.Close()
+}
+
+// runExportYears writes the champion of every release year and decade to a text file
+func runExportYears(db *store.DB, path string) error {
+	perYear, err := db.GetTopTrackPerYear()
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := export.WriteYearReport(file, export.BuildYearReport(perYear)); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Champions of %d years written to %s\n", len(perYear), path)
+	return file.Close()
+}
+
+// runCompareRanking prints how a friend's exported ranking agrees with ours
+func runCompareRanking(db *store.DB, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	theirs, err := export.ImportRankingJSON(file)
+	if err != nil {
+		return err
+	}
+
+	tracks, err := db.GetTopTracks(-1)
+	if err != nil {
+		return err
+	}
+
+	comparison := export.CompareRankings(export.BuildRanking(tracks), theirs)
+
+	fmt.Printf("🤝 Ranking comparison with %s\n", filepath.Base(path))
+	fmt.Println("════════════════════════════════════════")
+	fmt.Printf("Tracks in common: %d (only mine: %d, only theirs: %d)\n",
+		comparison.Common, len(comparison.OnlyMine), len(comparison.OnlyTheirs))
+
+	if math.IsNaN(comparison.Spearman) {
+		fmt.Println("Rank correlation: n/a (needs at least 2 tracks in common)")
+		return nil
+	}
+	fmt.Printf("Rank correlation (Spearman): %.2f\n", comparison.Spearman)
+
+	fmt.Println("\nBiggest disagreements (my rank → their rank):")
+	shown := 0
+	for _, d := range comparison.Disagreements {
+		if d.Diff == 0 || shown == MaxRankingDisagreements {
+			break
+		}
+		fmt.Printf("  #%-4d → #%-4d  %s - %s\n", d.MyRank, d.TheirRank, d.Name, d.Artist)
+		shown++
+	}
+	if shown == 0 {
+		fmt.Println("  None, you agree on every shared track 🎉")
+	}
+
+	return nil
+}
+
+// loadBattleQueue reads a battle queue file: one "left right" pair per line,
+// separated by spaces or a comma. Each side is a database ID or a Spotify track
+// ID, URI or URL already in the library. Blank lines and # comments are ignored.
+func loadBattleQueue(db *store.DB, path string) ([][2]int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var queue [][2]int64
+	scanner := bufio.NewScanner(file)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		})
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected 2 tracks, got %d", lineNum, len(fields))
+		}
+
+		var pair [2]int64
+		for i, field := range fields {
+			id, err := resolveQueuedTrack(db, field)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			pair[i] = id
+		}
+		if pair[0] == pair[1] {
+			return nil, fmt.Errorf("line %d: a track cannot battle itself", lineNum)
+		}
+		queue = append(queue, pair)
+	}
+
+	return queue, scanner.Err()
+}
+
+// resolveQueuedTrack returns the database ID of a queue file entry
+func resolveQueuedTrack(db *store.DB, value string) (int64, error) {
+	if id, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if _, err := db.GetTrackWithRating(id); err != nil {
+			return 0, fmt.Errorf("no track with ID %d", id)
+		}
+		return id, nil
+	}
+
+	spotifyID, err := spotify.ParseTrackID(value)
+	if err != nil {
+		return 0, err
+	}
+	track, err := db.GetTrackBySpotifyID(spotifyID)
+	if err != nil || track == nil {
+		return 0, fmt.Errorf("track %s is not in the library", spotifyID)
+	}
+	return track.ID, nil
+}
+
+// parseSince parses a relative duration ("7d", "2w", "36h") or a date ("2006-01-02")
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if date, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return date, nil
+	}
+
+	// Days and weeks are not supported by time.ParseDuration
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if number, ok := strings.CutSuffix(value, suffix); ok {
+			n, err := strconv.Atoi(number)
+			if err != nil || n < 0 {
+				return time.Time{}, fmt.Errorf("invalid duration %q", value)
+			}
+			return now.Add(-time.Duration(n) * unit), nil
+		}
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return time.Time{}, fmt.Errorf("invalid duration or date %q (examples: 7d, 2w, 36h, 2024-01-31)", value)
+	}
+	return now.Add(-duration), nil
+}
+
+// printDeferredSummary reports tracks waiting in the import retry queue
+func printDeferredSummary(db *store.DB) {
+	queue, err := db.GetImportQueue()
+	if err != nil || len(queue) == 0 {
+		return
+	}
+
+	fmt.Printf("⏳ %d tracks deferred due to rate limits; run -finish-import to complete.\n", len(queue))
+}
+
+// importUserTopTracks imports user's top tracks
+func importUserTopTracks(db *store.DB, client *spotify.Client, opts importOptions) error {
+	// Import short term top tracks
+	shortTermTracks, err := client.GetUserTopTracks(25, spotifyapi.ShortTermRange)
+	if err != nil {
+		fmt.Printf("⚠️  Failed to get short term tracks: %v\n", err)
+	} else {
+		if err := saveTracks(db, withSource(shortTermTracks, models.SourceTopShort), client, opts); err != nil {
+			return err
+		}
+		fmt.Printf("   ✓ %d short term tracks imported\n", len(shortTermTracks))
+	}
+
+	// Import medium term top tracks
+	mediumTermTracks, err := client.GetUserTopTracks(25, spotifyapi.MediumTermRange)
+	if err != nil {
+		fmt.Printf("⚠️  Failed to get medium term tracks: %v\n", err)
+	} else {
+		if err := saveTracks(db, withSource(mediumTermTracks, models.SourceTopMedium), client, opts); err != nil {
+			return err
+		}
+		fmt.Printf("   ✓ %d medium term tracks imported\n", len(mediumTermTracks))
+	}
+
+	// Import long term top tracks
+	longTermTracks, err := client.GetUserTopTracks(25, spotifyapi.LongTermRange)
+	if err != nil {
+		fmt.Printf("⚠️  Failed to get long term tracks: %v\n", err)
+	} else {
+		if err := saveTracks(db, withSource(longTermTracks, models.SourceTopLong), client, opts); err != nil {
+			return err
+		}
+		fmt.Printf("   ✓ %d long term tracks imported\n", len(longTermTracks))
+	}
+
+	return nil
+}
+
+// importRecommendations imports recommendations based on existing tracks
+func importRecommendations(db *store.DB, client *spotify.Client, opts importOptions) error {
+	// Get some existing tracks as seeds
+	existingTracks, err := db.GetTopTracks(5)
+	if err != nil || len(existingTracks) == 0 {
+		fmt.Println("   ⚠️  No existing tracks for recommendations")
+		return nil
+	}
+
+	// Use Spotify IDs as seeds
+	seeds := make([]string, 0, len(existingTracks))
+	for _, track := range existingTracks {
+		seeds = append(seeds, track.Track.SpotifyID)
+	}
+
+	// Get recommendations
+	recommendations, err := client.GetRecommendations(seeds[:min(2, len(seeds))], []string{}, []string{}, 20)
+	if err != nil {
+		return err
+	}
+
+	if err := saveTracks(db, withSource(recommendations, models.SourceRecommendation), client, opts); err != nil {
+		return err
+	}
+
+	fmt.Printf("   ✓ %d recommendations imported\n", len(recommendations))
+	return nil
+}
+
+// withSource tags tracks with the import they come from
+func withSource(tracks []*models.Track, source string) []*models.Track {
+	for _, track := range tracks {
+		track.Source = source
+	}
+	return tracks
+}
+
+// saveTracks saves a list of tracks to database
+// Tracks hitting Spotify rate limits are deferred to the import retry queue, along with their source
+func saveTracks(db *store.DB, tracks []*models.Track, client *spotify.Client, opts importOptions) error {
+	var deferred []string
+	deferredSources := map[string]string{}
+
+	for _, track := range tracks {
+		if opts.noExplicit && track.Explicit {
+			continue // Filtered out by -no-explicit
+		}
+
+		// Podcast episodes, local files and other items that are not songs
+		if reason := track.NotSongReason(); reason != "" {
+			logging.Printf("[import] skipped %q (%s): %s", track.Name, track.SpotifyID, reason)
+			continue
+		}
+
+		// Skip tracks already in the library, including the same recording under another Spotify ID
+		existing, err := db.FindExistingTrack(track)
+		if err != nil {
+			return fmt.Errorf("failed to look up track %s: %w", track.Name, err)
+		}
+		if existing != nil {
+			continue
+		}
+
+		// Enrich with audio features
+		if err := client.EnrichTrackWithAudioFeatures(track); err != nil {
+			if spotify.IsRateLimited(err) {
+				deferred = append(deferred, track.SpotifyID)
+				if track.Source != "" {
+					deferredSources[track.SpotifyID] = track.Source
+				}
+				continue // Retry later with -finish-import
+			}
+			fmt.Printf("   ⚠️  Failed to enrich %s: %v\n", track.Name, err)
+		}
 
 		// Save to database
 		if err := db.CreateTrack(track); err != nil {
@@ -268,6 +1832,15 @@ func saveTracks(db *store.DB, tracks []*models.Track, client *spotify.Client) er
 		}
 	}
 
+	if len(deferred) > 0 {
+		if err := db.AddToImportQueue(deferred...); err != nil {
+			return fmt.Errorf("failed to queue deferred tracks: %w", err)
+		}
+		if err := db.AddImportSources(deferredSources); err != nil {
+			return fmt.Errorf("failed to record deferred track sources: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -284,6 +1857,16 @@ func getDefaultDBPath() string {
 	return filepath.Join(configDir, DBName)
 }
 
+// getDefaultConfigPath returns the default configuration file path
+func getDefaultConfigPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ConfigName
+	}
+
+	return filepath.Join(homeDir, ".songbattle", ConfigName)
+}
+
 // showUsage displays usage help
 func showUsage() {
 	fmt.Printf(`🎵 %s v%s - Duel de chansons avec système Elo
@@ -294,10 +1877,54 @@ USAGE:
 OPTIONS:
     -client-id string       Client ID de votre application Spotify (requis)
     -db-path string         Chemin vers la base de données SQLite (défaut: ~/.songbattle/songbattle.db)
+    -config string          Fichier de configuration YAML (défaut: ~/.songbattle/config.yaml)
+    -log-file string        Écrit les traces de diagnostic (auth, base, Spotify) dans ce fichier
+    -no-summary             Quitter sans afficher le bilan de session
+    -no-animation           Afficher les nouveaux Elo sans animation après un vote
+    -skip-intro             Ne jamais afficher l'introduction du premier lancement
+    -rounds int             Session rapide : bilan après N duels, skips compris (ex: 10)
+    -queue string           Joue d'abord les duels listés dans ce fichier (une paire "gauche droite" par ligne)
+    -snippet-start duration Démarrer la lecture à cette position (ex: 45s)
+    -snippet-len duration   Mettre en pause après cette durée (ex: 20s)
+    -appearance-cooldown int Écarte un track des N duels suivant son apparition
+    -match-mode string      Stratégie de matchmaking : balanced (défaut) ou uncertainty (Elo les plus incertains d'abord)
+    -layout string          Cards côte à côte (horizontal), empilées (vertical) ou empilées si le terminal est étroit (auto)
     -import                 Mode import: récupère vos top tracks Spotify
+    -list                   Affiche le classement, une ligne par track, puis quitte
+    -stats                  Affiche les statistiques Elo et de matchmaking, puis quitte
+    -clean-unavailable      Supprime les tracks qui n'existent plus sur Spotify
+    -recluster              Recalcule l'humeur de chaque track à partir de ses audio features
+    -recompute              Recalcule tous les Elo en rejouant les duels avec les règles actuelles
+    -renormalize            Décale tous les Elo pour ramener la moyenne à 1200 (classement inchangé)
+    -merge-versions         Fusionne les versions clean et explicite d'un même morceau (Elo mélangé)
+    -fsck                   Vérifie l'intégrité de la base (ratings et duels orphelins, tracks sans rating) et propose de la réparer
+    -yes                    Ne pas demander de confirmation (taper CONFIRM) avant une suppression
+    -limit int              Nombre maximum de tracks pour -list (défaut: tous) ou -min-winrate (défaut: 50)
+    -min-winrate float      Exporte les tracks gagnant au moins ce %% de leurs duels, par Elo
+    -min-battles int        Duels minimum pour -min-winrate (défaut: 5)
+    -diff-since string      Évolution du classement depuis une durée (7d, 36h) ou une date
+    -import-artist string   Importe le catalogue d'un artiste (ID, URI ou URL Spotify)
+    -export-ranking string  Exporte le classement en JSON pour le partager
+    -compare-ranking string Compare le classement avec l'export JSON d'un ami (-export-ranking ou -export-json)
+    -export-csv string      Exporte en CSV tous les tracks avec leur rang, Elo et bilan
+    -export-json string     Exporte en JSON tous les tracks avec leur rating
+    -export-h2h string      Exporte en CSV le bilan de chaque paire de tracks s'étant affrontée
+    -export-years string    Exporte en texte le champion de chaque année et décennie
+    -no-explicit            Ignorer les morceaux explicites lors de l'import
+    -auto-calibrate         Duels automatiques (le plus populaire gagne) sur une copie de la base jusqu'à calibration
+    -dj-order int           Exporte le top N en playlist enchaînée par tempo et tonalité
+    -min-tracks int         Import automatique sous ce nombre de tracks (défaut: 2)
+    -no-auto-import         Désactive l'import automatique au lancement
+    -finish-import          Réessaie les tracks reportés à cause du rate limit Spotify
     -redirect-uri string    URI de redirection personnalisé (défaut: détection automatique)
     -use-custom-scheme      Force l'utilisation du schéma personnalisé 'songbattle://'
     -use-https              Force l'utilisation de HTTPS sur localhost:8080
+    -manual-auth            Coller l'URL de redirection au lieu du callback local
+    -reauth                 Oublie le token Spotify et redemande l'autorisation avec les scopes actuels
+    -export-tiered          Exporte le classement en plusieurs playlists (top 25, 50 et 100)
+    -tiers string           Tailles des playlists de -export-tiered, séparées par des virgules (ex: 10,40)
+    -force-export           Exporte même si le classement n'est pas encore stabilisé (export.min_calibration)
+    -public                 Crée des playlists publiques (demande le scope playlist-modify-public)
     -version                Affiche la version
     -help                   Affiche cette aide
 
@@ -329,16 +1956,46 @@ CONFIGURATION URI DE REDIRECTION:
 
 VARIABLES D'ENVIRONNEMENT:
     SPOTIFY_CLIENT_ID    Client ID Spotify (alternative au flag -client-id)
+    SONGBATTLE_DEBUG     Traces de diagnostic sur stderr si -log-file n'est pas utilisé
 
 CONTRÔLES DANS L'APPLICATION:
-    ←/→     Naviguer entre les chansons
+    ←/→     Naviguer entre les chansons (↑/↓ si les cards sont empilées)
     Espace  Écouter la chanson sélectionnée
     Entrée  Voter pour la chanson sélectionnée
-    S       Passer le duel
+    S       Passer le duel (enregistré, sans effet sur l'Elo)
+    Maj+D   Match nul : les deux chansons se valent
+    Maj+V   Vote en groupe : saisir les voix de chaque côté (ex: 3 contre 2)
+    Maj+Y   Champions par année et par décennie
+    Maj+P   Rejouer les duels de la session (ou de tout l'historique) avec leurs variations d'Elo
+    Maj+G   Duels entre deux genres (genre A contre genre B), ou retour à tous les genres
+    N       Nouvelle paire sans rien enregistrer
+    U       Annuler le dernier duel (skip compris)
+    Y       Historique des duels avec la variation d'Elo de chaque côté
+    A       Après un vote : redonner une chance au perdant
+    *       Épingler le track pour le calibrer en priorité
+    W       Définir/retirer une rivalité entre les deux tracks du duel
     T       Voir les caractéristiques audio
     G       Ouvrir dans Spotify
+    Maj+L   Changer de bibliothèque (section libraries: de la configuration)
+    C       Voir le classement
+    F       Classements par genre
+    X       Tracks les plus disputés (depuis le classement)
+    Z       Filtrer le classement par source d'import (depuis le classement)
+    Maj+C   Masquer/afficher les tracks en calibration (depuis le classement)
+    Maj+M   Humeurs : limiter les duels à une humeur, ou classement par humeur (depuis le classement)
+    D       Programmer un duel entre les 2 tracks cochés (depuis le classement)
+    =       Comparer les 2 tracks cochés côte à côte (depuis le classement)
+    B       Marquer/démarquer le track pour réécoute
+    V       Voir la liste de réécoute
+    /       Rechercher et ajouter un titre
+    Maj+R   Importer des recommandations tirées des meilleurs tracks actuels
+    R       Noter de 1 à 5 les nouveaux tracks après un extrait de 30s
+    I       Statistiques et distribution des Elo
+    O       Focus après un vote (côté gagnant / gauche / alterné)
+    %%       Afficher/masquer les probabilités de victoire sous les cards
+    Maj+H   Départ équitable : masquer l'Elo des tracks en calibration
     P       Exporter une playlist des meilleurs titres
-    Q       Quitter
+    Q       Quitter (avec bilan de session)
 
 PRÉREQUIS:
     - Compte Spotify Premium (pour la lecture audio)
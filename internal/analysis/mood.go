@@ -0,0 +1,38 @@
+package analysis
+
+import "songbattle/internal/models"
+
+// Humeurs, d'après l'énergie et la valence (positivité) des caractéristiques audio
+const (
+	MoodEnergetic = "energetic" // Énergique et positif
+	MoodIntense   = "intense"   // Énergique et sombre
+	MoodMellow    = "mellow"    // Calme et positif
+	MoodDark      = "dark"      // Calme et sombre
+)
+
+// Moods liste les humeurs dans l'ordre d'affichage
+var Moods = []string{MoodEnergetic, MoodIntense, MoodMellow, MoodDark}
+
+// moodThreshold sépare les valeurs hautes et basses d'énergie et de valence (échelle 0-1 de Spotify)
+const moodThreshold = 0.5
+
+// Mood classe un track dans l'une des quatre humeurs selon le quadrant énergie/valence
+// Retourne "" si ses caractéristiques audio n'ont jamais été récupérées
+func Mood(features models.AudioFeatures) string {
+	if features.Tempo <= 0 {
+		return ""
+	}
+
+	energetic := features.Energy >= moodThreshold
+	positive := features.Valence >= moodThreshold
+
+	switch {
+	case energetic && positive:
+		return MoodEnergetic
+	case energetic:
+		return MoodIntense
+	case positive:
+		return MoodMellow
+	}
+	return MoodDark
+}
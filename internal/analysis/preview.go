@@ -0,0 +1,323 @@
+package analysis
+
+import (
+	"errors"
+	"math"
+	"songbattle/internal/models"
+)
+
+// Estimation d'un profil audio grossier (énergie, tempo) à partir de l'extrait MP3 de 30 secondes
+// Aucun décodeur PCM n'est disponible : l'analyse lit seulement les en-têtes de frames et les
+// informations annexes de chaque granule (gain global et bits alloués), qui suivent le niveau
+// sonore et les attaques du signal
+
+// ErrPreviewUnreadable signale un extrait trop court ou qui n'est pas un MP3 Layer III exploitable
+var ErrPreviewUnreadable = errors.New("extrait audio illisible ou trop court")
+
+const (
+	minPreviewSeconds = 5.0   // Durée analysée en dessous de laquelle l'estimation n'a pas de sens
+	minPreviewTempo   = 60.0  // Tempo le plus lent cherché (BPM)
+	maxPreviewTempo   = 180.0 // Tempo le plus rapide cherché (BPM)
+	preferredTempo    = 120.0 // Tempo favorisé entre deux candidats proches (double/moitié)
+
+	quietGain = 120.0 // Gain global moyen d'un extrait très calme (énergie 0)
+	loudGain  = 180.0 // Gain global moyen d'un extrait très fort (énergie 1)
+
+	onsetGainStep   = 2.0  // Hausse de gain (≈ 3 dB) comptée comme une attaque
+	busyOnsetRate   = 0.2  // Proportion de granules en attaque d'un extrait très chargé
+	levelEnergyPart = 0.75 // Part du niveau moyen dans l'énergie, le reste venant des attaques
+)
+
+// granule décrit une granule MP3 (576 échantillons) : gain global moyen des canaux et bits alloués
+type granule struct {
+	gain float64
+	bits float64
+}
+
+// PreviewFeatures estime l'énergie et le tempo d'un extrait MP3
+// Le profil retourné est marqué Estimated ; les autres caractéristiques restent à zéro
+func PreviewFeatures(data []byte) (models.AudioFeatures, error) {
+	granules, granuleSeconds := readGranules(data)
+	if len(granules) == 0 || float64(len(granules))*granuleSeconds < minPreviewSeconds {
+		return models.AudioFeatures{}, ErrPreviewUnreadable
+	}
+
+	tempo := estimateTempo(granules, granuleSeconds)
+	if tempo == 0 {
+		return models.AudioFeatures{}, ErrPreviewUnreadable
+	}
+
+	return models.AudioFeatures{
+		Energy:    estimateEnergy(granules),
+		Tempo:     tempo,
+		Estimated: true,
+	}, nil
+}
+
+// estimateEnergy combine le niveau moyen et la densité d'attaques, ramenés sur l'échelle 0-1
+func estimateEnergy(granules []granule) float64 {
+	total, onsets := 0.0, 0
+	for i, g := range granules {
+		total += g.gain
+		if i > 0 && g.gain-granules[i-1].gain >= onsetGainStep {
+			onsets++
+		}
+	}
+
+	level := clamp01((total/float64(len(granules)) - quietGain) / (loudGain - quietGain))
+	activity := clamp01(float64(onsets) / float64(len(granules)) / busyOnsetRate)
+	return levelEnergyPart*level + (1-levelEnergyPart)*activity
+}
+
+// estimateTempo cherche la période la plus régulière des attaques par autocorrélation
+// Retourne 0 si l'extrait ne montre aucune attaque
+func estimateTempo(granules []granule, granuleSeconds float64) float64 {
+	envelope := onsetEnvelope(granules)
+
+	minLag := int(60 / maxPreviewTempo / granuleSeconds)
+	maxLag := int(math.Ceil(60 / minPreviewTempo / granuleSeconds))
+	if minLag < 1 || maxLag >= len(envelope) {
+		return 0
+	}
+
+	scores := make([]float64, maxLag+2)
+	for lag := minLag; lag <= maxLag+1 && lag < len(envelope); lag++ {
+		sum := 0.0
+		for i := lag; i < len(envelope); i++ {
+			sum += envelope[i] * envelope[i-lag]
+		}
+		scores[lag] = sum / float64(len(envelope)-lag)
+	}
+
+	bestLag, bestScore := 0, 0.0
+	for lag := minLag; lag <= maxLag; lag++ {
+		bpm := 60 / (float64(lag) * granuleSeconds)
+		// Préférence douce autour de 120 BPM : départage un tempo de son double ou de sa moitié
+		weight := math.Exp(-0.5 * math.Pow(math.Log2(bpm/preferredTempo), 2))
+		if score := scores[lag] * weight; score > bestScore {
+			bestLag, bestScore = lag, score
+		}
+	}
+	if bestLag == 0 {
+		return 0
+	}
+
+	// Interpolation parabolique autour du pic pour une période fractionnaire
+	period := float64(bestLag)
+	if bestLag > minLag {
+		prev, peak, next := scores[bestLag-1], scores[bestLag], scores[bestLag+1]
+		if curve := prev - 2*peak + next; curve < 0 {
+			period += 0.5 * (prev - next) / curve
+		}
+	}
+
+	return math.Round(60 / (period * granuleSeconds))
+}
+
+// onsetEnvelope mesure les hausses de gain et de bits alloués d'une granule à la suivante,
+// chacune normalisée par sa somme pour que l'une ne masque pas l'autre
+func onsetEnvelope(granules []granule) []float64 {
+	gainRises := make([]float64, len(granules))
+	bitRises := make([]float64, len(granules))
+	gainTotal, bitTotal := 0.0, 0.0
+	for i := 1; i < len(granules); i++ {
+		gainRises[i] = math.Max(0, granules[i].gain-granules[i-1].gain)
+		bitRises[i] = math.Max(0, granules[i].bits-granules[i-1].bits)
+		gainTotal += gainRises[i]
+		bitTotal += bitRises[i]
+	}
+
+	envelope := make([]float64, len(granules))
+	for i := range envelope {
+		if gainTotal > 0 {
+			envelope[i] += gainRises[i] / gainTotal
+		}
+		if bitTotal > 0 {
+			envelope[i] += bitRises[i] / bitTotal
+		}
+	}
+
+	// Centrer l'enveloppe : l'autocorrélation ne doit pas favoriser les petits décalages
+	mean := 0.0
+	for _, value := range envelope {
+		mean += value
+	}
+	mean /= float64(len(envelope))
+	for i := range envelope {
+		envelope[i] -= mean
+	}
+	return envelope
+}
+
+// clamp01 ramène une valeur dans [0, 1]
+func clamp01(value float64) float64 {
+	return math.Max(0, math.Min(1, value))
+}
+
+// Tables des en-têtes MPEG Layer III (kbps et Hz), indexées par version : MPEG-1 puis MPEG-2/2.5
+var (
+	layer3Bitrates = [2][15]int{
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+	}
+	sampleRates = map[int][3]int{
+		3: {44100, 48000, 32000}, // MPEG-1
+		2: {22050, 24000, 16000}, // MPEG-2
+		0: {11025, 12000, 8000},  // MPEG-2.5
+	}
+)
+
+// frameHeader est l'en-tête décodé d'une frame MPEG Layer III
+type frameHeader struct {
+	mpeg1      bool
+	crc        bool
+	channels   int
+	sampleRate int
+	length     int // Taille de la frame en octets, en-tête compris
+}
+
+// parseFrameHeader décode l'en-tête de frame situé au début de data
+func parseFrameHeader(data []byte) (frameHeader, bool) {
+	if len(data) < 4 || data[0] != 0xFF || data[1]&0xE0 != 0xE0 {
+		return frameHeader{}, false
+	}
+
+	version := int(data[1]>>3) & 0x03
+	layer := int(data[1]>>1) & 0x03
+	bitrateIndex := int(data[2] >> 4)
+	rateIndex := int(data[2]>>2) & 0x03
+	rates, ok := sampleRates[version]
+	if !ok || layer != 1 || bitrateIndex == 0 || bitrateIndex == 15 || rateIndex == 3 {
+		return frameHeader{}, false
+	}
+
+	header := frameHeader{
+		mpeg1:      version == 3,
+		crc:        data[1]&0x01 == 0,
+		channels:   2,
+		sampleRate: rates[rateIndex],
+	}
+	if data[3]>>6 == 3 {
+		header.channels = 1
+	}
+
+	table, coefficient := 0, 144
+	if !header.mpeg1 {
+		table, coefficient = 1, 72
+	}
+	padding := int(data[2]>>1) & 0x01
+	header.length = coefficient*layer3Bitrates[table][bitrateIndex]*1000/header.sampleRate + padding
+	return header, true
+}
+
+// readGranules parcourt les frames de l'extrait et retourne ses granules non silencieuses,
+// ainsi que la durée d'une granule en secondes
+func readGranules(data []byte) ([]granule, float64) {
+	pos := skipID3(data)
+	var granules []granule
+	granuleSeconds := 0.0
+
+	for pos+4 <= len(data) {
+		header, ok := parseFrameHeader(data[pos:])
+		if !ok || header.length <= 4 || pos+header.length > len(data) {
+			pos++ // Resynchronisation sur la frame suivante
+			continue
+		}
+
+		granuleSeconds = 576 / float64(header.sampleRate)
+		sideInfo := data[pos+4:]
+		if header.crc {
+			sideInfo = sideInfo[2:]
+		}
+		for _, g := range readSideInfo(sideInfo, header) {
+			// Gain nul : frame Xing/Info ou silence numérique, sans information sur le signal
+			if g.gain > 0 {
+				granules = append(granules, g)
+			}
+		}
+		pos += header.length
+	}
+
+	return granules, granuleSeconds
+}
+
+// readSideInfo lit le gain global et les bits alloués de chaque granule d'une frame
+// (deux granules en MPEG-1, une seule en MPEG-2/2.5)
+func readSideInfo(data []byte, header frameHeader) []granule {
+	reader := bitReader{data: data}
+	granuleCount, channelBits := 2, 59
+	if header.mpeg1 {
+		reader.skip(9) // main_data_begin
+		if header.channels == 1 {
+			reader.skip(5) // private_bits
+		} else {
+			reader.skip(3)
+		}
+		reader.skip(4 * header.channels) // scfsi
+	} else {
+		granuleCount, channelBits = 1, 63
+		reader.skip(8)
+		reader.skip(header.channels) // private_bits
+	}
+
+	granules := make([]granule, 0, granuleCount)
+	for gr := 0; gr < granuleCount; gr++ {
+		var g granule
+		for ch := 0; ch < header.channels; ch++ {
+			g.bits += float64(reader.read(12)) // part2_3_length
+			reader.skip(9)                     // big_values
+			g.gain += float64(reader.read(8))  // global_gain
+			reader.skip(channelBits - 29)
+		}
+		if reader.overflow {
+			break
+		}
+		g.gain /= float64(header.channels)
+		granules = append(granules, g)
+	}
+	return granules
+}
+
+// skipID3 retourne la position suivant un éventuel tag ID3v2 en tête de fichier
+func skipID3(data []byte) int {
+	if len(data) < 10 || string(data[:3]) != "ID3" {
+		return 0
+	}
+	// Taille "synchsafe" : 7 bits utiles par octet
+	size := int(data[6])<<21 | int(data[7])<<14 | int(data[8])<<7 | int(data[9])
+	size += 10
+	if data[5]&0x10 != 0 {
+		size += 10 // Pied de tag
+	}
+	if size > len(data) {
+		return len(data)
+	}
+	return size
+}
+
+// bitReader lit des champs de bits successifs (poids fort en premier)
+type bitReader struct {
+	data     []byte
+	pos      int // Position en bits
+	overflow bool
+}
+
+// read lit n bits ; au-delà de la fin des données, overflow est positionné et 0 retourné
+func (r *bitReader) read(n int) int {
+	if r.pos+n > len(r.data)*8 {
+		r.overflow = true
+		return 0
+	}
+	value := 0
+	for i := 0; i < n; i++ {
+		bit := (r.data[(r.pos+i)/8] >> (7 - uint((r.pos+i)%8))) & 1
+		value = value<<1 | int(bit)
+	}
+	r.pos += n
+	return value
+}
+
+// skip saute n bits
+func (r *bitReader) skip(n int) {
+	r.read(n)
+}
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"bufio"
 	"context"
 	"crypto/rand"
 	"crypto/sha256"
@@ -9,9 +10,10 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"runtime"
+	"songbattle/internal/logging"
 	"songbattle/internal/models"
 	"songbattle/internal/store"
-	"strconv"
 	"strings"
 	"time"
 
@@ -27,6 +29,9 @@ const (
 	HTTPSRedirectURI  = "https://localhost:8080/callback" // HTTPS alternative
 	CallbackPort      = ":8080"
 	CustomSchemePort  = ":8081" // Alternative port for custom scheme
+
+	// ManualAuthDelay is how long to wait for the callback before offering manual input
+	ManualAuthDelay = 90 * time.Second
 )
 
 var RequiredScopes = []string{
@@ -37,12 +42,18 @@ var RequiredScopes = []string{
 	"user-top-read",
 }
 
+// PublicPlaylistScope is only requested when public playlist export is enabled
+const PublicPlaylistScope = "playlist-modify-public"
+
 type SpotifyAuth struct {
 	ClientID        string
 	config          *oauth2.Config
 	db              *store.DB
 	redirectURI     string // Automatically detected redirect URI
 	useCustomScheme bool   // Uses custom scheme or HTTP(S)
+
+	// ManualAuth skips the local callback server: the redirect URL is pasted in the terminal
+	ManualAuth bool
 }
 
 // NewSpotifyAuth creates a new Spotify authentication instance
@@ -75,13 +86,38 @@ func NewSpotifyAuthWithOptions(clientID string, db *store.DB, customRedirectURI
 	return newSpotifyAuthWithOptions(clientID, db, redirectURI, useCustomScheme)
 }
 
+// AddScopes requests extra scopes on top of RequiredScopes
+// A stored token granted without them triggers a new consent
+func (sa *SpotifyAuth) AddScopes(scopes ...string) {
+	for _, scope := range scopes {
+		if !containsScope(sa.config.Scopes, scope) {
+			sa.config.Scopes = append(sa.config.Scopes, scope)
+		}
+	}
+}
+
+// Scopes returns the scopes requested when asking for consent
+func (sa *SpotifyAuth) Scopes() []string {
+	return append([]string(nil), sa.config.Scopes...)
+}
+
+// containsScope checks if a scope is in the list
+func containsScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
 // newSpotifyAuthWithOptions internal function to create the instance
 func newSpotifyAuthWithOptions(clientID string, db *store.DB, redirectURI string, useCustomScheme bool) *SpotifyAuth {
 
 	config := &oauth2.Config{
 		ClientID:    clientID,
 		RedirectURL: redirectURI,
-		Scopes:      RequiredScopes,
+		Scopes:      append([]string(nil), RequiredScopes...),
 		Endpoint: oauth2.Endpoint{
 			AuthURL:  SpotifyAuthURL,
 			TokenURL: SpotifyTokenURL,
@@ -97,16 +133,9 @@ func newSpotifyAuthWithOptions(clientID string, db *store.DB, redirectURI string
 	}
 }
 
-// isDebugEnabled checks if debug mode is enabled
-func isDebugEnabled() bool {
-	return os.Getenv("SONGBATTLE_DEBUG") != ""
-}
-
-// debugLog displays a debug message if debug mode is enabled
+// debugLog writes an auth diagnostic to the debug log (see -log-file)
 func debugLog(msg string, args ...interface{}) {
-	if isDebugEnabled() {
-		fmt.Printf("🐛 [DEBUG] "+msg+"\n", args...)
-	}
+	logging.Printf("[auth] "+msg, args...)
 }
 
 // detectBestRedirectURI automatically detects the best redirect URI
@@ -151,6 +180,92 @@ func isHTTPSLocalhostAvailable() bool {
 	return false
 }
 
+// IsHeadless reports whether no browser can be opened (SSH session, server without display)
+func IsHeadless() bool {
+	// An explicit browser command always wins
+	if os.Getenv("BROWSER") != "" {
+		return false
+	}
+
+	// macOS and Windows always have a desktop session available
+	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		return os.Getenv("SSH_CONNECTION") != "" || os.Getenv("SSH_TTY") != ""
+	}
+
+	return os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == ""
+}
+
+// extractAuthCode extracts the authorization code from a pasted redirect URL or a raw code
+// When the pasted URL carries a state, it must match the one sent to Spotify
+func extractAuthCode(input, expectedState string) (string, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", fmt.Errorf("empty input")
+	}
+
+	// Raw code pasted on its own
+	if !strings.Contains(input, "?") && !strings.Contains(input, "=") {
+		return input, nil
+	}
+
+	// Full redirect URL (or just its query string)
+	query := input
+	if idx := strings.Index(input, "?"); idx >= 0 {
+		query = input[idx+1:]
+	}
+
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return "", fmt.Errorf("invalid redirect URL: %w", err)
+	}
+
+	if errParam := values.Get("error"); errParam != "" {
+		return "", fmt.Errorf("authorization denied: %s", errParam)
+	}
+
+	code := values.Get("code")
+	if code == "" {
+		return "", fmt.Errorf("no authorization code found in %q", input)
+	}
+
+	if state := values.Get("state"); state != "" && state != expectedState {
+		return "", fmt.Errorf("state mismatch, make sure you pasted the URL from this login attempt")
+	}
+
+	return code, nil
+}
+
+// promptManualAuth explains how to complete authentication by pasting the redirect URL
+func promptManualAuth(authURL string) {
+	fmt.Println("1. Open this URL on any device:")
+	fmt.Println()
+	fmt.Printf("   %s\n", authURL)
+	fmt.Println()
+	fmt.Println("2. After approving, paste the full URL you were redirected to (or just the code):")
+}
+
+// readAuthCodeFromStdin waits for the redirect URL or code to be pasted in the terminal
+// The reader goroutine cannot be interrupted: it is only started when manual input is needed
+func readAuthCodeFromStdin(expectedState string, codeChan chan string) {
+	go func() {
+		reader := bufio.NewReader(os.Stdin)
+		for {
+			line, err := reader.ReadString('\n')
+			if line != "" {
+				code, parseErr := extractAuthCode(line, expectedState)
+				if parseErr == nil {
+					codeChan <- code
+					return
+				}
+				fmt.Printf("⚠️  %v - paste the full redirect URL or the code:\n", parseErr)
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+}
+
 // generateCodeVerifier generates a code verifier for PKCE
 func generateCodeVerifier() (string, error) {
 	bytes := make([]byte, 32)
@@ -160,6 +275,15 @@ func generateCodeVerifier() (string, error) {
 	return base64.URLEncoding.WithPadding(base64.NoPadding).EncodeToString(bytes), nil
 }
 
+// generateState generates a random OAuth state
+func generateState() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.WithPadding(base64.NoPadding).EncodeToString(bytes), nil
+}
+
 // generateCodeChallenge generates a code challenge from the verifier
 func generateCodeChallenge(verifier string) string {
 	hash := sha256.Sum256([]byte(verifier))
@@ -175,74 +299,87 @@ func (sa *SpotifyAuth) Authenticate(ctx context.Context) (*oauth2.Token, error)
 	}
 	codeChallenge := generateCodeChallenge(codeVerifier)
 
+	// State anti-CSRF, vérifié sur le callback comme sur l'URL collée
+	state, err := generateState()
+	if err != nil {
+		return nil, fmt.Errorf("state generation error: %w", err)
+	}
+
 	// Canal pour recevoir le code d'autorisation
 	codeChan := make(chan string, 1)
 	errChan := make(chan error, 1)
 
-	// Configuration du serveur selon le type d'URI
-	var server *http.Server
-	var port string
-
-	if sa.useCustomScheme {
-		port = CustomSchemePort
-		server = &http.Server{Addr: port}
-	} else {
-		port = CallbackPort
-		server = &http.Server{Addr: port}
-	}
-
-	// Configuration du handler selon le type d'URI
-	if sa.useCustomScheme {
-		// Handler for custom scheme - listens on all paths
-		http.HandleFunc("/", sa.handleCustomSchemeCallback(codeChan, errChan))
-	} else {
-		// Handler classique pour HTTP(S)
-		http.HandleFunc("/callback", sa.handleHTTPCallback(codeChan, errChan))
-	}
-
-	// Launch server in background
-	go func() {
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			errChan <- fmt.Errorf("erreur serveur callback: %w", err)
-		}
-	}()
-
 	// Construire l'URL d'autorisation avec PKCE
-	authURL := sa.config.AuthCodeURL("state",
+	authURL := sa.config.AuthCodeURL(state,
 		oauth2.SetAuthURLParam("code_challenge", codeChallenge),
 		oauth2.SetAuthURLParam("code_challenge_method", "S256"))
 
 	fmt.Println("🎵 Spotify authentication required")
-	if sa.useCustomScheme {
-		fmt.Println("🔒 Using secure mode (Custom Scheme)")
-		fmt.Printf("🌐 Listening on: localhost%s\n", port)
-	} else {
-		fmt.Printf("🌐 Listening on: localhost%s\n", port)
+
+	// Saisie manuelle demandée : pas de serveur de callback
+	manual := sa.ManualAuth || IsHeadless()
+	var server *http.Server
+	if !sa.ManualAuth {
+		server = sa.startCallbackServer(state, codeChan, errChan)
 	}
-	fmt.Println("Opening your browser...")
-	fmt.Printf("If it doesn't work, copy this URL: %s\n", authURL)
 
-	// Open browser
-	if err := browser.OpenURL(authURL); err != nil {
-		fmt.Printf("Failed to open browser: %v\n", err)
-		fmt.Printf("Please open manually: %s\n", authURL)
+	if manual {
+		// No browser or no reachable loopback: the user opens the URL elsewhere
+		// and pastes back the redirect URL
+		if IsHeadless() {
+			fmt.Println()
+			fmt.Println("🖥️  No browser detected (headless/SSH session)")
+		}
+		promptManualAuth(authURL)
+		readAuthCodeFromStdin(state, codeChan)
+	} else {
+		fmt.Println("Opening your browser...")
+		fmt.Printf("If it doesn't work, copy this URL: %s\n", authURL)
+
+		// Open browser
+		if err := browser.OpenURL(authURL); err != nil {
+			fmt.Printf("Failed to open browser: %v\n", err)
+			fmt.Printf("Please open manually: %s\n", authURL)
+		}
 	}
 
 	// Attendre le code ou une erreur
+	// Sans callback reçu après ManualAuthDelay, proposer la saisie manuelle
 	var code string
-	select {
-	case code = <-codeChan:
-		// Code received
-	case err := <-errChan:
-		return nil, err
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case <-time.After(5 * time.Minute):
-		return nil, fmt.Errorf("timeout authentification")
+	manualTimer := time.NewTimer(ManualAuthDelay)
+	defer manualTimer.Stop()
+	timeout := time.After(5 * time.Minute)
+
+wait:
+	for {
+		select {
+		case code = <-codeChan:
+			break wait
+		case err := <-errChan:
+			if !manual {
+				return nil, err
+			}
+			// Le serveur local est optionnel en saisie manuelle
+			fmt.Printf("⚠️  %v (continuing with manual input)\n", err)
+		case <-manualTimer.C:
+			if !manual {
+				manual = true
+				fmt.Println()
+				fmt.Println("⌛ Still waiting for the browser redirect...")
+				promptManualAuth(authURL)
+				readAuthCodeFromStdin(state, codeChan)
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timeout:
+			return nil, fmt.Errorf("timeout authentification")
+		}
 	}
 
 	// Fermer le serveur
-	server.Shutdown(context.Background())
+	if server != nil {
+		server.Shutdown(context.Background())
+	}
 
 	// Exchange code for token with PKCE
 	token, err := sa.exchangeCodeForToken(code, codeVerifier)
@@ -258,6 +395,39 @@ func (sa *SpotifyAuth) Authenticate(ctx context.Context) (*oauth2.Token, error)
 	return token, nil
 }
 
+// startCallbackServer launches the local server receiving the Spotify redirect
+func (sa *SpotifyAuth) startCallbackServer(state string, codeChan chan string, errChan chan error) *http.Server {
+	// Configuration du serveur selon le type d'URI
+	mux := http.NewServeMux()
+	var port string
+
+	if sa.useCustomScheme {
+		// Handler for custom scheme - listens on all paths
+		port = CustomSchemePort
+		mux.HandleFunc("/", sa.handleCustomSchemeCallback(state, codeChan, errChan))
+	} else {
+		// Handler classique pour HTTP(S)
+		port = CallbackPort
+		mux.HandleFunc("/callback", sa.handleHTTPCallback(state, codeChan, errChan))
+	}
+
+	server := &http.Server{Addr: port, Handler: mux}
+
+	if sa.useCustomScheme {
+		fmt.Println("🔒 Using secure mode (Custom Scheme)")
+	}
+	fmt.Printf("🌐 Listening on: localhost%s\n", port)
+
+	// Launch server in background
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errChan <- fmt.Errorf("erreur serveur callback: %w", err)
+		}
+	}()
+
+	return server
+}
+
 // exchangeCodeForToken exchanges authorization code for access token
 func (sa *SpotifyAuth) exchangeCodeForToken(code, codeVerifier string) (*oauth2.Token, error) {
 	data := url.Values{}
@@ -284,8 +454,14 @@ func (sa *SpotifyAuth) SaveToken(token *oauth2.Token) error {
 	}
 
 	if !token.Expiry.IsZero() {
-		expiryStr := strconv.FormatInt(token.Expiry.Unix(), 10)
-		if err := sa.db.SetMeta(models.MetaKeyTokenExpiry, expiryStr); err != nil {
+		if err := sa.db.SetMetaTime(models.MetaKeyTokenExpiry, token.Expiry); err != nil {
+			return err
+		}
+	}
+
+	// Scopes accordés (absents des refresh qui ne les modifient pas)
+	if scope, ok := token.Extra("scope").(string); ok && scope != "" {
+		if err := sa.db.SetMeta(models.MetaKeyTokenScopes, scope); err != nil {
 			return err
 		}
 	}
@@ -293,6 +469,38 @@ func (sa *SpotifyAuth) SaveToken(token *oauth2.Token) error {
 	return nil
 }
 
+// SwitchDB stores tokens in another database from now on
+// The current token is copied over when the new database has none, so the session stays logged in
+func (sa *SpotifyAuth) SwitchDB(db *store.DB) error {
+	token, loadErr := sa.LoadToken()
+	sa.db = db
+
+	if loadErr != nil {
+		return nil // Nothing to carry over
+	}
+	if _, err := db.GetMeta(models.MetaKeyAccessToken); err == nil {
+		return nil // The new database already has its own token
+	}
+	return sa.SaveToken(token)
+}
+
+// hasRequiredScopes checks that the stored token was granted every configured scope
+// Tokens saved before scopes were recorded are assumed valid
+func (sa *SpotifyAuth) hasRequiredScopes() bool {
+	granted, err := sa.db.GetMeta(models.MetaKeyTokenScopes)
+	if err != nil {
+		return true
+	}
+
+	grantedScopes := strings.Fields(granted)
+	for _, scope := range sa.config.Scopes {
+		if !containsScope(grantedScopes, scope) {
+			return false
+		}
+	}
+	return true
+}
+
 // LoadToken loads the token from database
 func (sa *SpotifyAuth) LoadToken() (*oauth2.Token, error) {
 	accessToken, err := sa.db.GetMeta(models.MetaKeyAccessToken)
@@ -308,10 +516,8 @@ func (sa *SpotifyAuth) LoadToken() (*oauth2.Token, error) {
 	}
 
 	// Expiry (optionnel)
-	if expiryStr, err := sa.db.GetMeta(models.MetaKeyTokenExpiry); err == nil {
-		if expiryUnix, err := strconv.ParseInt(expiryStr, 10, 64); err == nil {
-			token.Expiry = time.Unix(expiryUnix, 0)
-		}
+	if expiry, err := sa.db.GetMetaTime(models.MetaKeyTokenExpiry, time.Time{}); err == nil {
+		token.Expiry = expiry
 	}
 
 	return token, nil
@@ -360,6 +566,12 @@ func (sa *SpotifyAuth) GetValidToken(ctx context.Context) (*oauth2.Token, error)
 		return sa.Authenticate(ctx)
 	}
 
+	// Un token autorisé avec un ancien jeu de scopes doit être redemandé
+	if !sa.hasRequiredScopes() {
+		debugLog("Stored token lacks required scopes, new authentication required")
+		return sa.Authenticate(ctx)
+	}
+
 	// Vérifier si le token est valide
 	if sa.IsTokenValid(token) {
 		debugLog("Existing token valid, reusing")
@@ -383,8 +595,13 @@ func (sa *SpotifyAuth) GetValidToken(ctx context.Context) (*oauth2.Token, error)
 }
 
 // handleHTTPCallback gère les callbacks HTTP/HTTPS classiques
-func (sa *SpotifyAuth) handleHTTPCallback(codeChan chan string, errChan chan error) http.HandlerFunc {
+func (sa *SpotifyAuth) handleHTTPCallback(state string, codeChan chan string, errChan chan error) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("state") != state {
+			http.Error(w, "state mismatch", http.StatusBadRequest)
+			return
+		}
+
 		code := r.URL.Query().Get("code")
 		if code == "" {
 			errChan <- fmt.Errorf("no authorization code received")
@@ -411,8 +628,13 @@ func (sa *SpotifyAuth) handleHTTPCallback(codeChan chan string, errChan chan err
 }
 
 // handleCustomSchemeCallback gère les callbacks de custom scheme
-func (sa *SpotifyAuth) handleCustomSchemeCallback(codeChan chan string, errChan chan error) http.HandlerFunc {
+func (sa *SpotifyAuth) handleCustomSchemeCallback(state string, codeChan chan string, errChan chan error) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("state") != state {
+			http.Error(w, "state mismatch", http.StatusBadRequest)
+			return
+		}
+
 		// Pour les custom schemes, Spotify redirigera vers songbattle://callback?code=...
 		// Mais l'OS peut rediriger vers http://localhost:8081/?code=...
 		code := r.URL.Query().Get("code")
@@ -451,5 +673,8 @@ func (sa *SpotifyAuth) Logout() error {
 	if err := sa.db.DeleteMeta(models.MetaKeyTokenExpiry); err != nil {
 		return err
 	}
+	if err := sa.db.DeleteMeta(models.MetaKeyTokenScopes); err != nil {
+		return err
+	}
 	return nil
 }
@@ -0,0 +1,260 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config regroupe les réglages optionnels lus depuis le fichier YAML
+type Config struct {
+	Auth        AuthConfig        `yaml:"auth"`
+	Matchmaking MatchmakingConfig `yaml:"matchmaking"`
+	Elo         EloConfig         `yaml:"elo"`
+	Export      ExportConfig      `yaml:"export"`
+	Playback    PlaybackConfig    `yaml:"playback"`
+	UI          UIConfig          `yaml:"ui"`
+	Keymap      KeymapConfig      `yaml:"keymap"`
+	Libraries   []LibraryConfig   `yaml:"libraries"`
+}
+
+// AuthConfig contient les réglages de l'authentification Spotify
+type AuthConfig struct {
+	// ManualAuth remplace le callback local par le collage de l'URL de redirection
+	ManualAuth bool `yaml:"manual_auth"`
+}
+
+// MatchmakingConfig contient les réglages du matchmaking
+type MatchmakingConfig struct {
+	// PopularityExploration privilégie les tracks populaires sur Spotify
+	// parmi les tracks peu joués lors des duels d'exploration
+	PopularityExploration bool `yaml:"popularity_exploration"`
+
+	// ExcludeExplicit écarte les tracks explicites des duels
+	ExcludeExplicit bool `yaml:"exclude_explicit"`
+
+	// PinnedRate est la part des duels (0-1) où le track épinglé est placé à gauche
+	PinnedRate float64 `yaml:"pinned_rate"`
+
+	// PinnedBattles est le nombre de duels après lequel un track est désépinglé
+	PinnedBattles int `yaml:"pinned_battles"`
+
+	// SessionPenalty est la pénalité (en points d'Elo) par apparition d'un track
+	// dans la session, pour répartir les duels sur toute la bibliothèque (0 = désactivé)
+	SessionPenalty int `yaml:"session_penalty"`
+
+	// RivalryRate est la part des duels (0-1) réservée aux rivalités définies par l'utilisateur
+	RivalryRate float64 `yaml:"rivalry_rate"`
+
+	// AppearanceCooldown est le nombre de matchs pendant lesquels un track qui vient
+	// d'apparaître n'est pas reproposé, s'il reste assez d'autres tracks (0 = désactivé)
+	AppearanceCooldown int `yaml:"appearance_cooldown"`
+
+	// Mode choisit la stratégie de tirage des paires (MatchModeBalanced ou MatchModeUncertainty)
+	Mode string `yaml:"mode"`
+}
+
+// Stratégies de matchmaking
+const (
+	// MatchModeBalanced alterne exploration des tracks peu joués et duels à Elo proche
+	MatchModeBalanced = "balanced"
+
+	// MatchModeUncertainty oppose en priorité les tracks dont l'Elo est le plus incertain
+	MatchModeUncertainty = "uncertainty"
+)
+
+// ValidMatchMode indique si mode est une stratégie de matchmaking connue
+func ValidMatchMode(mode string) bool {
+	return mode == MatchModeBalanced || mode == MatchModeUncertainty
+}
+
+// EloConfig contient les réglages du système Elo
+type EloConfig struct {
+	// SkipUpdatesLastSeen fait compter un skip comme une apparition (LastSeenAt)
+	// Désactivé par défaut : un track toujours passé reste considéré comme ignoré
+	// Annuler un skip ne rétablit pas cette date
+	SkipUpdatesLastSeen bool `yaml:"skip_updates_last_seen"`
+
+	// WeightByListenTime réduit le facteur K des votes émis après une écoute courte,
+	// mesurée de la première lecture d'un extrait du duel jusqu'au vote
+	// Désactivé par défaut : tous les votes ont le même poids
+	WeightByListenTime bool `yaml:"weight_by_listen_time"`
+
+	// FullListenTime est la durée d'écoute donnant son plein poids à un vote
+	// (ramenée à la durée des extraits si elle est plus courte)
+	FullListenTime time.Duration `yaml:"full_listen_time"`
+}
+
+// ExportConfig contient les réglages de l'export de playlists
+type ExportConfig struct {
+	// ExcludeExplicit écarte les tracks explicites des playlists exportées
+	ExcludeExplicit bool `yaml:"exclude_explicit"`
+
+	// Public crée des playlists publiques (nécessite le scope playlist-modify-public)
+	Public bool `yaml:"public"`
+
+	// IncludeUnavailable garde les tracks signalés indisponibles sur Spotify (écartés par défaut)
+	IncludeUnavailable bool `yaml:"include_unavailable"`
+
+	// MinCalibration est le pourcentage de tracks calibrés exigé avant d'exporter
+	// un classement en playlist (0 = pas de contrôle)
+	MinCalibration float64 `yaml:"min_calibration"`
+}
+
+// PlaybackConfig contient les réglages de lecture des extraits
+type PlaybackConfig struct {
+	// SnippetStart démarre la lecture après l'intro (ex: "45s")
+	SnippetStart time.Duration `yaml:"snippet_start"`
+
+	// SnippetLength met la lecture en pause après cette durée (0 = lecture complète)
+	SnippetLength time.Duration `yaml:"snippet_length"`
+
+	// PauseOnNext met en pause, au duel suivant, le morceau lancé depuis l'application
+	// La musique lancée ailleurs n'est jamais interrompue
+	PauseOnNext bool `yaml:"pause_on_next"`
+}
+
+// UIConfig contient les réglages de l'interface
+type UIConfig struct {
+	// SessionSummary affiche un bilan de session avant de quitter avec 'q'
+	SessionSummary bool `yaml:"session_summary"`
+
+	// AutosaveInterval est l'intervalle de sauvegarde des compteurs de session,
+	// repris au lancement suivant après un arrêt brutal (0 = désactivé)
+	AutosaveInterval time.Duration `yaml:"autosave_interval"`
+
+	// Animation fait défiler l'Elo des cards après un vote
+	Animation bool `yaml:"animation"`
+
+	// DailyGoal est le nombre de duels visé chaque jour (0 = désactivé)
+	DailyGoal int `yaml:"daily_goal"`
+
+	// Rounds lance une session rapide au démarrage : le bilan s'affiche après ce nombre
+	// de duels (skips compris) ; c'est aussi la durée d'une session rapide lancée avec Q
+	// (0 = sessions libres, 10 duels pour Q)
+	Rounds int `yaml:"rounds"`
+
+	// SkipIntro n'affiche jamais l'introduction du premier lancement
+	SkipIntro bool `yaml:"skip_intro"`
+
+	// FairStart masque l'Elo et le bilan des tracks ayant disputé moins de FairStartBattles duels,
+	// pour ne pas influencer les votes pendant la calibration
+	FairStart        bool `yaml:"fair_start"`
+	FairStartBattles int  `yaml:"fair_start_battles"`
+
+	// Layout règle les dimensions de l'affichage
+	Layout LayoutConfig `yaml:"layout"`
+}
+
+// LayoutConfig contient les dimensions des cards et du classement
+// Les valeurs sont réduites si le terminal est trop petit
+type LayoutConfig struct {
+	CardWidth       int `yaml:"card_width"`       // Largeur des cards de duel
+	CardHeight      int `yaml:"card_height"`      // Hauteur des cards de duel
+	LeaderboardRows int `yaml:"leaderboard_rows"` // Lignes visibles du classement (0 = toute la hauteur du terminal)
+
+	// Orientation place les cards de duel côte à côte ou l'une sous l'autre
+	// (OrientationAuto empile les cards quand le terminal est trop étroit)
+	Orientation string `yaml:"orientation"`
+}
+
+// Orientations des cards de duel
+const (
+	OrientationAuto       = "auto"
+	OrientationHorizontal = "horizontal"
+	OrientationVertical   = "vertical"
+)
+
+// ValidOrientation indique si orientation est une orientation connue
+func ValidOrientation(orientation string) bool {
+	switch orientation {
+	case OrientationAuto, OrientationHorizontal, OrientationVertical:
+		return true
+	}
+	return false
+}
+
+// KeymapConfig associe des actions du clavier (ex: "vote-left") aux touches qui les déclenchent
+// Les touches listées remplacent celles par défaut de l'action ; les autres actions gardent les leurs
+type KeymapConfig map[string][]string
+
+// LibraryConfig décrit une bibliothèque (fichier de base de données) ouvrable depuis l'interface
+type LibraryConfig struct {
+	Name string `yaml:"name"` // Nom affiché dans le sélecteur
+	Path string `yaml:"path"` // Chemin de la base SQLite (~ accepté)
+}
+
+// ResolvedPath retourne le chemin de la base, ~ remplacé par le dossier personnel
+func (l LibraryConfig) ResolvedPath() string {
+	if l.Path == "~" || strings.HasPrefix(l.Path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(l.Path, "~"))
+		}
+	}
+	return l.Path
+}
+
+// Default retourne la configuration par défaut
+func Default() *Config {
+	return &Config{
+		Elo: EloConfig{
+			FullListenTime: 30 * time.Second,
+		},
+		Export: ExportConfig{
+			MinCalibration: 50,
+		},
+		Playback: PlaybackConfig{
+			PauseOnNext: true,
+		},
+		Matchmaking: MatchmakingConfig{
+			PopularityExploration: false,
+			PinnedRate:            0.75,
+			PinnedBattles:         10,
+			SessionPenalty:        15,
+			RivalryRate:           0.1,
+			Mode:                  MatchModeBalanced,
+		},
+		UI: UIConfig{
+			SessionSummary:   true,
+			AutosaveInterval: 30 * time.Second,
+			Animation:        true,
+			DailyGoal:        10,
+			FairStart:        true,
+			FairStartBattles: 5,
+			Layout: LayoutConfig{
+				CardWidth:       40,
+				CardHeight:      8,
+				LeaderboardRows: 0,
+				Orientation:     OrientationAuto,
+			},
+		},
+	}
+}
+
+// Load charge la configuration depuis un fichier YAML
+// Un fichier absent n'est pas une erreur : la configuration par défaut est retournée
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lecture configuration: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("configuration invalide %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
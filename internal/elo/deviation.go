@@ -0,0 +1,69 @@
+package elo
+
+import (
+	"math"
+	"songbattle/internal/models"
+	"time"
+)
+
+// Écart type de l'Elo (RD de Glicko) : l'incertitude sur le niveau réel d'un track
+// Il diminue à chaque duel joué et remonte lentement pendant les périodes d'inactivité
+// L'Elo lui-même reste calculé par les règles Elo habituelles (facteur K)
+const (
+	InitialDeviation = 350.0 // Track jamais joué
+	MinDeviation     = 30.0  // Plancher : un Elo n'est jamais tout à fait figé
+
+	// Variance regagnée par jour sans duel : un track au plancher redevient inconnu en trois ans
+	// (un mois d'absence fait passer un RD de 50 à 76 environ)
+	deviationDriftPerDay = (InitialDeviation*InitialDeviation - MinDeviation*MinDeviation) / (3 * 365)
+
+	// Nombre d'écarts types retirés de l'Elo pour la borne basse du classement prudent
+	conservativeDeviations = 2
+)
+
+// glickoQ convertit l'échelle Elo en échelle logistique naturelle (ln(10)/400)
+const glickoQ = math.Ln10 / 400
+
+// glickoG atténue l'information apportée par un adversaire dont l'Elo est lui-même incertain
+func glickoG(deviation float64) float64 {
+	return 1 / math.Sqrt(1+3*glickoQ*glickoQ*deviation*deviation/(math.Pi*math.Pi))
+}
+
+// InflateDeviation fait remonter un écart type après une période sans duel, sans dépasser
+// l'écart type initial (idle négatif ou nul : inchangé)
+func InflateDeviation(deviation float64, idle time.Duration) float64 {
+	if idle <= 0 {
+		return deviation
+	}
+	days := idle.Hours() / 24
+	return math.Min(math.Sqrt(deviation*deviation+deviationDriftPerDay*days), InitialDeviation)
+}
+
+// CurrentDeviation retourne l'écart type d'un rating à la date now, inactivité comprise
+func CurrentDeviation(rating models.Rating, now time.Time) float64 {
+	if rating.LastSeenAt.IsZero() {
+		return rating.RD
+	}
+	return InflateDeviation(rating.RD, now.Sub(rating.LastSeenAt))
+}
+
+// ConservativeElo retourne la borne basse de l'Elo (Elo moins deux écarts types) : un track
+// peu joué n'y passe devant un track confirmé que s'il le devance nettement
+func ConservativeElo(rating models.Rating, now time.Time) int {
+	return rating.Elo - int(math.Round(conservativeDeviations*CurrentDeviation(rating, now)))
+}
+
+// updateDeviation réduit l'écart type après un duel contre un adversaire (formule de Glicko) ;
+// weight réduit l'information apportée par un vote peu fiable (1 = vote pleinement écouté)
+func updateDeviation(deviation float64, elo, opponentElo int, opponentDeviation, weight float64) float64 {
+	g := glickoG(opponentDeviation)
+	expected := 1 / (1 + math.Pow(10, -g*float64(elo-opponentElo)/400))
+	information := weight * glickoQ * glickoQ * g * g * expected * (1 - expected)
+	updated := 1 / math.Sqrt(1/(deviation*deviation)+information)
+	return math.Max(updated, MinDeviation)
+}
+
+// clampDeviation ramène un écart type entre le plancher et l'écart type initial
+func clampDeviation(deviation float64) float64 {
+	return math.Max(MinDeviation, math.Min(deviation, InitialDeviation))
+}
@@ -1,9 +1,15 @@
 package elo
 
 import (
+	"errors"
+	"fmt"
 	"math"
+	"songbattle/internal/config"
+	"songbattle/internal/logging"
 	"songbattle/internal/models"
 	"songbattle/internal/store"
+	"sort"
+	"sync"
 	"time"
 )
 
@@ -14,18 +20,48 @@ const (
 	MidK       = 24 // Pour les tracks avec quelques duels
 	MinK       = 16 // Pour les tracks expérimentés
 
+	// Bornes d'un Elo valide : au-delà, les données sont considérées corrompues
+	MinElo = 0
+	MaxElo = 4000
+
 	// Seuils pour ajuster K
 	NewPlayerThreshold         = 10 // Moins de 10 duels = nouveau
 	ExperiencedPlayerThreshold = 30 // Plus de 30 duels = expérimenté
+
+	// Poids d'un vote émis sans avoir écouté les extraits (pondération par l'écoute)
+	MinConfidence = 0.5
 )
 
+// ErrEloOutOfRange signale un rating hors de [MinElo, MaxElo] (import défectueux, base corrompue)
+var ErrEloOutOfRange = errors.New("elo hors limites")
+
+// ErrInvalidScore signale un score de vote partagé hors de [0, 1]
+var ErrInvalidScore = errors.New("score de vote hors de [0, 1]")
+
+// ErrNoUndoHistory signale un duel joué avant l'historique des Elo : sans les variations
+// enregistrées, l'annuler ne rendrait ni l'Elo ni le bilan des deux tracks
+var ErrNoUndoHistory = errors.New("duel sans historique des Elo, impossible à annuler")
+
+// ErrInvalidResult signale un résultat de duel inconnu (ni left, right, draw ni skip)
+var ErrInvalidResult = errors.New("résultat de duel invalide")
+
+// ErrInvalidConfidence signale une confiance de vote hors de [0, 1]
+var ErrInvalidConfidence = errors.New("confiance de vote hors de [0, 1]")
+
 type EloSystem struct {
-	db *store.DB
+	db     *store.DB
+	config config.EloConfig
+	mu     sync.Mutex // Sérialise les duels traités dans ce processus
 }
 
 // NewEloSystem crée une nouvelle instance du système Elo
 func NewEloSystem(db *store.DB) *EloSystem {
-	return &EloSystem{db: db}
+	return NewEloSystemWithConfig(db, config.Default().Elo)
+}
+
+// NewEloSystemWithConfig crée une nouvelle instance du système Elo avec une configuration
+func NewEloSystemWithConfig(db *store.DB, cfg config.EloConfig) *EloSystem {
+	return &EloSystem{db: db, config: cfg}
 }
 
 // CalculateExpectedScore calcule le score attendu pour le joueur A contre B
@@ -44,101 +80,704 @@ func GetKFactor(totalBattles int) int {
 	return MinK
 }
 
+// ListenConfidence retourne la confiance d'un vote d'après la durée d'écoute qui l'a précédé :
+// MinConfidence sans écoute, puis proportionnellement jusqu'à 1 pour une écoute d'au moins full
+func ListenConfidence(listened, full time.Duration) float64 {
+	if full <= 0 || listened >= full {
+		return 1
+	}
+	if listened <= 0 {
+		return MinConfidence
+	}
+	return MinConfidence + (1-MinConfidence)*float64(listened)/float64(full)
+}
+
+// weightedK réduit le facteur K selon la confiance du vote (nil = confiance pleine)
+func weightedK(k int, confidence *float64) int {
+	if confidence == nil {
+		return k
+	}
+	return int(math.Round(float64(k) * *confidence))
+}
+
 // CalculateNewElo calcule le nouveau Elo après un duel
-// Elo_new = Elo_old + K * (Score - Expected)
+// Elo_new = Elo_old + K * (Score - Expected), borné à [MinElo, MaxElo]
 func CalculateNewElo(oldElo int, actualScore float64, expectedScore float64, kFactor int) int {
 	newElo := float64(oldElo) + float64(kFactor)*(actualScore-expectedScore)
-	return int(math.Round(newElo))
+	if math.IsNaN(newElo) {
+		return clampElo(oldElo)
+	}
+	return int(math.Round(math.Max(MinElo, math.Min(MaxElo, newElo))))
 }
 
-// ProcessDuel traite le résultat d'un duel et met à jour les Elos
-func (es *EloSystem) ProcessDuel(leftTrackID, rightTrackID int64, result string) error {
-	// Récupérer les ratings actuels
-	leftRating, err := es.db.GetRating(leftTrackID)
+// clampElo ramène un Elo dans [MinElo, MaxElo]
+func clampElo(elo int) int {
+	if elo < MinElo {
+		return MinElo
+	}
+	if elo > MaxElo {
+		return MaxElo
+	}
+	return elo
+}
+
+// ValidElo indique si un Elo est dans les bornes acceptées
+func ValidElo(elo int) bool {
+	return elo >= MinElo && elo <= MaxElo
+}
+
+// repairElo ramène dans [MinElo, MaxElo] et sauvegarde l'Elo hors limites d'un rating
+// (import défectueux, base corrompue) : le problème est tracé et le duel peut être joué
+func repairElo(tx *store.Tx, ratings ...*models.Rating) error {
+	for _, rating := range ratings {
+		if ValidElo(rating.Elo) {
+			continue
+		}
+		logging.Printf("[elo] track %d: Elo %d out of range, clamped to %d", rating.TrackID, rating.Elo, clampElo(rating.Elo))
+		rating.Elo = clampElo(rating.Elo)
+		if err := tx.UpdateRating(rating); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ProcessDuel traite le résultat d'un duel, met à jour les Elos
+// et retourne les changements appliqués (gauche puis droite)
+// La lecture des ratings, le calcul et l'écriture sont atomiques : deux duels
+// concurrents sur les mêmes tracks ne peuvent pas perdre de mise à jour
+func (es *EloSystem) ProcessDuel(leftTrackID, rightTrackID int64, result string) ([]EloChange, error) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	var changes []EloChange
+	err := es.db.WithTx(func(tx *store.Tx) error {
+		var err error
+		changes, err = es.processDuelTx(tx, leftTrackID, rightTrackID, result, nil)
+		return err
+	})
 	if err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// ProcessDuelWithConfidence traite un duel comme ProcessDuel, avec un facteur K réduit selon
+// la confiance du vote, entre 0 et 1 (ex: ListenConfidence pour un vote après une écoute courte)
+// Une confiance de 1 équivaut à ProcessDuel ; sinon elle est conservée avec le duel (-recompute)
+func (es *EloSystem) ProcessDuelWithConfidence(leftTrackID, rightTrackID int64, result string, confidence float64) ([]EloChange, error) {
+	if math.IsNaN(confidence) || confidence < 0 || confidence > 1 {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfidence, confidence)
+	}
+	if confidence == 1 {
+		return es.ProcessDuel(leftTrackID, rightTrackID, result)
+	}
+
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	var changes []EloChange
+	err := es.db.WithTx(func(tx *store.Tx) error {
+		var err error
+		changes, err = es.processDuelTx(tx, leftTrackID, rightTrackID, result, &confidence)
 		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	rightRating, err := es.db.GetRating(rightTrackID)
+	return changes, nil
+}
+
+// processDuelTx applique un duel dans une transaction
+// confidence réduit le facteur K d'un duel joué (nil = confiance pleine)
+func (es *EloSystem) processDuelTx(tx *store.Tx, leftTrackID, rightTrackID int64, result string, confidence *float64) ([]EloChange, error) {
+	// Récupérer les ratings actuels
+	leftRating, err := tx.GetRating(leftTrackID)
+	if err != nil {
+		return nil, err
+	}
+
+	rightRating, err := tx.GetRating(rightTrackID)
 	if err != nil {
+		return nil, err
+	}
+
+	// Ne pas calculer à partir de données corrompues : l'Elo fautif est ramené dans les bornes
+	if err := repairElo(tx, leftRating, rightRating); err != nil {
+		return nil, err
+	}
+
+	switch result {
+	case models.WinnerLeft, models.WinnerRight, models.WinnerDraw:
+		// Duel joué : Elos mis à jour par playDuel ci-dessous
+	case models.WinnerSkip:
+		// Pas de changement d'Elo pour un skip
+		if es.config.SkipUpdatesLastSeen {
+			if err := touchLastSeen(tx, leftRating, rightRating); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := recordDuel(tx, leftTrackID, rightTrackID, nil, nil, nil, models.WinnerSkip); err != nil {
+			return nil, err
+		}
+		return []EloChange{
+			{TrackID: leftTrackID, OldElo: leftRating.Elo, NewElo: leftRating.Elo, Change: 0, Result: result},
+			{TrackID: rightTrackID, OldElo: rightRating.Elo, NewElo: rightRating.Elo, Change: 0, Result: result},
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidResult, result)
+	}
+
+	leftScore, _ := resultScore(result)
+	return playDuel(tx, leftRating, rightRating, leftScore, nil, confidence)
+}
+
+// ProcessWeightedDuel traite un vote partagé (écoute en groupe) : leftScore est la part
+// des voix du track de gauche, entre 0 et 1 (ex: 3 voix contre 2 = 0.6). L'Elo est mis à
+// jour avec ce score réel au lieu de 1/0.5/0 ; la majorité compte comme une victoire dans
+// le bilan, une égalité comme un nul. Le score est conservé avec le duel (undo, -recompute)
+func (es *EloSystem) ProcessWeightedDuel(leftTrackID, rightTrackID int64, leftScore float64) ([]EloChange, error) {
+	if math.IsNaN(leftScore) || leftScore < 0 || leftScore > 1 {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidScore, leftScore)
+	}
+
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	var changes []EloChange
+	err := es.db.WithTx(func(tx *store.Tx) error {
+		leftRating, err := tx.GetRating(leftTrackID)
+		if err != nil {
+			return err
+		}
+		rightRating, err := tx.GetRating(rightTrackID)
+		if err != nil {
+			return err
+		}
+
+		if err := repairElo(tx, leftRating, rightRating); err != nil {
+			return err
+		}
+
+		changes, err = playDuel(tx, leftRating, rightRating, leftScore, &leftScore, nil)
 		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Déterminer les scores
-	var leftScore, rightScore float64
+	return changes, nil
+}
+
+// ScoreResult retourne le résultat correspondant au score du track de gauche :
+// la majorité l'emporte, un partage à égalité est un nul
+func ScoreResult(leftScore float64) string {
+	switch {
+	case leftScore > 0.5:
+		return models.WinnerLeft
+	case leftScore < 0.5:
+		return models.WinnerRight
+	}
+	return models.WinnerDraw
+}
+
+// resultScore retourne le score du track de gauche pour un résultat joué (ok = false pour un skip)
+func resultScore(result string) (float64, bool) {
 	switch result {
 	case models.WinnerLeft:
-		leftScore, rightScore = 1.0, 0.0
+		return 1.0, true
 	case models.WinnerRight:
-		leftScore, rightScore = 0.0, 1.0
+		return 0.0, true
 	case models.WinnerDraw:
-		leftScore, rightScore = 0.5, 0.5
-	case models.WinnerSkip:
-		// Pas de changement d'Elo pour un skip
-		return es.recordDuelWithoutEloChange(leftTrackID, rightTrackID, nil)
-	default:
-		return nil // Résultat invalide
+		return 0.5, true
 	}
+	return 0, false
+}
+
+// playDuel applique le score d'un duel joué, l'enregistre et historise les nouveaux Elos
+// storedScore est enregistré avec le duel pour un vote partagé (nil sinon), tout comme
+// confidence pour un vote pondéré par l'écoute
+func playDuel(tx *store.Tx, leftRating, rightRating *models.Rating, leftScore float64, storedScore, confidence *float64) ([]EloChange, error) {
+	leftTrackID, rightTrackID := leftRating.TrackID, rightRating.TrackID
+	result := ScoreResult(leftScore)
+
+	oldLeftElo, oldLeftRD, oldLeftSeen := leftRating.Elo, leftRating.RD, leftRating.LastSeenAt
+	oldRightElo, oldRightRD, oldRightSeen := rightRating.Elo, rightRating.RD, rightRating.LastSeenAt
+
+	if err := applyScore(tx, leftRating, rightRating, leftScore, confidence, time.Now()); err != nil {
+		return nil, err
+	}
+	newLeftElo := leftRating.Elo
+	newRightElo := rightRating.Elo
+
+	// Enregistrer le duel
+	var winnerID *int64
+	if result == models.WinnerLeft {
+		winnerID = &leftTrackID
+	} else if result == models.WinnerRight {
+		winnerID = &rightTrackID
+	}
+
+	duel, err := recordDuel(tx, leftTrackID, rightTrackID, winnerID, storedScore, confidence, result)
+	if err != nil {
+		return nil, err
+	}
+
+	// Historiser les nouveaux Elos
+	if err := tx.AddEloHistory(leftTrackID, duel.ID, oldLeftElo, newLeftElo, oldLeftRD, leftRating.RD, oldLeftSeen, duel.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := tx.AddEloHistory(rightTrackID, duel.ID, oldRightElo, newRightElo, oldRightRD, rightRating.RD, oldRightSeen, duel.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	return []EloChange{
+		{
+			TrackID: leftTrackID,
+			OldElo:  oldLeftElo,
+			NewElo:  newLeftElo,
+			Change:  newLeftElo - oldLeftElo,
+			Result:  result,
+		},
+		{
+			TrackID: rightTrackID,
+			OldElo:  oldRightElo,
+			NewElo:  newRightElo,
+			Change:  newRightElo - oldRightElo,
+			Result:  result,
+		},
+	}, nil
+}
+
+// applyResult met à jour et sauvegarde les Elos et compteurs des deux tracks d'un duel joué
+// (victoire ou nul) ; at devient la date de dernière apparition des deux tracks
+func applyResult(tx *store.Tx, leftRating, rightRating *models.Rating, result string, confidence *float64, at time.Time) error {
+	leftScore, ok := resultScore(result)
+	if !ok {
+		return fmt.Errorf("résultat sans effet sur l'Elo: %q", result)
+	}
+	return applyScore(tx, leftRating, rightRating, leftScore, confidence, at)
+}
+
+// applyScore met à jour et sauvegarde les Elos et compteurs des deux tracks d'après le score
+// réel du track de gauche (1 victoire, 0.5 nul, 0 défaite, ou la part d'un vote partagé)
+// et la confiance du vote (nil = confiance pleine)
+func applyScore(tx *store.Tx, leftRating, rightRating *models.Rating, leftScore float64, confidence *float64, at time.Time) error {
+	rightScore := 1 - leftScore
 
 	// Calculer les scores attendus
 	leftExpected := CalculateExpectedScore(leftRating.Elo, rightRating.Elo)
 	rightExpected := CalculateExpectedScore(rightRating.Elo, leftRating.Elo)
 
 	// Calculer les facteurs K
-	leftK := GetKFactor(leftRating.GetTotalBattles())
-	rightK := GetKFactor(rightRating.GetTotalBattles())
+	leftK := weightedK(GetKFactor(leftRating.GetTotalBattles()), confidence)
+	rightK := weightedK(GetKFactor(rightRating.GetTotalBattles()), confidence)
+
+	// Écarts types : remontée due à l'inactivité, puis réduction par l'information du duel
+	// (calculés avant la mise à jour des Elos, qui n'en dépend pas)
+	weight := 1.0
+	if confidence != nil {
+		weight = *confidence
+	}
+	leftDeviation := CurrentDeviation(*leftRating, at)
+	rightDeviation := CurrentDeviation(*rightRating, at)
+	leftRating.RD = updateDeviation(leftDeviation, leftRating.Elo, rightRating.Elo, rightDeviation, weight)
+	rightRating.RD = updateDeviation(rightDeviation, rightRating.Elo, leftRating.Elo, leftDeviation, weight)
 
 	// Calculer les nouveaux Elos
-	newLeftElo := CalculateNewElo(leftRating.Elo, leftScore, leftExpected, leftK)
-	newRightElo := CalculateNewElo(rightRating.Elo, rightScore, rightExpected, rightK)
+	leftRating.Elo = CalculateNewElo(leftRating.Elo, leftScore, leftExpected, leftK)
+	rightRating.Elo = CalculateNewElo(rightRating.Elo, rightScore, rightExpected, rightK)
+	leftRating.LastSeenAt = at
+	rightRating.LastSeenAt = at
 
-	// Mettre à jour les statistiques
-	leftRating.Elo = newLeftElo
-	rightRating.Elo = newRightElo
-	leftRating.LastSeenAt = time.Now()
-	rightRating.LastSeenAt = time.Now()
-
-	// Mettre à jour les compteurs de victoires/défaites
-	if result == models.WinnerLeft {
+	// Mettre à jour les compteurs de victoires/défaites (la majorité l'emporte)
+	switch ScoreResult(leftScore) {
+	case models.WinnerLeft:
 		leftRating.Wins++
 		rightRating.Losses++
-	} else if result == models.WinnerRight {
+	case models.WinnerRight:
 		leftRating.Losses++
 		rightRating.Wins++
-	} else if result == models.WinnerDraw {
+	default:
 		leftRating.Draws++
 		rightRating.Draws++
 	}
 
 	// Sauvegarder en base
-	if err := es.db.UpdateRating(leftRating); err != nil {
-		return err
-	}
-	if err := es.db.UpdateRating(rightRating); err != nil {
+	if err := tx.UpdateRating(leftRating); err != nil {
 		return err
 	}
+	return tx.UpdateRating(rightRating)
+}
 
-	// Enregistrer le duel
-	var winnerID *int64
-	if result == models.WinnerLeft {
-		winnerID = &leftTrackID
-	} else if result == models.WinnerRight {
-		winnerID = &rightTrackID
+// touchLastSeen marque les tracks comme vus maintenant, sans toucher à leur Elo
+func touchLastSeen(tx *store.Tx, ratings ...*models.Rating) error {
+	now := time.Now()
+	for _, rating := range ratings {
+		rating.LastSeenAt = now
+		if err := tx.UpdateRating(rating); err != nil {
+			return err
+		}
 	}
-
-	return es.recordDuelWithoutEloChange(leftTrackID, rightTrackID, winnerID)
+	return nil
 }
 
-// recordDuelWithoutEloChange enregistre juste le duel sans changer les Elos
-func (es *EloSystem) recordDuelWithoutEloChange(leftTrackID, rightTrackID int64, winnerID *int64) error {
+// recordDuel enregistre le duel et son résultat sans changer les Elos, et compte l'apparition
+// des deux tracks (un skip compte aussi comme tel)
+func recordDuel(tx *store.Tx, leftTrackID, rightTrackID int64, winnerID *int64, leftScore, confidence *float64, result string) (*models.Duel, error) {
 	duel := &models.Duel{
 		LeftTrackID:   leftTrackID,
 		RightTrackID:  rightTrackID,
 		WinnerTrackID: winnerID,
 		CreatedAt:     time.Now(),
+		LeftScore:     leftScore,
+		Confidence:    confidence,
+		Result:        result,
+	}
+
+	if err := tx.CreateDuel(duel); err != nil {
+		return nil, err
+	}
+	if err := tx.CountAppearance(leftTrackID, rightTrackID, result == models.WinnerSkip, 1); err != nil {
+		return nil, err
+	}
+	return duel, nil
+}
+
+// UndoLastDuel annule le dernier duel, quel que soit son résultat : après une victoire ou
+// un nul, les variations d'Elo et d'écart type enregistrées sont retirées aux deux tracks,
+// leurs compteurs et leur date de dernière apparition reviennent à leur état précédent ;
+// un skip n'a rien à restaurer (avec elo.skip_updates_last_seen, la date de dernière
+// apparition qu'il a avancée n'est pas rétablie). Dans tous les cas le duel est supprimé
+// de l'historique. Un duel joué ou nul sans historique des Elo (base ancienne) n'est pas
+// annulé : ErrNoUndoHistory, sans rien modifier
+// Retourne le duel annulé (nil s'il n'y en a aucun) et les changements appliqués
+func (es *EloSystem) UndoLastDuel() (*models.LoggedDuel, []EloChange, error) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	var duel *models.LoggedDuel
+	var changes []EloChange
+	err := es.db.WithTx(func(tx *store.Tx) error {
+		var err error
+		duel, err = tx.GetLastDuel()
+		if err != nil || duel == nil {
+			return err
+		}
+
+		// Le duel ne compte plus dans les apparitions (ni les skips) des deux tracks
+		if err := tx.CountAppearance(duel.LeftTrackID, duel.RightTrackID, duel.Result == models.WinnerSkip, -1); err != nil {
+			return err
+		}
+
+		if duel.Result == models.WinnerSkip {
+			return tx.DeleteDuel(duel.ID)
+		}
+
+		history, err := tx.GetEloHistoryForDuel(duel.ID)
+		if err != nil {
+			return err
+		}
+		if len(history) == 0 {
+			return ErrNoUndoHistory
+		}
+
+		for _, entry := range history {
+			rating, err := tx.GetRating(entry.TrackID)
+			if err != nil {
+				return err
+			}
+
+			// Retirer exactement ce que le duel a apporté, plutôt que restaurer l'ancien Elo :
+			// un décalage appliqué depuis (-renormalize) est conservé
+			oldElo := rating.Elo
+			rating.Elo -= entry.NewElo - entry.OldElo
+			if entry.OldRD != nil && entry.NewRD != nil {
+				rating.RD = clampDeviation(rating.RD + *entry.OldRD - *entry.NewRD)
+			}
+			if entry.OldLastSeenAt != nil {
+				rating.LastSeenAt = *entry.OldLastSeenAt
+			}
+			switch {
+			case duel.Result == models.WinnerDraw:
+				rating.Draws--
+			case *duel.WinnerTrackID == entry.TrackID:
+				rating.Wins--
+			default:
+				rating.Losses--
+			}
+
+			if err := tx.UpdateRating(rating); err != nil {
+				return err
+			}
+
+			changes = append(changes, EloChange{
+				TrackID: entry.TrackID,
+				OldElo:  oldElo,
+				NewElo:  rating.Elo,
+				Change:  rating.Elo - oldElo,
+			})
+		}
+
+		return tx.DeleteDuel(duel.ID)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return duel, changes, nil
+}
+
+// Recompute recalcule tous les ratings à partir du journal des duels : chaque track repart
+// de son Elo de départ (celui d'avant son premier duel, notation rapide comprise, ou
+// InitialElo pour les duels joués avant l'historique des Elo), sans victoire ni défaite,
+// puis les duels sont rejoués dans l'ordre chronologique avec les règles Elo actuelles.
+// L'historique des Elo est reconstruit au passage
+// Retourne le nombre de duels rejoués et les tracks dont l'Elo a changé, par amplitude décroissante
+func (es *EloSystem) Recompute() (int, []EloChange, error) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	before, err := es.db.GetAllTracksWithRatings()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	replayed := 0
+	err = es.db.WithTx(func(tx *store.Tx) error {
+		// Lire le journal (et distinguer nuls et skips) avant d'effacer l'historique
+		duels, err := tx.GetDuelLog()
+		if err != nil {
+			return err
+		}
+		startingElos, err := tx.GetStartingElos(InitialElo)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.ResetRatings(startingElos); err != nil {
+			return err
+		}
+		if err := tx.ClearEloHistory(); err != nil {
+			return err
+		}
+
+		for _, duel := range duels {
+			if duel.Result == models.WinnerSkip {
+				continue // Aucun effet sur l'Elo
+			}
+
+			leftRating, err := tx.GetRating(duel.LeftTrackID)
+			if err != nil {
+				return err
+			}
+			rightRating, err := tx.GetRating(duel.RightTrackID)
+			if err != nil {
+				return err
+			}
+
+			// Les votes partagés sont rejoués avec leur score réel, les votes pondérés avec leur confiance
+			oldLeftElo, oldRightElo := leftRating.Elo, rightRating.Elo
+			oldLeftRD, oldRightRD := leftRating.RD, rightRating.RD
+			oldLeftSeen, oldRightSeen := leftRating.LastSeenAt, rightRating.LastSeenAt
+			if duel.LeftScore != nil {
+				err = applyScore(tx, leftRating, rightRating, *duel.LeftScore, duel.Confidence, duel.CreatedAt)
+			} else {
+				err = applyResult(tx, leftRating, rightRating, duel.Result, duel.Confidence, duel.CreatedAt)
+			}
+			if err != nil {
+				return err
+			}
+			if err := tx.AddEloHistory(duel.LeftTrackID, duel.ID, oldLeftElo, leftRating.Elo, oldLeftRD, leftRating.RD, oldLeftSeen, duel.CreatedAt); err != nil {
+				return err
+			}
+			if err := tx.AddEloHistory(duel.RightTrackID, duel.ID, oldRightElo, rightRating.Elo, oldRightRD, rightRating.RD, oldRightSeen, duel.CreatedAt); err != nil {
+				return err
+			}
+			replayed++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	after, err := es.db.GetAllTracksWithRatings()
+	if err != nil {
+		return replayed, nil, err
+	}
+
+	oldElos := make(map[int64]int, len(before))
+	for _, track := range before {
+		oldElos[track.Track.ID] = track.Rating.Elo
+	}
+
+	changes := make([]EloChange, 0)
+	for _, track := range after {
+		old, ok := oldElos[track.Track.ID]
+		if !ok || old == track.Rating.Elo {
+			continue
+		}
+		changes = append(changes, EloChange{
+			TrackID: track.Track.ID,
+			OldElo:  old,
+			NewElo:  track.Rating.Elo,
+			Change:  track.Rating.Elo - old,
+		})
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return abs(changes[i].Change) > abs(changes[j].Change)
+	})
+
+	return replayed, changes, nil
+}
+
+// BlendRatings combine les ratings de deux versions d'un même morceau en celui de keep :
+// l'Elo est la moyenne des deux pondérée par leurs nombres de duels (celui de keep si aucun
+// n'a joué), les compteurs s'additionnent, l'écart type est celui du mieux connu des deux et
+// la dernière apparition est la plus récente
+func BlendRatings(keep, drop models.Rating) models.Rating {
+	blended := keep
+	keepBattles, dropBattles := keep.GetTotalBattles(), drop.GetTotalBattles()
+	if total := keepBattles + dropBattles; total > 0 {
+		blended.Elo = int(math.Round(float64(keep.Elo*keepBattles+drop.Elo*dropBattles) / float64(total)))
+	}
+
+	blended.Wins += drop.Wins
+	blended.Losses += drop.Losses
+	blended.Draws += drop.Draws
+	blended.Appearances += drop.Appearances
+	blended.Skips += drop.Skips
+	blended.RD = math.Min(keep.RD, drop.RD)
+	if drop.LastSeenAt.After(blended.LastSeenAt) {
+		blended.LastSeenAt = drop.LastSeenAt
+	}
+	return blended
+}
+
+// MergeTracks fond le track dropID dans keepID (versions clean et explicite d'un même morceau) :
+// le rating de keepID devient le mélange des deux (BlendRatings), sans les duels qui les
+// opposaient, supprimés ; les autres duels de dropID passent à keepID, puis dropID est supprimé
+// Retourne le rating fusionné
+func (es *EloSystem) MergeTracks(keepID, dropID int64) (*models.Rating, error) {
+	if keepID == dropID {
+		return nil, fmt.Errorf("impossible de fusionner le track %d avec lui-même", keepID)
+	}
+
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	var merged models.Rating
+	err := es.db.WithTx(func(tx *store.Tx) error {
+		keep, err := tx.GetRating(keepID)
+		if err != nil {
+			return fmt.Errorf("rating du track %d: %w", keepID, err)
+		}
+		drop, err := tx.GetRating(dropID)
+		if err != nil {
+			return fmt.Errorf("rating du track %d: %w", dropID, err)
+		}
+
+		// Un duel entre les deux versions compte des deux côtés : le retirer des compteurs
+		decided, draws, skips, err := tx.CountDuelsBetween(keepID, dropID)
+		if err != nil {
+			return err
+		}
+		merged = BlendRatings(*keep, *drop)
+		merged.Wins = max(merged.Wins-decided, 0)
+		merged.Losses = max(merged.Losses-decided, 0)
+		merged.Draws = max(merged.Draws-2*draws, 0)
+		merged.Appearances = max(merged.Appearances-2*(decided+draws+skips), 0)
+		merged.Skips = max(merged.Skips-2*skips, 0)
+
+		if err := tx.MergeTrack(dropID, keepID); err != nil {
+			return err
+		}
+		return tx.UpdateRating(&merged)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return es.db.CreateDuel(duel)
+	if err := es.db.RetargetTrackReferences(dropID, keepID); err != nil {
+		return &merged, fmt.Errorf("report de l'épinglage, des rivalités et du marquage: %w", err)
+	}
+	return &merged, nil
+}
+
+// Renormalize décale tous les Elo du même écart pour ramener l'Elo moyen à InitialElo :
+// l'ordre et les écarts entre tracks sont conservés, seuls les nombres absolus changent
+// Refusé (ErrEloOutOfRange) si le décalage sortait un track de [MinElo, MaxElo]
+// Retourne le décalage appliqué (0 si la moyenne est déjà à InitialElo)
+func (es *EloSystem) Renormalize() (int, error) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	delta := 0
+	err := es.db.WithTx(func(tx *store.Tx) error {
+		mean, minElo, maxElo, count, err := tx.GetEloRange()
+		if err != nil || count == 0 {
+			return err
+		}
+
+		delta = int(math.Round(InitialElo - mean))
+		if delta == 0 {
+			return nil
+		}
+		if !ValidElo(minElo+delta) || !ValidElo(maxElo+delta) {
+			return fmt.Errorf("%w: décalage de %+d pour des Elo entre %d et %d", ErrEloOutOfRange, delta, minElo, maxElo)
+		}
+		return tx.ShiftElos(delta)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return delta, nil
+}
+
+// RankingDiff compare l'Elo actuel de chaque track à son Elo avant since
+// Les changements sont triés par amplitude décroissante ; les tracks inchangés sont omis
+func (es *EloSystem) RankingDiff(since time.Time) ([]EloChange, error) {
+	baselines, err := es.db.GetEloBaselines(since)
+	if err != nil {
+		return nil, err
+	}
+
+	tracks, err := es.db.GetAllTracksWithRatings()
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]EloChange, 0)
+	for _, track := range tracks {
+		baseline, ok := baselines[track.Track.ID]
+		if !ok || baseline == track.Rating.Elo {
+			continue
+		}
+		changes = append(changes, EloChange{
+			TrackID: track.Track.ID,
+			OldElo:  baseline,
+			NewElo:  track.Rating.Elo,
+			Change:  track.Rating.Elo - baseline,
+		})
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return abs(changes[i].Change) > abs(changes[j].Change)
+	})
+
+	return changes, nil
+}
+
+// abs retourne la valeur absolue d'un entier
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
 }
 
 // GetEloRanking retourne les tracks classés par Elo
@@ -224,13 +863,19 @@ func (es *EloSystem) GetEloStats() (map[string]interface{}, error) {
 		return nil, err
 	}
 
+	return ComputeEloStats(tracks), nil
+}
+
+// ComputeEloStats calcule les statistiques globales à partir de tracks déjà chargés
+func ComputeEloStats(tracks []models.TrackWithRating) map[string]interface{} {
 	if len(tracks) == 0 {
 		return map[string]interface{}{
 			"total_tracks": 0,
 			"average_elo":  0,
 			"min_elo":      0,
 			"max_elo":      0,
-		}, nil
+			"tiers":        TierThresholds{},
+		}
 	}
 
 	var totalElo, minElo, maxElo int
@@ -253,5 +898,35 @@ func (es *EloSystem) GetEloStats() (map[string]interface{}, error) {
 		"average_elo":  totalElo / len(tracks),
 		"min_elo":      minElo,
 		"max_elo":      maxElo,
-	}, nil
+		"tiers":        ComputeTierThresholds(tracks),
+	}
+}
+
+// GetEloHistogram répartit les tracks par tranches d'Elo de bucketSize points
+// La clé est la borne basse de la tranche (ex: 1200 pour 1200-1249)
+func (es *EloSystem) GetEloHistogram(bucketSize int) (map[int]int, error) {
+	tracks, err := es.db.GetAllTracksWithRatings()
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildEloHistogram(tracks, bucketSize), nil
+}
+
+// BuildEloHistogram répartit des tracks déjà chargés par tranches d'Elo
+func BuildEloHistogram(tracks []models.TrackWithRating, bucketSize int) map[int]int {
+	if bucketSize <= 0 {
+		bucketSize = 50
+	}
+
+	histogram := make(map[int]int)
+	for _, track := range tracks {
+		bucket := track.Rating.Elo / bucketSize * bucketSize
+		if track.Rating.Elo < 0 && track.Rating.Elo%bucketSize != 0 {
+			bucket -= bucketSize // Arrondi vers le bas pour les Elo négatifs
+		}
+		histogram[bucket]++
+	}
+
+	return histogram
 }
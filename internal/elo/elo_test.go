@@ -0,0 +1,552 @@
+package elo
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"songbattle/internal/config"
+	"songbattle/internal/models"
+	"songbattle/internal/store"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestSystem ouvre une base temporaire contenant un track par Elo de départ
+func newTestSystem(t *testing.T, elos ...int) (*EloSystem, *store.DB, []int64) {
+	t.Helper()
+	return newTestSystemAt(t, filepath.Join(t.TempDir(), "test.db"), elos...)
+}
+
+// newTestSystemAt crée la base de test dans path
+func newTestSystemAt(t *testing.T, path string, elos ...int) (*EloSystem, *store.DB, []int64) {
+	t.Helper()
+
+	db, err := store.NewDB(path)
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	ids := make([]int64, 0, len(elos))
+	for i, elo := range elos {
+		track := &models.Track{
+			SpotifyID:  fmt.Sprintf("track%d", i),
+			Name:       fmt.Sprintf("Track %d", i),
+			Artist:     "Artist",
+			Album:      "Album",
+			SpotifyURI: fmt.Sprintf("spotify:track:track%d", i),
+			Popularity: -1,
+		}
+		if err := db.CreateTrack(track); err != nil {
+			t.Fatalf("CreateTrack: %v", err)
+		}
+		if _, err := db.Exec(`UPDATE ratings SET elo = ? WHERE track_id = ?`, elo, track.ID); err != nil {
+			t.Fatalf("set elo: %v", err)
+		}
+		ids = append(ids, track.ID)
+	}
+
+	return NewEloSystem(db), db, ids
+}
+
+// elosByTrack retourne l'Elo actuel de chaque track
+func elosByTrack(t *testing.T, db *store.DB) map[int64]int {
+	t.Helper()
+
+	tracks, err := db.GetAllTracksWithRatings()
+	if err != nil {
+		t.Fatalf("GetAllTracksWithRatings: %v", err)
+	}
+	elos := make(map[int64]int, len(tracks))
+	for _, track := range tracks {
+		elos[track.Track.ID] = track.Rating.Elo
+	}
+	return elos
+}
+
+func TestRenormalizeKeepsOrderAndHistory(t *testing.T) {
+	es, db, ids := newTestSystem(t, 1500, 1450, 1400, 1350)
+
+	for _, pair := range [][2]int{{0, 1}, {2, 3}, {1, 2}, {3, 0}, {0, 2}} {
+		if _, err := es.ProcessDuel(ids[pair[0]], ids[pair[1]], models.WinnerLeft); err != nil {
+			t.Fatalf("ProcessDuel: %v", err)
+		}
+	}
+
+	before := elosByTrack(t, db)
+	delta, err := es.Renormalize()
+	if err != nil {
+		t.Fatalf("Renormalize: %v", err)
+	}
+	if delta >= 0 {
+		t.Fatalf("delta = %d, want a negative shift for a library above %d", delta, InitialElo)
+	}
+
+	after := elosByTrack(t, db)
+	sum := 0
+	for id, elo := range after {
+		if elo != before[id]+delta {
+			t.Errorf("track %d: Elo %d, want %d%+d", id, elo, before[id], delta)
+		}
+		sum += elo
+	}
+	if mean := float64(sum) / float64(len(after)); mean < InitialElo-0.5 || mean > InitialElo+0.5 {
+		t.Errorf("mean Elo = %.2f, want %d", mean, InitialElo)
+	}
+
+	// Le décalage n'est pas un changement de classement
+	changes, err := es.RankingDiff(time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("RankingDiff: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("RankingDiff after renormalization = %+v, want no change", changes)
+	}
+
+	// Le replay des duels ne défait pas le décalage
+	if _, _, err := es.Recompute(); err != nil {
+		t.Fatalf("Recompute: %v", err)
+	}
+	for id, elo := range elosByTrack(t, db) {
+		if elo != after[id] {
+			t.Errorf("track %d after Recompute: Elo %d, want %d", id, elo, after[id])
+		}
+	}
+}
+
+func TestRenormalizeAtBaseline(t *testing.T) {
+	es, _, _ := newTestSystem(t, 1250, 1150)
+
+	delta, err := es.Renormalize()
+	if err != nil {
+		t.Fatalf("Renormalize: %v", err)
+	}
+	if delta != 0 {
+		t.Errorf("delta = %d, want 0", delta)
+	}
+}
+
+func TestUndoLastDuelRestoresRating(t *testing.T) {
+	es, db, ids := newTestSystem(t, 1300, 1200)
+
+	lastSeen := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := db.Exec(`UPDATE ratings SET last_seen_at = ?`, lastSeen); err != nil {
+		t.Fatalf("set last_seen_at: %v", err)
+	}
+	before := make(map[int64]*models.Rating)
+	for _, id := range ids {
+		rating, err := db.GetRating(id)
+		if err != nil {
+			t.Fatalf("GetRating: %v", err)
+		}
+		before[id] = rating
+	}
+
+	if _, err := es.ProcessDuel(ids[1], ids[0], models.WinnerLeft); err != nil {
+		t.Fatalf("ProcessDuel: %v", err)
+	}
+	duel, _, err := es.UndoLastDuel()
+	if err != nil {
+		t.Fatalf("UndoLastDuel: %v", err)
+	}
+	if duel == nil {
+		t.Fatal("UndoLastDuel found no duel")
+	}
+
+	for _, id := range ids {
+		rating, err := db.GetRating(id)
+		if err != nil {
+			t.Fatalf("GetRating: %v", err)
+		}
+		want := before[id]
+		if rating.Elo != want.Elo || rating.Wins != want.Wins || rating.Losses != want.Losses || rating.RD != want.RD {
+			t.Errorf("track %d after undo: %+v, want %+v", id, rating, want)
+		}
+		if !rating.LastSeenAt.Equal(lastSeen) {
+			t.Errorf("track %d after undo: last seen %v, want %v", id, rating.LastSeenAt, lastSeen)
+		}
+	}
+}
+
+func TestUndoLastDuelWithoutHistory(t *testing.T) {
+	es, db, ids := newTestSystem(t, 1216, 1184)
+
+	// Duel joué avant l'historique des Elo : ratings à jour, aucune variation enregistrée
+	if _, err := db.Exec(`UPDATE ratings SET wins = 1, appearances = 1 WHERE track_id = ?`, ids[0]); err != nil {
+		t.Fatalf("set winner: %v", err)
+	}
+	if _, err := db.Exec(`UPDATE ratings SET losses = 1, appearances = 1 WHERE track_id = ?`, ids[1]); err != nil {
+		t.Fatalf("set loser: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO duels (left_track_id, right_track_id, winner_track_id, result) VALUES (?, ?, ?, ?)`,
+		ids[0], ids[1], ids[0], models.WinnerLeft); err != nil {
+		t.Fatalf("insert duel: %v", err)
+	}
+	before := make(map[int64]*models.Rating)
+	for _, id := range ids {
+		rating, err := db.GetRating(id)
+		if err != nil {
+			t.Fatalf("GetRating: %v", err)
+		}
+		before[id] = rating
+	}
+
+	duel, changes, err := es.UndoLastDuel()
+	if !errors.Is(err, ErrNoUndoHistory) {
+		t.Fatalf("UndoLastDuel error = %v, want ErrNoUndoHistory", err)
+	}
+	if duel != nil || changes != nil {
+		t.Errorf("UndoLastDuel = %+v, %+v, want nothing undone", duel, changes)
+	}
+
+	// Ni le duel ni les ratings ne sont touchés
+	if count, _ := db.GetDuelCount(); count != 1 {
+		t.Errorf("%d duels left, want 1", count)
+	}
+	for _, id := range ids {
+		rating, err := db.GetRating(id)
+		if err != nil {
+			t.Fatalf("GetRating: %v", err)
+		}
+		if want := before[id]; *rating != *want {
+			t.Errorf("track %d after refused undo: %+v, want %+v", id, rating, want)
+		}
+	}
+}
+
+func TestProcessDuelConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	es, db, ids := newTestSystemAt(t, path, 1200, 1200, 1200, 1200)
+
+	// Une seconde connexion, comme un autre processus sur la même base : seules
+	// les transactions SQLite sérialisent alors les duels
+	other, err := store.NewDB(path)
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	defer other.Close()
+	systems := []*EloSystem{es, NewEloSystem(other)}
+
+	// Paires qui se chevauchent : chaque track joue contre les trois autres
+	var pairs [][2]int64
+	for i := range ids {
+		for j := i + 1; j < len(ids); j++ {
+			pairs = append(pairs, [2]int64{ids[i], ids[j]})
+		}
+	}
+
+	const duels = 48
+	var wg sync.WaitGroup
+	errs := make(chan error, duels)
+	for i := 0; i < duels; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			pair := pairs[i%len(pairs)]
+			if _, err := systems[i%len(systems)].ProcessDuel(pair[0], pair[1], models.WinnerLeft); err != nil {
+				errs <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("ProcessDuel: %v", err)
+	}
+
+	tracks, err := db.GetAllTracksWithRatings()
+	if err != nil {
+		t.Fatalf("GetAllTracksWithRatings: %v", err)
+	}
+	wins, losses := 0, 0
+	for _, track := range tracks {
+		wins += track.Rating.Wins
+		losses += track.Rating.Losses
+	}
+	if wins != duels || losses != duels {
+		t.Errorf("wins = %d, losses = %d, want %d each", wins, losses, duels)
+	}
+
+	count, err := db.GetDuelCount()
+	if err != nil {
+		t.Fatalf("GetDuelCount: %v", err)
+	}
+	if count != duels {
+		t.Errorf("recorded %d duels, want %d", count, duels)
+	}
+}
+
+func TestProcessDuelInvalidResult(t *testing.T) {
+	es, db, ids := newTestSystem(t, 1200, 1200)
+
+	changes, err := es.ProcessDuel(ids[0], ids[1], "left-ish")
+	if !errors.Is(err, ErrInvalidResult) {
+		t.Fatalf("ProcessDuel error = %v, want ErrInvalidResult", err)
+	}
+	if changes != nil {
+		t.Errorf("changes = %+v, want none", changes)
+	}
+	if count, _ := db.GetDuelCount(); count != 0 {
+		t.Errorf("recorded %d duels, want 0", count)
+	}
+}
+
+func TestValidElo(t *testing.T) {
+	tests := []struct {
+		elo  int
+		want bool
+	}{
+		{-1, false},
+		{-500, false},
+		{MinElo, true},
+		{InitialElo, true},
+		{MaxElo, true},
+		{MaxElo + 1, false},
+		{1 << 30, false},
+	}
+	for _, tt := range tests {
+		if got := ValidElo(tt.elo); got != tt.want {
+			t.Errorf("ValidElo(%d) = %v, want %v", tt.elo, got, tt.want)
+		}
+	}
+}
+
+func TestCalculateNewEloClamps(t *testing.T) {
+	tests := []struct {
+		name     string
+		oldElo   int
+		score    float64
+		expected float64
+		k        int
+		want     int
+	}{
+		{"regular win", 1200, 1, 0.5, 32, 1216},
+		{"loss below the floor", 10, 0, 1, 32, MinElo},
+		{"win above the ceiling", MaxElo - 5, 1, 0, 32, MaxElo},
+		{"negative input", -300, 1, 0.5, 32, MinElo},
+		{"huge input", 1 << 20, 0, 0.5, 32, MaxElo},
+		{"NaN expected score", 1500, 1, math.NaN(), 32, 1500},
+		{"NaN with corrupt input", -50, 1, math.NaN(), 32, MinElo},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CalculateNewElo(tt.oldElo, tt.score, tt.expected, tt.k); got != tt.want {
+				t.Errorf("CalculateNewElo(%d, %v, %v, %d) = %d, want %d", tt.oldElo, tt.score, tt.expected, tt.k, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessDuelRepairsCorruptElo(t *testing.T) {
+	es, db, ids := newTestSystem(t, -400, 9000, 1200)
+
+	// Un vote sur un track corrompu n'est pas bloqué : l'Elo est d'abord ramené dans les bornes
+	changes, err := es.ProcessDuel(ids[0], ids[1], models.WinnerLeft)
+	if err != nil {
+		t.Fatalf("ProcessDuel: %v", err)
+	}
+	if changes[0].OldElo != MinElo || changes[1].OldElo != MaxElo {
+		t.Errorf("duel played from %d and %d, want %d and %d", changes[0].OldElo, changes[1].OldElo, MinElo, MaxElo)
+	}
+	for id, elo := range elosByTrack(t, db) {
+		if !ValidElo(elo) {
+			t.Errorf("track %d still has an out-of-range Elo: %d", id, elo)
+		}
+	}
+
+	// Un skip répare aussi la valeur stockée
+	if _, err := db.Exec(`UPDATE ratings SET elo = -1 WHERE track_id = ?`, ids[2]); err != nil {
+		t.Fatalf("corrupt rating: %v", err)
+	}
+	if _, err := es.ProcessDuel(ids[2], ids[0], models.WinnerSkip); err != nil {
+		t.Fatalf("ProcessDuel skip: %v", err)
+	}
+	if elo := elosByTrack(t, db)[ids[2]]; elo != MinElo {
+		t.Errorf("skipped track Elo = %d, want %d", elo, MinElo)
+	}
+}
+
+func TestSkipLastSeen(t *testing.T) {
+	lastSeen := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, skipUpdates := range []bool{false, true} {
+		t.Run(fmt.Sprintf("SkipUpdatesLastSeen=%v", skipUpdates), func(t *testing.T) {
+			_, db, ids := newTestSystem(t, 1200, 1200, 1200, 1200)
+			cfg := config.Default().Elo
+			cfg.SkipUpdatesLastSeen = skipUpdates
+			es := NewEloSystemWithConfig(db, cfg)
+
+			if _, err := db.Exec(`UPDATE ratings SET last_seen_at = ?`, lastSeen); err != nil {
+				t.Fatalf("set last_seen_at: %v", err)
+			}
+
+			if _, err := es.ProcessDuel(ids[0], ids[1], models.WinnerSkip); err != nil {
+				t.Fatalf("ProcessDuel skip: %v", err)
+			}
+			if _, err := es.ProcessDuel(ids[2], ids[3], models.WinnerLeft); err != nil {
+				t.Fatalf("ProcessDuel: %v", err)
+			}
+
+			for i, id := range ids {
+				rating, err := db.GetRating(id)
+				if err != nil {
+					t.Fatalf("GetRating: %v", err)
+				}
+				// Un duel joué compte toujours comme une apparition, un skip seulement si l'option est active
+				wantTouched := i >= 2 || skipUpdates
+				if touched := rating.LastSeenAt.After(lastSeen); touched != wantTouched {
+					t.Errorf("track %d (skipped: %v): last seen %v, updated = %v, want %v",
+						id, i < 2, rating.LastSeenAt, touched, wantTouched)
+				}
+			}
+		})
+	}
+}
+
+// legacySchema est le schéma d'une base créée avant l'historique des Elo et les colonnes ajoutées depuis
+var legacySchema = []string{
+	`CREATE TABLE tracks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		spotify_id TEXT UNIQUE NOT NULL,
+		name TEXT NOT NULL,
+		artist TEXT NOT NULL,
+		album TEXT NOT NULL,
+		year INTEGER DEFAULT 0,
+		genres_json TEXT DEFAULT '[]',
+		spotify_uri TEXT NOT NULL,
+		preview_url TEXT,
+		audio_features_json TEXT DEFAULT '{}',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`,
+	`CREATE TABLE ratings (
+		track_id INTEGER PRIMARY KEY,
+		elo INTEGER DEFAULT 1200,
+		wins INTEGER DEFAULT 0,
+		losses INTEGER DEFAULT 0,
+		draws INTEGER DEFAULT 0,
+		last_seen_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`,
+	`CREATE TABLE duels (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		left_track_id INTEGER NOT NULL,
+		right_track_id INTEGER NOT NULL,
+		winner_track_id INTEGER,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`,
+	`CREATE TABLE meta (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	)`,
+}
+
+func TestRecomputeLegacyDatabase(t *testing.T) {
+	// Duels joués avant l'historique des Elo : une victoire, un nul et un skip,
+	// qui ne laissaient tous trois qu'un duel sans trace de l'Elo
+	type legacyDuel struct {
+		left, right int
+		result      string
+	}
+	duels := []legacyDuel{
+		{0, 1, models.WinnerLeft},
+		{0, 2, models.WinnerDraw},
+		{1, 2, models.WinnerSkip},
+	}
+
+	// Ratings attendus : les mêmes duels joués sur une base actuelle
+	fresh, freshDB, freshIDs := newTestSystem(t, 1200, 1200, 1200, 1350)
+	for _, d := range duels {
+		if _, err := fresh.ProcessDuel(freshIDs[d.left], freshIDs[d.right], d.result); err != nil {
+			t.Fatalf("ProcessDuel: %v", err)
+		}
+	}
+	want := make([]*models.Rating, len(freshIDs))
+	for i, id := range freshIDs {
+		rating, err := freshDB.GetRating(id)
+		if err != nil {
+			t.Fatalf("GetRating: %v", err)
+		}
+		want[i] = rating
+	}
+
+	// Base ancienne : ratings à jour, duels sans historique ni résultat
+	path := filepath.Join(t.TempDir(), "legacy.db")
+	legacy, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open legacy database: %v", err)
+	}
+	for _, statement := range legacySchema {
+		if _, err := legacy.Exec(statement); err != nil {
+			t.Fatalf("legacy schema: %v", err)
+		}
+	}
+	for i, rating := range want {
+		if _, err := legacy.Exec(`INSERT INTO tracks (spotify_id, name, artist, album, spotify_uri) VALUES (?, ?, 'Artist', 'Album', ?)`,
+			fmt.Sprintf("track%d", i), fmt.Sprintf("Track %d", i), fmt.Sprintf("spotify:track:track%d", i)); err != nil {
+			t.Fatalf("legacy track: %v", err)
+		}
+		if _, err := legacy.Exec(`INSERT INTO ratings (track_id, elo, wins, losses, draws) VALUES (?, ?, ?, ?, ?)`,
+			i+1, rating.Elo, rating.Wins, rating.Losses, rating.Draws); err != nil {
+			t.Fatalf("legacy rating: %v", err)
+		}
+	}
+	playedAt := time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC)
+	for i, d := range duels {
+		var winner interface{}
+		switch d.result {
+		case models.WinnerLeft:
+			winner = d.left + 1
+		case models.WinnerRight:
+			winner = d.right + 1
+		}
+		if _, err := legacy.Exec(`INSERT INTO duels (left_track_id, right_track_id, winner_track_id, created_at) VALUES (?, ?, ?, ?)`,
+			d.left+1, d.right+1, winner, playedAt.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatalf("legacy duel: %v", err)
+		}
+	}
+	legacy.Close()
+
+	db, err := store.NewDB(path)
+	if err != nil {
+		t.Fatalf("NewDB on legacy database: %v", err)
+	}
+	defer db.Close()
+
+	// La migration retrouve le nul grâce aux compteurs draws
+	h2h, err := db.GetHeadToHead(1, 3)
+	if err != nil {
+		t.Fatalf("GetHeadToHead: %v", err)
+	}
+	if h2h.Draws != 1 || h2h.Skips != 0 {
+		t.Errorf("legacy draw migrated as %+v, want one draw", h2h)
+	}
+	h2h, err = db.GetHeadToHead(2, 3)
+	if err != nil {
+		t.Fatalf("GetHeadToHead: %v", err)
+	}
+	if h2h.Skips != 1 || h2h.Draws != 0 {
+		t.Errorf("legacy skip migrated as %+v, want one skip", h2h)
+	}
+
+	replayed, _, err := NewEloSystem(db).Recompute()
+	if err != nil {
+		t.Fatalf("Recompute: %v", err)
+	}
+	if replayed != 2 {
+		t.Errorf("replayed %d duels, want 2 (the skip has no effect)", replayed)
+	}
+
+	// Chaque duel compte une seule fois ; le track sans duel garde son Elo
+	for i, w := range want {
+		rating, err := db.GetRating(int64(i + 1))
+		if err != nil {
+			t.Fatalf("GetRating: %v", err)
+		}
+		if rating.Elo != w.Elo || rating.Wins != w.Wins || rating.Losses != w.Losses || rating.Draws != w.Draws {
+			t.Errorf("track %d after Recompute: Elo %d %d-%d-%d, want Elo %d %d-%d-%d", i+1,
+				rating.Elo, rating.Wins, rating.Losses, rating.Draws, w.Elo, w.Wins, w.Losses, w.Draws)
+		}
+	}
+}
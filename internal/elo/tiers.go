@@ -0,0 +1,87 @@
+package elo
+
+import (
+	"songbattle/internal/models"
+	"sort"
+)
+
+// Tiers, du meilleur au moins bon
+const (
+	TierS = "S"
+	TierA = "A"
+	TierB = "B"
+	TierC = "C"
+	TierD = "D"
+)
+
+// Part cumulée de la bibliothèque couverte par chaque tier :
+// top 10% en S, puis 20% en A, 30% en B, 25% en C et les 15% restants en D
+const (
+	tierShareS = 0.10
+	tierShareA = 0.30
+	tierShareB = 0.60
+	tierShareC = 0.85
+)
+
+// MinTierTracks est le nombre de tracks en dessous duquel les tiers n'ont pas de sens
+const MinTierTracks = 5
+
+// TierThresholds contient l'Elo minimal des tiers S, A, B et C ; en dessous, le track est en D
+// Les seuils suivent la distribution de la bibliothèque, quel que soit l'écart absolu entre les Elo
+type TierThresholds struct {
+	S, A, B, C int
+	valid      bool
+}
+
+// ComputeTierThresholds calcule les seuils des tiers par percentiles
+// Sans assez de tracks ou si tous ont le même Elo, aucun tier n'est attribué
+func ComputeTierThresholds(tracks []models.TrackWithRating) TierThresholds {
+	if len(tracks) < MinTierTracks {
+		return TierThresholds{}
+	}
+
+	elos := make([]int, len(tracks))
+	for i, track := range tracks {
+		elos[i] = track.Rating.Elo
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(elos)))
+
+	if elos[0] == elos[len(elos)-1] {
+		return TierThresholds{}
+	}
+
+	// Elo du dernier track compris dans la part cumulée du tier
+	cutoff := func(share float64) int {
+		index := int(share*float64(len(elos))+0.5) - 1
+		if index < 0 {
+			index = 0
+		}
+		return elos[index]
+	}
+
+	return TierThresholds{
+		S:     cutoff(tierShareS),
+		A:     cutoff(tierShareA),
+		B:     cutoff(tierShareB),
+		C:     cutoff(tierShareC),
+		valid: true,
+	}
+}
+
+// Tier retourne le tier d'un Elo ("" si les seuils n'ont pas pu être calculés)
+// Les ex aequo au seuil sont classés dans le tier supérieur
+func (t TierThresholds) Tier(elo int) string {
+	switch {
+	case !t.valid:
+		return ""
+	case elo >= t.S:
+		return TierS
+	case elo >= t.A:
+		return TierA
+	case elo >= t.B:
+		return TierB
+	case elo >= t.C:
+		return TierC
+	}
+	return TierD
+}
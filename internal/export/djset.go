@@ -0,0 +1,174 @@
+package export
+
+import (
+	"fmt"
+	"math"
+	"songbattle/internal/models"
+	"time"
+)
+
+// Poids d'une transition entre deux clés incompatibles, par pas sur la roue Camelot
+// (exprimé en BPM pour être comparable à l'écart de tempo)
+const camelotStepPenalty = 8.0
+
+// camelotKey représente une position sur la roue Camelot (1A-12A, 1B-12B)
+type camelotKey struct {
+	Number int  // 1 à 12
+	Major  bool // B = majeur, A = mineur
+}
+
+// toCamelot convertit la clé Spotify (classe de hauteur 0-11, mode 1 = majeur)
+// en position Camelot. Retourne false si la clé est inconnue
+func toCamelot(key, mode int) (camelotKey, bool) {
+	if key < 0 || key > 11 {
+		return camelotKey{}, false
+	}
+
+	// Chaque quinte avance d'un cran sur la roue : C = 8B, A mineur = 8A
+	offset := 5
+	if mode == 1 {
+		offset = 8
+	}
+	number := (key*7 + offset) % 12
+	if number == 0 {
+		number = 12
+	}
+
+	return camelotKey{Number: number, Major: mode == 1}, true
+}
+
+// wheelDistance retourne le nombre de crans entre deux numéros Camelot
+func wheelDistance(a, b int) int {
+	d := a - b
+	if d < 0 {
+		d = -d
+	}
+	if d > 6 {
+		d = 12 - d
+	}
+	return d
+}
+
+// keyPenalty mesure la dureté harmonique d'une transition
+// Les clés compatibles (même numéro, ou voisin avec la même lettre) ne coûtent rien
+func keyPenalty(from, to camelotKey) float64 {
+	distance := wheelDistance(from.Number, to.Number)
+	if distance == 0 || (distance == 1 && from.Major == to.Major) {
+		return 0
+	}
+	if from.Major != to.Major {
+		distance++
+	}
+	return float64(distance) * camelotStepPenalty
+}
+
+// djTrack associe un track à ses caractéristiques utiles pour l'enchaînement
+type djTrack struct {
+	track models.TrackWithRating
+	key   camelotKey
+	tempo float64
+}
+
+// transitionCost évalue la fluidité du passage d'un track à l'autre
+func transitionCost(from, to djTrack) float64 {
+	return math.Abs(to.tempo-from.tempo) + keyPenalty(from.key, to.key)
+}
+
+// OrderForDJSet réordonne les tracks pour des transitions fluides : clés
+// compatibles sur la roue Camelot et variations de tempo progressives.
+// Le set démarre sur le tempo le plus lent puis choisit à chaque étape la
+// transition la moins coûteuse. Les tracks sans audio features sont placés
+// à la fin, dans leur ordre d'origine
+func OrderForDJSet(tracks []models.TrackWithRating) []models.TrackWithRating {
+	var remaining []djTrack
+	var missing []models.TrackWithRating
+
+	for _, track := range tracks {
+		features := track.Track.AudioFeaturesJSON
+		key, ok := toCamelot(features.Key, features.Mode)
+		if !ok || features.Tempo <= 0 {
+			missing = append(missing, track)
+			continue
+		}
+		remaining = append(remaining, djTrack{track: track, key: key, tempo: features.Tempo})
+	}
+
+	ordered := make([]models.TrackWithRating, 0, len(tracks))
+	if len(remaining) > 0 {
+		// Point de départ : le tempo le plus lent
+		current := 0
+		for i := range remaining {
+			if remaining[i].tempo < remaining[current].tempo {
+				current = i
+			}
+		}
+
+		for {
+			last := remaining[current]
+			ordered = append(ordered, last.track)
+			remaining = append(remaining[:current], remaining[current+1:]...)
+			if len(remaining) == 0 {
+				break
+			}
+
+			current = 0
+			bestCost := transitionCost(last, remaining[0])
+			for i := 1; i < len(remaining); i++ {
+				if cost := transitionCost(last, remaining[i]); cost < bestCost {
+					current = i
+					bestCost = cost
+				}
+			}
+		}
+	}
+
+	return append(ordered, missing...)
+}
+
+// ExportDJSet exporte les N meilleurs tracks, réordonnés pour un set DJ
+func (pe *PlaylistExporter) ExportDJSet(limit int) (*PlaylistInfo, error) {
+	topTracks, err := pe.topTracks(limit)
+	if err != nil {
+		return nil, fmt.Errorf("erreur récupération top tracks: %w", err)
+	}
+
+	if len(topTracks) == 0 {
+		return nil, fmt.Errorf("aucun track trouvé")
+	}
+
+	tracks := OrderForDJSet(topTracks)
+
+	user, err := pe.spotifyClient.GetCurrentUser()
+	if err != nil {
+		return nil, fmt.Errorf("erreur récupération utilisateur: %w", err)
+	}
+
+	playlistName := fmt.Sprintf("Song Battle DJ Set %d", len(tracks))
+	playlistDescription := fmt.Sprintf("Top %d Song Battle enchaîné par tempo et tonalité - Créée le %s",
+		len(tracks), time.Now().Format("02/01/2006"))
+
+	playlist, err := pe.spotifyClient.CreatePlaylist(
+		string(user.ID),
+		playlistName,
+		playlistDescription,
+		pe.config.Public,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("erreur création playlist: %w", err)
+	}
+
+	added, err := pe.addTracks(string(playlist.ID), tracks)
+	if err != nil {
+		return nil, fmt.Errorf("erreur ajout tracks playlist: %w", err)
+	}
+
+	return &PlaylistInfo{
+		ID:          string(playlist.ID),
+		Name:        playlist.Name,
+		Description: playlist.Description,
+		URL:         playlist.ExternalURLs["spotify"],
+		TrackCount:  len(added),
+		CreatedAt:   time.Now(),
+		Tracks:      added,
+	}, nil
+}
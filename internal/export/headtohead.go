@@ -0,0 +1,62 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"songbattle/internal/models"
+	"songbattle/internal/store"
+	"strconv"
+)
+
+// headToHeadHeader est l'en-tête du CSV des confrontations
+var headToHeadHeader = []string{
+	"track_a_spotify_id", "track_a_name", "track_a_artist",
+	"track_b_spotify_id", "track_b_name", "track_b_artist",
+	"a_wins", "b_wins", "draws",
+}
+
+// ExportHeadToHeadCSV écrit le bilan de chaque paire de tracks s'étant affrontée, en CSV
+// Le format long (une ligne par paire) reste compact sur les grandes bibliothèques,
+// là où une matrice dense aurait une cellule par paire possible ; retourne le nombre de paires
+func ExportHeadToHeadCSV(db *store.DB, w io.Writer) (int, error) {
+	records, err := db.GetPairRecords()
+	if err != nil {
+		return 0, err
+	}
+
+	tracks, err := db.GetAllTracksWithRatings()
+	if err != nil {
+		return 0, err
+	}
+	byID := make(map[int64]models.Track, len(tracks))
+	for _, track := range tracks {
+		byID[track.Track.ID] = track.Track
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(headToHeadHeader); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, record := range records {
+		a, okA := byID[record.TrackA]
+		b, okB := byID[record.TrackB]
+		if !okA || !okB {
+			continue // Track supprimé depuis le duel
+		}
+
+		row := []string{
+			a.SpotifyID, a.Name, a.Artist,
+			b.SpotifyID, b.Name, b.Artist,
+			strconv.Itoa(record.Wins), strconv.Itoa(record.Losses), strconv.Itoa(record.Draws),
+		}
+		if err := writer.Write(row); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	writer.Flush()
+	return count, writer.Error()
+}
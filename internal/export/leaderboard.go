@@ -0,0 +1,66 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"songbattle/internal/models"
+	"strconv"
+)
+
+// leaderboardHeader est l'en-tête du CSV du classement complet
+var leaderboardHeader = []string{
+	"rank", "name", "artist", "album", "year",
+	"elo", "wins", "losses", "draws", "battles", "spotify_id",
+}
+
+// LeaderboardEntry est une ligne du classement complet exporté en JSON
+// Le track et son rating gardent le format de models.TrackWithRating : le fichier se relit
+// tel quel en []models.TrackWithRating (rank et battles sont alors ignorés)
+type LeaderboardEntry struct {
+	Rank    int `json:"rank"`
+	Battles int `json:"battles"`
+	models.TrackWithRating
+}
+
+// ExportLeaderboardCSV écrit le classement (tracks triés par Elo décroissant) en CSV, une ligne par track
+func ExportLeaderboardCSV(w io.Writer, tracks []models.TrackWithRating) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(leaderboardHeader); err != nil {
+		return err
+	}
+
+	for i, track := range tracks {
+		year := ""
+		if track.Track.Year > 0 {
+			year = strconv.Itoa(track.Track.Year)
+		}
+		row := []string{
+			strconv.Itoa(i + 1), track.Track.Name, track.Track.Artist, track.Track.Album, year,
+			strconv.Itoa(track.Rating.Elo), strconv.Itoa(track.Rating.Wins), strconv.Itoa(track.Rating.Losses),
+			strconv.Itoa(track.Rating.Draws), strconv.Itoa(track.Rating.GetTotalBattles()), track.Track.SpotifyID,
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// ExportLeaderboardJSON écrit le classement (tracks triés par Elo décroissant) en JSON
+func ExportLeaderboardJSON(w io.Writer, tracks []models.TrackWithRating) error {
+	entries := make([]LeaderboardEntry, 0, len(tracks))
+	for i, track := range tracks {
+		entries = append(entries, LeaderboardEntry{
+			Rank:            i + 1,
+			Battles:         track.Rating.GetTotalBattles(),
+			TrackWithRating: track,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(entries)
+}
@@ -3,9 +3,11 @@ package export
 import (
 	"context"
 	"fmt"
+	"songbattle/internal/config"
 	"songbattle/internal/models"
 	"songbattle/internal/spotify"
 	"songbattle/internal/store"
+	"sort"
 	"time"
 )
 
@@ -13,21 +15,28 @@ type PlaylistExporter struct {
 	db            *store.DB
 	spotifyClient *spotify.Client
 	ctx           context.Context
+	config        config.ExportConfig
 }
 
 // NewPlaylistExporter crée une nouvelle instance d'exporteur de playlist
 func NewPlaylistExporter(db *store.DB, spotifyClient *spotify.Client, ctx context.Context) *PlaylistExporter {
+	return NewPlaylistExporterWithConfig(db, spotifyClient, ctx, config.Default().Export)
+}
+
+// NewPlaylistExporterWithConfig crée une nouvelle instance d'exporteur de playlist avec une configuration
+func NewPlaylistExporterWithConfig(db *store.DB, spotifyClient *spotify.Client, ctx context.Context, cfg config.ExportConfig) *PlaylistExporter {
 	return &PlaylistExporter{
 		db:            db,
 		spotifyClient: spotifyClient,
 		ctx:           ctx,
+		config:        cfg,
 	}
 }
 
 // ExportTopTracks exporte les N meilleurs tracks vers une playlist Spotify
 func (pe *PlaylistExporter) ExportTopTracks(limit int) (*PlaylistInfo, error) {
 	// Récupérer les top tracks
-	topTracks, err := pe.db.GetTopTracks(limit)
+	topTracks, err := pe.topTracks(limit)
 	if err != nil {
 		return nil, fmt.Errorf("erreur récupération top tracks: %w", err)
 	}
@@ -42,38 +51,81 @@ func (pe *PlaylistExporter) ExportTopTracks(limit int) (*PlaylistInfo, error) {
 		return nil, fmt.Errorf("erreur récupération utilisateur: %w", err)
 	}
 
-	// Créer la playlist
+	return pe.createTopPlaylist(string(user.ID), topTracks)
+}
+
+// ExportTiered crée en une fois plusieurs playlists du même classement, une par taille
+// (ex: top 25, top 50, top 100) ; sans taille, les limites small, medium et large de
+// GetRecommendedLimits. Les tailles dépassant le nombre de tracks exportables ne
+// créent qu'une playlist, avec tous les tracks
+func (pe *PlaylistExporter) ExportTiered(sizes []int) ([]*PlaylistInfo, error) {
+	if len(sizes) == 0 {
+		limits := GetRecommendedLimits()
+		sizes = []int{limits["small"], limits["medium"], limits["large"]}
+	}
+
+	sizes = append([]int(nil), sizes...)
+	sort.Ints(sizes)
+	for _, size := range sizes {
+		if err := ValidateExportParams(size); err != nil {
+			return nil, err
+		}
+	}
+
+	// Un seul classement pour toutes les playlists
+	topTracks, err := pe.topTracks(sizes[len(sizes)-1])
+	if err != nil {
+		return nil, fmt.Errorf("erreur récupération top tracks: %w", err)
+	}
+
+	if len(topTracks) == 0 {
+		return nil, fmt.Errorf("aucun track trouvé")
+	}
+
+	user, err := pe.spotifyClient.GetCurrentUser()
+	if err != nil {
+		return nil, fmt.Errorf("erreur récupération utilisateur: %w", err)
+	}
+
+	var infos []*PlaylistInfo
+	previous := 0
+	for _, size := range sizes {
+		count := min(size, len(topTracks))
+		if count == previous {
+			continue // Même contenu que la playlist précédente
+		}
+		previous = count
+
+		info, err := pe.createTopPlaylist(string(user.ID), topTracks[:count])
+		if err != nil {
+			return infos, err
+		}
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
+
+// createTopPlaylist crée la playlist "Song Battle Top N" des tracks donnés, dans l'ordre du classement
+func (pe *PlaylistExporter) createTopPlaylist(userID string, topTracks []models.TrackWithRating) (*PlaylistInfo, error) {
 	playlistName := fmt.Sprintf("Song Battle Top %d", len(topTracks))
 	playlistDescription := fmt.Sprintf("Top %d des meilleures chansons selon Song Battle - Créée le %s",
 		len(topTracks), time.Now().Format("02/01/2006"))
 
 	playlist, err := pe.spotifyClient.CreatePlaylist(
-		string(user.ID),
+		userID,
 		playlistName,
 		playlistDescription,
+		pe.config.Public,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("erreur création playlist: %w", err)
 	}
 
-	// Préparer les URIs des tracks
-	trackURIs := make([]string, 0, len(topTracks))
-	for _, track := range topTracks {
-		trackURIs = append(trackURIs, track.Track.SpotifyURI)
-	}
-
 	// Ajouter les tracks à la playlist (par batches de 100)
-	batchSize := 100
-	for i := 0; i < len(trackURIs); i += batchSize {
-		end := i + batchSize
-		if end > len(trackURIs) {
-			end = len(trackURIs)
-		}
-
-		batch := trackURIs[i:end]
-		if err := pe.spotifyClient.AddTracksToPlaylist(string(playlist.ID), batch); err != nil {
-			return nil, fmt.Errorf("erreur ajout tracks playlist: %w", err)
-		}
+	added, err := pe.addTracks(string(playlist.ID), topTracks)
+	if err != nil {
+		return nil, fmt.Errorf("erreur ajout tracks playlist: %w", err)
 	}
 
 	// Retourner les informations de la playlist créée
@@ -82,9 +134,9 @@ func (pe *PlaylistExporter) ExportTopTracks(limit int) (*PlaylistInfo, error) {
 		Name:        playlist.Name,
 		Description: playlist.Description,
 		URL:         playlist.ExternalURLs["spotify"],
-		TrackCount:  len(topTracks),
+		TrackCount:  len(added),
 		CreatedAt:   time.Now(),
-		Tracks:      topTracks,
+		Tracks:      added,
 	}, nil
 }
 
@@ -104,6 +156,8 @@ func (pe *PlaylistExporter) ExportCustomPlaylist(trackIDs []int64, name, descrip
 		tracks = append(tracks, *track)
 	}
 
+	tracks = pe.exportable(tracks)
+
 	if len(tracks) == 0 {
 		return nil, fmt.Errorf("aucun track valide trouvé")
 	}
@@ -127,19 +181,15 @@ func (pe *PlaylistExporter) ExportCustomPlaylist(trackIDs []int64, name, descrip
 		string(user.ID),
 		name,
 		description,
+		pe.config.Public,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("erreur création playlist: %w", err)
 	}
 
-	// Préparer les URIs des tracks
-	trackURIs := make([]string, 0, len(tracks))
-	for _, track := range tracks {
-		trackURIs = append(trackURIs, track.Track.SpotifyURI)
-	}
-
 	// Ajouter les tracks à la playlist
-	if err := pe.spotifyClient.AddTracksToPlaylist(string(playlist.ID), trackURIs); err != nil {
+	added, err := pe.addTracks(string(playlist.ID), tracks)
+	if err != nil {
 		return nil, fmt.Errorf("erreur ajout tracks playlist: %w", err)
 	}
 
@@ -148,12 +198,135 @@ func (pe *PlaylistExporter) ExportCustomPlaylist(trackIDs []int64, name, descrip
 		Name:        playlist.Name,
 		Description: playlist.Description,
 		URL:         playlist.ExternalURLs["spotify"],
-		TrackCount:  len(tracks),
+		TrackCount:  len(added),
 		CreatedAt:   time.Now(),
-		Tracks:      tracks,
+		Tracks:      added,
 	}, nil
 }
 
+// exportable écarte les tracks exclus des playlists par la configuration
+// (explicites si demandé, indisponibles sur Spotify sauf demande contraire)
+func (pe *PlaylistExporter) exportable(tracks []models.TrackWithRating) []models.TrackWithRating {
+	if pe.config.ExcludeExplicit {
+		tracks = models.WithoutExplicit(tracks)
+	}
+	if !pe.config.IncludeUnavailable {
+		tracks = models.WithoutUnavailable(tracks)
+	}
+	return tracks
+}
+
+// topTracks récupère les N meilleurs tracks exportables
+func (pe *PlaylistExporter) topTracks(limit int) ([]models.TrackWithRating, error) {
+	// Classement complet (LIMIT -1) puis filtrage, pour garder N tracks après exclusion
+	ranked, err := pe.db.GetTopTracks(-1)
+	if err != nil {
+		return nil, err
+	}
+
+	tracks := pe.exportable(ranked)
+	if len(tracks) > limit {
+		tracks = tracks[:limit]
+	}
+	return tracks, nil
+}
+
+// addTracks ajoute des tracks à une playlist par batches de 100 et retourne les tracks ajoutés
+// Si un batch échoue car un track est introuvable, les IDs relinkés par Spotify
+// sont retrouvés via l'ISRC puis le batch est réessayé ; les tracks toujours
+// introuvables sont alors signalés indisponibles et retirés du batch
+func (pe *PlaylistExporter) addTracks(playlistID string, tracks []models.TrackWithRating) ([]models.TrackWithRating, error) {
+	added := make([]models.TrackWithRating, 0, len(tracks))
+
+	batchSize := 100
+	for i := 0; i < len(tracks); i += batchSize {
+		end := i + batchSize
+		if end > len(tracks) {
+			end = len(tracks)
+		}
+
+		batch := tracks[i:end]
+		err := pe.spotifyClient.AddTracksToPlaylist(playlistID, trackURIs(batch))
+		if err != nil && spotify.IsNotFound(err) && pe.relinkTracks(batch) > 0 {
+			err = pe.spotifyClient.AddTracksToPlaylist(playlistID, trackURIs(batch))
+		}
+		if err != nil && spotify.IsNotFound(err) {
+			if available, marked := pe.markUnavailable(batch); marked > 0 {
+				batch = available
+				err = nil
+				if len(batch) > 0 {
+					err = pe.spotifyClient.AddTracksToPlaylist(playlistID, trackURIs(batch))
+				}
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		added = append(added, batch...)
+	}
+
+	return added, nil
+}
+
+// markUnavailable signale les tracks du batch retirés du catalogue Spotify
+// Retourne les tracks restants et le nombre de tracks signalés
+func (pe *PlaylistExporter) markUnavailable(tracks []models.TrackWithRating) ([]models.TrackWithRating, int) {
+	ids := make([]string, 0, len(tracks))
+	for _, track := range tracks {
+		ids = append(ids, track.Track.SpotifyID)
+	}
+
+	missing, err := pe.spotifyClient.MissingTrackIDs(ids)
+	if err != nil || len(missing) == 0 {
+		return tracks, 0
+	}
+
+	available := make([]models.TrackWithRating, 0, len(tracks))
+	marked := 0
+	for _, track := range tracks {
+		if !missing[track.Track.SpotifyID] {
+			available = append(available, track)
+			continue
+		}
+		if err := pe.db.MarkTrackUnavailable(track.Track.ID); err == nil {
+			marked++
+		}
+	}
+	return available, marked
+}
+
+// relinkTracks met à jour les tracks dont l'ID Spotify a changé et retourne leur nombre
+func (pe *PlaylistExporter) relinkTracks(tracks []models.TrackWithRating) int {
+	relinked := 0
+	for i := range tracks {
+		track := &tracks[i].Track
+
+		changed, err := pe.spotifyClient.RelinkTrack(track)
+		if err != nil || !changed {
+			continue
+		}
+
+		if err := pe.db.UpdateTrackSpotifyID(track.ID, track.SpotifyID, track.SpotifyURI); err != nil {
+			continue
+		}
+		if track.Unavailable {
+			_ = pe.db.MarkTrackAvailable(track.ID)
+		}
+		relinked++
+	}
+
+	return relinked
+}
+
+// trackURIs extrait les URIs Spotify d'une liste de tracks
+func trackURIs(tracks []models.TrackWithRating) []string {
+	uris := make([]string, 0, len(tracks))
+	for _, track := range tracks {
+		uris = append(uris, track.Track.SpotifyURI)
+	}
+	return uris
+}
+
 // ExportByEloRange exporte les tracks dans une plage d'Elo spécifique
 func (pe *PlaylistExporter) ExportByEloRange(minElo, maxElo int, name string) (*PlaylistInfo, error) {
 	// Récupérer tous les tracks et filtrer par Elo
@@ -189,6 +362,38 @@ func (pe *PlaylistExporter) ExportByEloRange(minElo, maxElo int, name string) (*
 	return pe.ExportCustomPlaylist(trackIDs, name, description)
 }
 
+// ExportDecisiveWinners exporte les tracks qui gagnent le plus souvent leurs duels :
+// taux de victoire (en %) d'au moins minWinRate sur au moins minBattles duels,
+// classés par Elo. minBattles écarte les tracks au taux flatteur sur trop peu de duels
+func (pe *PlaylistExporter) ExportDecisiveWinners(minWinRate float64, minBattles, limit int) (*PlaylistInfo, error) {
+	ranked, err := pe.db.GetTopTracks(-1)
+	if err != nil {
+		return nil, fmt.Errorf("erreur récupération tracks: %w", err)
+	}
+
+	ranked = pe.exportable(ranked)
+
+	trackIDs := make([]int64, 0, limit)
+	for _, track := range ranked {
+		if len(trackIDs) == limit {
+			break
+		}
+		if track.Rating.GetTotalBattles() >= minBattles && track.Rating.GetWinRate() >= minWinRate {
+			trackIDs = append(trackIDs, track.Track.ID)
+		}
+	}
+
+	if len(trackIDs) == 0 {
+		return nil, fmt.Errorf("aucun track avec au moins %.0f%% de victoires sur %d duels", minWinRate, minBattles)
+	}
+
+	name := fmt.Sprintf("Song Battle Winners %.0f%%", minWinRate)
+	description := fmt.Sprintf("Chansons gagnant au moins %.0f%% de leurs duels (%d duels minimum) - %d chansons - Créée le %s",
+		minWinRate, minBattles, len(trackIDs), time.Now().Format("02/01/2006"))
+
+	return pe.ExportCustomPlaylist(trackIDs, name, description)
+}
+
 // GetExportHistory récupère l'historique des exports (simulé pour l'instant)
 func (pe *PlaylistExporter) GetExportHistory() ([]PlaylistInfo, error) {
 	// Pour l'instant, on retourne une liste vide
@@ -224,6 +429,16 @@ func ValidateExportParams(limit int) error {
 	return nil
 }
 
+// ValidateCalibration refuse d'exporter un classement pas encore stabilisé
+// progress est le pourcentage de tracks calibrés (voir matchmaker.CalibrationProgress),
+// minProgress le seuil exigé (0 = pas de contrôle)
+func ValidateCalibration(progress, minProgress float64) error {
+	if minProgress <= 0 || progress >= minProgress {
+		return nil
+	}
+	return fmt.Errorf("classement pas encore stabilisé : %.0f%% des tracks calibrés (minimum %.0f%%)", progress, minProgress)
+}
+
 // GetRecommendedLimits retourne les limites recommandées pour l'export
 func GetRecommendedLimits() map[string]int {
 	return map[string]int{
@@ -0,0 +1,202 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"songbattle/internal/models"
+	"sort"
+	"time"
+)
+
+// RankingEntry est une ligne d'un classement partagé en JSON
+type RankingEntry struct {
+	Rank      int    `json:"rank"`
+	SpotifyID string `json:"spotify_id"`
+	Name      string `json:"name"`
+	Artist    string `json:"artist"`
+	Elo       int    `json:"elo"`
+	Wins      int    `json:"wins"`
+	Losses    int    `json:"losses"`
+}
+
+// RankingFile est le format JSON d'un classement exporté
+type RankingFile struct {
+	ExportedAt time.Time      `json:"exported_at"`
+	Tracks     []RankingEntry `json:"tracks"`
+}
+
+// BuildRanking convertit des tracks triés par Elo décroissant en classement partageable
+func BuildRanking(tracks []models.TrackWithRating) []RankingEntry {
+	entries := make([]RankingEntry, 0, len(tracks))
+	for i, track := range tracks {
+		entries = append(entries, RankingEntry{
+			Rank:      i + 1,
+			SpotifyID: track.Track.SpotifyID,
+			Name:      track.Track.Name,
+			Artist:    track.Track.Artist,
+			Elo:       track.Rating.Elo,
+			Wins:      track.Rating.Wins,
+			Losses:    track.Rating.Losses,
+		})
+	}
+	return entries
+}
+
+// ExportRankingJSON écrit le classement (tracks triés par Elo décroissant) en JSON
+func ExportRankingJSON(w io.Writer, tracks []models.TrackWithRating) error {
+	file := RankingFile{
+		ExportedAt: time.Now(),
+		Tracks:     BuildRanking(tracks),
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(file)
+}
+
+// ImportRankingJSON lit un classement exporté par ExportRankingJSON (-export-ranking) ou par
+// ExportLeaderboardJSON (-export-json, tableau de LeaderboardEntry)
+// Les entrées sans spotify_id et les doublons sont ignorés ; le résultat est trié par rang
+func ImportRankingJSON(r io.Reader) ([]RankingEntry, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("classement JSON invalide: %w", err)
+	}
+
+	var file RankingFile
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '[' {
+		var leaderboard []LeaderboardEntry
+		if err := json.Unmarshal(raw, &leaderboard); err != nil {
+			return nil, fmt.Errorf("classement JSON invalide: %w", err)
+		}
+		file.Tracks = rankingFromLeaderboard(leaderboard)
+	} else if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("classement JSON invalide: %w", err)
+	}
+
+	seen := make(map[string]bool, len(file.Tracks))
+	entries := make([]RankingEntry, 0, len(file.Tracks))
+	for _, entry := range file.Tracks {
+		if entry.SpotifyID == "" || seen[entry.SpotifyID] {
+			continue
+		}
+		seen[entry.SpotifyID] = true
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Rank < entries[j].Rank
+	})
+	return entries, nil
+}
+
+// rankingFromLeaderboard convertit les lignes d'un export -export-json en entrées de classement
+func rankingFromLeaderboard(leaderboard []LeaderboardEntry) []RankingEntry {
+	entries := make([]RankingEntry, 0, len(leaderboard))
+	for _, entry := range leaderboard {
+		entries = append(entries, RankingEntry{
+			Rank:      entry.Rank,
+			SpotifyID: entry.Track.SpotifyID,
+			Name:      entry.Track.Name,
+			Artist:    entry.Track.Artist,
+			Elo:       entry.Rating.Elo,
+			Wins:      entry.Rating.Wins,
+			Losses:    entry.Rating.Losses,
+		})
+	}
+	return entries
+}
+
+// RankDisagreement décrit un track classé différemment dans les deux classements
+type RankDisagreement struct {
+	SpotifyID string
+	Name      string
+	Artist    string
+	MyRank    int
+	TheirRank int
+	Diff      int // TheirRank - MyRank (positif = mieux classé chez moi)
+}
+
+// RankingComparison compare deux classements sur leurs tracks communs
+type RankingComparison struct {
+	Common        int
+	Spearman      float64 // Corrélation de rang (NaN si moins de 2 tracks communs)
+	Disagreements []RankDisagreement
+	OnlyMine      []RankingEntry
+	OnlyTheirs    []RankingEntry
+}
+
+// CompareRankings compare deux classements en les associant par spotify_id
+// La corrélation de Spearman est calculée sur les rangs relatifs des tracks communs ;
+// les écarts affichés utilisent les rangs d'origine
+func CompareRankings(mine, theirs []RankingEntry) RankingComparison {
+	theirIndex := make(map[string]int, len(theirs))
+	for i, entry := range theirs {
+		theirIndex[entry.SpotifyID] = i
+	}
+
+	var comparison RankingComparison
+	inMine := make(map[string]bool, len(mine))
+	var commonMine []RankingEntry
+	for _, entry := range mine {
+		inMine[entry.SpotifyID] = true
+		if _, ok := theirIndex[entry.SpotifyID]; ok {
+			commonMine = append(commonMine, entry)
+		} else {
+			comparison.OnlyMine = append(comparison.OnlyMine, entry)
+		}
+	}
+
+	// Rangs relatifs de l'autre classement, restreint aux tracks communs
+	theirRelative := make(map[string]int, len(commonMine))
+	relative := 0
+	for _, entry := range theirs {
+		if !inMine[entry.SpotifyID] {
+			comparison.OnlyTheirs = append(comparison.OnlyTheirs, entry)
+			continue
+		}
+		relative++
+		theirRelative[entry.SpotifyID] = relative
+	}
+
+	comparison.Common = len(commonMine)
+	var sumSquares float64
+	for i, entry := range commonMine {
+		d := float64(i + 1 - theirRelative[entry.SpotifyID])
+		sumSquares += d * d
+
+		their := theirs[theirIndex[entry.SpotifyID]]
+		comparison.Disagreements = append(comparison.Disagreements, RankDisagreement{
+			SpotifyID: entry.SpotifyID,
+			Name:      entry.Name,
+			Artist:    entry.Artist,
+			MyRank:    entry.Rank,
+			TheirRank: their.Rank,
+			Diff:      their.Rank - entry.Rank,
+		})
+	}
+
+	n := float64(comparison.Common)
+	comparison.Spearman = math.NaN()
+	if comparison.Common >= 2 {
+		comparison.Spearman = 1 - 6*sumSquares/(n*(n*n-1))
+	}
+
+	// Plus grands désaccords en premier
+	sort.SliceStable(comparison.Disagreements, func(i, j int) bool {
+		return absInt(comparison.Disagreements[i].Diff) > absInt(comparison.Disagreements[j].Diff)
+	})
+
+	return comparison
+}
+
+// absInt retourne la valeur absolue d'un entier
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
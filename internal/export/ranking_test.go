@@ -0,0 +1,102 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"songbattle/internal/models"
+	"strings"
+	"testing"
+)
+
+// rankingTracks retourne des tracks triés par Elo décroissant
+func rankingTracks() []models.TrackWithRating {
+	var tracks []models.TrackWithRating
+	for i, elo := range []int{1420, 1310, 1200} {
+		tracks = append(tracks, models.TrackWithRating{
+			Track: models.Track{
+				ID:        int64(i + 1),
+				SpotifyID: fmt.Sprintf("track%d", i),
+				Name:      fmt.Sprintf("Song %d", i),
+				Artist:    "Artist",
+			},
+			Rating: models.Rating{TrackID: int64(i + 1), Elo: elo, Wins: 3 - i, Losses: i},
+		})
+	}
+	return tracks
+}
+
+func TestImportRankingJSON(t *testing.T) {
+	tracks := rankingTracks()
+	want := BuildRanking(tracks)
+
+	// Les deux exports JSON se relisent en classement
+	formats := map[string]func(io.Writer, []models.TrackWithRating) error{
+		"export-ranking": ExportRankingJSON,
+		"export-json":    ExportLeaderboardJSON,
+	}
+	for name, write := range formats {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := write(&buf, tracks); err != nil {
+				t.Fatalf("export: %v", err)
+			}
+			got, err := ImportRankingJSON(&buf)
+			if err != nil {
+				t.Fatalf("ImportRankingJSON: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ImportRankingJSON = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestImportRankingJSONEntries(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []string // spotify_id dans l'ordre
+		wantErr bool
+	}{
+		{
+			name: "ranking file sorted by rank, duplicates and missing IDs dropped",
+			in: `{"tracks": [{"rank": 2, "spotify_id": "b"}, {"rank": 1, "spotify_id": "a"},
+				{"rank": 3, "spotify_id": "a"}, {"rank": 4}]}`,
+			want: []string{"a", "b"},
+		},
+		{
+			name: "leaderboard array sorted by rank, duplicates and missing IDs dropped",
+			in: `  [{"rank": 2, "track": {"spotify_id": "b"}}, {"rank": 1, "track": {"spotify_id": "a"}},
+				{"rank": 3, "track": {"spotify_id": "a"}}, {"rank": 4, "track": {}}]`,
+			want: []string{"a", "b"},
+		},
+		{name: "empty ranking file", in: `{"tracks": []}`, want: []string{}},
+		{name: "empty leaderboard", in: `[]`, want: []string{}},
+		{name: "not JSON", in: `rank,name`, wantErr: true},
+		{name: "array of something else", in: `[1, 2]`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := ImportRankingJSON(strings.NewReader(tt.in))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ImportRankingJSON = %+v, want an error", entries)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ImportRankingJSON: %v", err)
+			}
+			got := make([]string, 0, len(entries))
+			for _, entry := range entries {
+				got = append(got, entry.SpotifyID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ImportRankingJSON = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
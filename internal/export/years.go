@@ -0,0 +1,64 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"songbattle/internal/models"
+)
+
+// DecadeChampions regroupe les champions des années d'une décennie
+type DecadeChampions struct {
+	Decade   int                      // Première année de la décennie (ex: 1990)
+	Champion models.TrackWithRating   // Meilleur track de la décennie
+	Years    []models.TrackWithRating // Meilleur track de chaque année, par année croissante
+}
+
+// BuildYearReport regroupe par décennie les champions de chaque année (triés par année)
+// Le champion d'une décennie est le meilleur de ses champions annuels
+func BuildYearReport(perYear []models.TrackWithRating) []DecadeChampions {
+	var decades []DecadeChampions
+	for _, track := range perYear {
+		decade := track.Track.Year / 10 * 10
+		if len(decades) == 0 || decades[len(decades)-1].Decade != decade {
+			decades = append(decades, DecadeChampions{Decade: decade, Champion: track})
+		}
+
+		current := &decades[len(decades)-1]
+		current.Years = append(current.Years, track)
+		if ranksAbove(track, current.Champion) {
+			current.Champion = track
+		}
+	}
+	return decades
+}
+
+// ranksAbove indique si a est mieux classé que b (Elo, puis nombre de duels, comme le classement)
+func ranksAbove(a, b models.TrackWithRating) bool {
+	if a.Rating.Elo != b.Rating.Elo {
+		return a.Rating.Elo > b.Rating.Elo
+	}
+	return a.Rating.GetTotalBattles() > b.Rating.GetTotalBattles()
+}
+
+// WriteYearReport écrit les champions par décennie et par année en texte brut
+func WriteYearReport(w io.Writer, decades []DecadeChampions) error {
+	if _, err := fmt.Fprintln(w, "Champions by year"); err != nil {
+		return err
+	}
+
+	for _, decade := range decades {
+		champion := decade.Champion
+		if _, err := fmt.Fprintf(w, "\n%ds  %s - %s (%d, %d Elo)\n",
+			decade.Decade, champion.Track.Name, champion.Track.Artist, champion.Track.Year, champion.Rating.Elo); err != nil {
+			return err
+		}
+
+		for _, year := range decade.Years {
+			if _, err := fmt.Fprintf(w, "  %d  %4d  %s - %s\n",
+				year.Track.Year, year.Rating.Elo, year.Track.Name, year.Track.Artist); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+// Package logging centralise les traces de diagnostic (auth, base, Spotify)
+// Elles sont désactivées par défaut : écrire sur stdout corromprait l'affichage du TUI
+package logging
+
+import (
+	"io"
+	"log"
+	"os"
+)
+
+// logger est partagé par tous les packages ; il n'écrit nulle part tant qu'aucune sortie n'est définie
+var logger = log.New(io.Discard, "", log.LstdFlags|log.Lmicroseconds)
+
+// enabled évite de formater les messages quand les traces sont désactivées
+var enabled bool
+
+// SetOutput envoie les traces vers w (nil les désactive)
+func SetOutput(w io.Writer) {
+	if w == nil {
+		logger.SetOutput(io.Discard)
+		enabled = false
+		return
+	}
+	logger.SetOutput(w)
+	enabled = true
+}
+
+// OpenFile envoie les traces vers un fichier (ajout en fin de fichier)
+// Le fichier retourné doit être fermé par l'appelant
+func OpenFile(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	SetOutput(file)
+	return file, nil
+}
+
+// Enabled indique si les traces sont actives
+func Enabled() bool {
+	return enabled
+}
+
+// Printf écrit une trace si une sortie est définie
+func Printf(format string, args ...interface{}) {
+	if !enabled {
+		return
+	}
+	logger.Printf(format, args...)
+}
@@ -1,10 +1,15 @@
 package matchmaker
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"math/rand"
+	"songbattle/internal/config"
+	"songbattle/internal/elo"
 	"songbattle/internal/models"
 	"songbattle/internal/store"
+	"sync"
 	"time"
 )
 
@@ -13,38 +18,491 @@ const (
 	EloRange             = 100  // Différence d'Elo acceptable pour un match équilibré
 	ExplorationRate      = 0.15 // 15% des duels incluent un morceau peu joué
 	MinBattlesForBalance = 5    // Minimum de duels avant d'utiliser le matchmaking équilibré
+
+	// Mode incertitude : écart d'Elo maximal d'une paire
+	UncertaintyEloRange = 200
 )
 
+// ErrNotEnoughTracks indique que la bibliothèque ne permet pas encore de duel
+var ErrNotEnoughTracks = errors.New("besoin d'au moins 2 tracks pour un duel")
+
+// ErrEmptyGenre indique qu'un genre d'un duel inter-genres n'a aucun track éligible
+var ErrEmptyGenre = errors.New("aucun track de ce genre")
+
 type Matchmaker struct {
-	db   *store.DB
-	rand *rand.Rand
+	db     *store.DB
+	rand   *rand.Rand
+	config config.MatchmakingConfig
+
+	// Paires de duels annulés, évitées pendant quelques matchs
+	// (protégées par undoneMu : l'UI et les commandes Bubble Tea y accèdent en parallèle)
+	undoneMu   sync.Mutex
+	justUndone map[trackPair]int
+
+	// Apparitions de chaque track depuis le lancement (protégées par sessionMu)
+	// lastAppearance retient le numéro du dernier match de chaque track, pour le cooldown
+	sessionMu          sync.Mutex
+	sessionAppearances map[int64]int
+	lastAppearance     map[int64]int
+	matchCount         int
+
+	// Duels imposés, joués dans l'ordre avant le matchmaking (protégés par queueMu)
+	queueMu sync.Mutex
+	queue   [][2]int64
+
+	// Rivalités définies par l'utilisateur, chargées depuis la base au premier usage (protégées par rivalryMu)
+	rivalryMu sync.Mutex
+	rivalries map[trackPair]bool
+
+	// Humeur à laquelle les duels sont limités ("" = toute la bibliothèque, protégée par moodMu)
+	moodMu sync.Mutex
+	mood   string
+
+	// Genres opposés en mode inter-genres (vides = mode désactivé, protégés par crossGenreMu)
+	crossGenreMu sync.Mutex
+	crossGenres  [2]string
+}
+
+// trackPair identifie une paire de tracks indépendamment du côté
+type trackPair struct {
+	low, high int64
+}
+
+// newTrackPair crée une paire ordonnée
+func newTrackPair(a, b int64) trackPair {
+	if a > b {
+		a, b = b, a
+	}
+	return trackPair{low: a, high: b}
 }
 
+// UndoAvoidanceMatches est le nombre de matchs pendant lesquels une paire annulée est évitée
+const UndoAvoidanceMatches = 5
+
 // NewMatchmaker crée une nouvelle instance du matchmaker
 func NewMatchmaker(db *store.DB) *Matchmaker {
+	return NewMatchmakerWithConfig(db, config.Default().Matchmaking)
+}
+
+// NewMatchmakerWithConfig crée une nouvelle instance du matchmaker avec une configuration
+func NewMatchmakerWithConfig(db *store.DB, cfg config.MatchmakingConfig) *Matchmaker {
 	return &Matchmaker{
-		db:   db,
-		rand: rand.New(rand.NewSource(time.Now().UnixNano())),
+		db:     db,
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+		config: cfg,
+
+		justUndone:         make(map[trackPair]int),
+		sessionAppearances: make(map[int64]int),
+		lastAppearance:     make(map[int64]int),
+	}
+}
+
+// SessionAppearances retourne le nombre de duels proposés à chaque track depuis le lancement
+func (mm *Matchmaker) SessionAppearances() map[int64]int {
+	mm.sessionMu.Lock()
+	defer mm.sessionMu.Unlock()
+
+	counts := make(map[int64]int, len(mm.sessionAppearances))
+	for id, count := range mm.sessionAppearances {
+		counts[id] = count
+	}
+	return counts
+}
+
+// recordAppearance compte une apparition de la paire proposée
+func (mm *Matchmaker) recordAppearance(left, right *models.TrackWithRating) {
+	mm.sessionMu.Lock()
+	defer mm.sessionMu.Unlock()
+	mm.sessionAppearances[left.Track.ID]++
+	mm.sessionAppearances[right.Track.ID]++
+
+	mm.matchCount++
+	mm.lastAppearance[left.Track.ID] = mm.matchCount
+	mm.lastAppearance[right.Track.ID] = mm.matchCount
+}
+
+// coolingDown indique si un track est apparu dans l'un des AppearanceCooldown derniers matchs
+func (mm *Matchmaker) coolingDown(trackID int64) bool {
+	mm.sessionMu.Lock()
+	defer mm.sessionMu.Unlock()
+
+	last, seen := mm.lastAppearance[trackID]
+	return seen && mm.matchCount-last < mm.config.AppearanceCooldown
+}
+
+// withoutCoolingDown écarte les tracks en cooldown
+// Si trop peu de tracks restent pour un duel, tous les tracks sont conservés
+func (mm *Matchmaker) withoutCoolingDown(tracks []models.TrackWithRating) []models.TrackWithRating {
+	if mm.config.AppearanceCooldown <= 0 {
+		return tracks
+	}
+
+	rested := make([]models.TrackWithRating, 0, len(tracks))
+	for _, track := range tracks {
+		if !mm.coolingDown(track.Track.ID) {
+			rested = append(rested, track)
+		}
+	}
+
+	if len(rested) < 2 {
+		return tracks
+	}
+	return rested
+}
+
+// sessionCount retourne le nombre d'apparitions d'un track dans la session
+func (mm *Matchmaker) sessionCount(trackID int64) int {
+	mm.sessionMu.Lock()
+	defer mm.sessionMu.Unlock()
+	return mm.sessionAppearances[trackID]
+}
+
+// sessionPenalty convertit les apparitions d'un track en pénalité d'Elo
+func (mm *Matchmaker) sessionPenalty(trackID int64) int {
+	return mm.sessionCount(trackID) * mm.config.SessionPenalty
+}
+
+// pickFresh tire un track au hasard en favorisant ceux peu vus pendant la session
+// (poids inversement proportionnel aux apparitions). Tirage uniforme si désactivé
+func (mm *Matchmaker) pickFresh(tracks []models.TrackWithRating) *models.TrackWithRating {
+	if mm.config.SessionPenalty <= 0 {
+		return &tracks[mm.rand.Intn(len(tracks))]
+	}
+
+	weights := make([]float64, len(tracks))
+	total := 0.0
+	for i := range tracks {
+		weights[i] = 1 / float64(1+mm.sessionCount(tracks[i].Track.ID))
+		total += weights[i]
+	}
+
+	target := mm.rand.Float64() * total
+	for i, weight := range weights {
+		target -= weight
+		if target < 0 {
+			return &tracks[i]
+		}
+	}
+
+	return &tracks[len(tracks)-1]
+}
+
+// SetQueue remplace la file de duels imposés (paires d'IDs de tracks gauche/droite)
+func (mm *Matchmaker) SetQueue(pairs [][2]int64) {
+	mm.queueMu.Lock()
+	defer mm.queueMu.Unlock()
+	mm.queue = append([][2]int64(nil), pairs...)
+}
+
+// EnqueueMatch ajoute un duel à la fin de la file
+func (mm *Matchmaker) EnqueueMatch(leftID, rightID int64) {
+	mm.queueMu.Lock()
+	defer mm.queueMu.Unlock()
+	mm.queue = append(mm.queue, [2]int64{leftID, rightID})
+}
+
+// ReturnQueuedMatch remet un duel en tête de file (duel retiré mais finalement pas joué)
+func (mm *Matchmaker) ReturnQueuedMatch(leftID, rightID int64) {
+	mm.queueMu.Lock()
+	defer mm.queueMu.Unlock()
+	mm.queue = append([][2]int64{{leftID, rightID}}, mm.queue...)
+}
+
+// QueueLength retourne le nombre de duels restant dans la file
+func (mm *Matchmaker) QueueLength() int {
+	mm.queueMu.Lock()
+	defer mm.queueMu.Unlock()
+	return len(mm.queue)
+}
+
+// NextQueuedMatch retire le prochain duel de la file
+// Les paires dont un track n'existe plus sont ignorées ; ok = false quand la file est vide
+func (mm *Matchmaker) NextQueuedMatch() (left, right *models.TrackWithRating, ok bool) {
+	for {
+		mm.queueMu.Lock()
+		if len(mm.queue) == 0 {
+			mm.queueMu.Unlock()
+			return nil, nil, false
+		}
+		pair := mm.queue[0]
+		mm.queue = mm.queue[1:]
+		mm.queueMu.Unlock()
+
+		left, err := mm.db.GetTrackWithRating(pair[0])
+		if err != nil {
+			continue
+		}
+		right, err := mm.db.GetTrackWithRating(pair[1])
+		if err != nil {
+			continue
+		}
+
+		mm.recordAppearance(left, right)
+		return left, right, true
+	}
+}
+
+// MarkUndone signale un duel annulé pour ne pas le reproposer immédiatement
+func (mm *Matchmaker) MarkUndone(leftTrackID, rightTrackID int64) {
+	mm.undoneMu.Lock()
+	defer mm.undoneMu.Unlock()
+	mm.justUndone[newTrackPair(leftTrackID, rightTrackID)] = UndoAvoidanceMatches
+}
+
+// isJustUndone indique si une paire vient d'être annulée
+func (mm *Matchmaker) isJustUndone(left, right *models.TrackWithRating) bool {
+	mm.undoneMu.Lock()
+	defer mm.undoneMu.Unlock()
+	return mm.justUndone[newTrackPair(left.Track.ID, right.Track.ID)] > 0
+}
+
+// ageUndone décompte un match pour chaque paire annulée
+func (mm *Matchmaker) ageUndone() {
+	mm.undoneMu.Lock()
+	defer mm.undoneMu.Unlock()
+	for pair, remaining := range mm.justUndone {
+		if remaining <= 1 {
+			delete(mm.justUndone, pair)
+		} else {
+			mm.justUndone[pair] = remaining - 1
+		}
 	}
 }
 
 // GetNextMatch sélectionne la prochaine paire de tracks pour un duel
 func (mm *Matchmaker) GetNextMatch() (*models.TrackWithRating, *models.TrackWithRating, error) {
 	// Récupérer tous les tracks avec leurs ratings
-	allTracks, err := mm.db.GetAllTracksWithRatings()
+	allTracks, err := mm.candidates()
 	if err != nil {
-		return nil, nil, fmt.Errorf("erreur récupération tracks: %w", err)
+		return nil, nil, err
 	}
 
 	if len(allTracks) < 2 {
-		return nil, nil, fmt.Errorf("besoin d'au moins 2 tracks pour un duel")
+		return nil, nil, ErrNotEnoughTracks
+	}
+
+	defer mm.ageUndone()
+
+	// Mode inter-genres : uniquement des duels genre A contre genre B
+	if genreA, genreB := mm.CrossGenres(); genreA != "" {
+		leftTrack, rightTrack, err := mm.crossGenreMatch(allTracks, genreA, genreB)
+		if err == nil {
+			mm.recordAppearance(leftTrack, rightTrack)
+			return leftTrack, rightTrack, nil
+		}
+		// Un genre s'est vidé (humeur, suppression) : retour au matchmaking normal
+		mm.SetCrossGenres("", "")
+	}
+
+	// Track épinglé en cours de calibration
+	if leftTrack, rightTrack := mm.pinnedMatch(allTracks); leftTrack != nil {
+		mm.recordAppearance(leftTrack, rightTrack)
+		return leftTrack, rightTrack, nil
+	}
+
+	// De temps en temps, une rivalité définie par l'utilisateur
+	if leftTrack, rightTrack := mm.rivalryMatch(allTracks); leftTrack != nil {
+		mm.recordAppearance(leftTrack, rightTrack)
+		return leftTrack, rightTrack, nil
+	}
+
+	// Laisser reposer les tracks tout juste vus, puis retirer une paire
+	// tout juste annulée, tant qu'il existe d'autres paires
+	rested := mm.withoutCoolingDown(allTracks)
+	var leftTrack, rightTrack *models.TrackWithRating
+	for attempt := 0; attempt < maxUndoneRetries; attempt++ {
+		leftTrack, rightTrack = mm.selectMatch(rested)
+		if !mm.isJustUndone(leftTrack, rightTrack) || len(rested) < 3 {
+			break
+		}
+	}
+
+	mm.recordAppearance(leftTrack, rightTrack)
+	return leftTrack, rightTrack, nil
+}
+
+// pinnedMatch place le track épinglé à gauche pour une partie des duels,
+// face à l'adversaire le plus proche en Elo. Le track est désépinglé
+// automatiquement après PinnedBattles duels
+func (mm *Matchmaker) pinnedMatch(tracks []models.TrackWithRating) (*models.TrackWithRating, *models.TrackWithRating) {
+	pin, err := mm.db.GetPinnedTrack()
+	if err != nil || pin == nil {
+		return nil, nil
+	}
+
+	var pinned *models.TrackWithRating
+	for i := range tracks {
+		if tracks[i].Track.ID == pin.TrackID {
+			pinned = &tracks[i]
+			break
+		}
+	}
+	if pinned == nil {
+		return nil, nil // Track supprimé ou exclu des duels
+	}
+
+	if pinned.Rating.GetTotalBattles()-pin.StartBattles >= mm.config.PinnedBattles {
+		_ = mm.db.UnpinTrack()
+		return nil, nil
 	}
 
+	if mm.rand.Float64() >= mm.config.PinnedRate {
+		return nil, nil
+	}
+
+	// Éviter de rejouer une paire tout juste annulée
+	opponents := make([]models.TrackWithRating, 0, len(tracks))
+	for _, track := range tracks {
+		if !mm.isJustUndone(pinned, &track) {
+			opponents = append(opponents, track)
+		}
+	}
+
+	opponent := mm.findBestOpponent(pinned, opponents)
+	if opponent == nil {
+		return nil, nil
+	}
+	return pinned, opponent
+}
+
+// loadRivalries charge les rivalités depuis la base si ce n'est pas déjà fait
+// Doit être appelée avec rivalryMu verrouillé
+func (mm *Matchmaker) loadRivalries() error {
+	if mm.rivalries != nil {
+		return nil
+	}
+
+	rivalries, err := mm.db.GetRivalries()
+	if err != nil {
+		return err
+	}
+
+	mm.rivalries = make(map[trackPair]bool, len(rivalries))
+	for _, rivalry := range rivalries {
+		mm.rivalries[newTrackPair(rivalry.TrackA, rivalry.TrackB)] = true
+	}
+	return nil
+}
+
+// AddRivalry définit une rivalité : les deux tracks s'affronteront régulièrement
+func (mm *Matchmaker) AddRivalry(a, b int64) error {
+	if a == b {
+		return fmt.Errorf("un track ne peut pas être son propre rival")
+	}
+
+	mm.rivalryMu.Lock()
+	defer mm.rivalryMu.Unlock()
+
+	if err := mm.loadRivalries(); err != nil {
+		return err
+	}
+	if err := mm.db.AddRivalry(a, b); err != nil {
+		return err
+	}
+	mm.rivalries[newTrackPair(a, b)] = true
+	return nil
+}
+
+// RemoveRivalry supprime la rivalité entre deux tracks
+func (mm *Matchmaker) RemoveRivalry(a, b int64) error {
+	mm.rivalryMu.Lock()
+	defer mm.rivalryMu.Unlock()
+
+	if err := mm.loadRivalries(); err != nil {
+		return err
+	}
+	if err := mm.db.RemoveRivalry(a, b); err != nil {
+		return err
+	}
+	delete(mm.rivalries, newTrackPair(a, b))
+	return nil
+}
+
+// IsRivalry indique si deux tracks forment une rivalité définie
+func (mm *Matchmaker) IsRivalry(a, b int64) bool {
+	mm.rivalryMu.Lock()
+	defer mm.rivalryMu.Unlock()
+
+	if err := mm.loadRivalries(); err != nil {
+		return false
+	}
+	return mm.rivalries[newTrackPair(a, b)]
+}
+
+// rivalryMatch propose une rivalité pour une partie des duels (RivalryRate)
+// Seules les rivalités dont les deux tracks sont éligibles aux duels sont retenues,
+// en favorisant celles dont les tracks ont peu joué pendant la session
+func (mm *Matchmaker) rivalryMatch(tracks []models.TrackWithRating) (*models.TrackWithRating, *models.TrackWithRating) {
+	if mm.rand.Float64() >= mm.config.RivalryRate {
+		return nil, nil
+	}
+
+	mm.rivalryMu.Lock()
+	if err := mm.loadRivalries(); err != nil || len(mm.rivalries) == 0 {
+		mm.rivalryMu.Unlock()
+		return nil, nil
+	}
+	pairs := make([]trackPair, 0, len(mm.rivalries))
+	for pair := range mm.rivalries {
+		pairs = append(pairs, pair)
+	}
+	mm.rivalryMu.Unlock()
+
+	byID := make(map[int64]*models.TrackWithRating, len(tracks))
+	for i := range tracks {
+		byID[tracks[i].Track.ID] = &tracks[i]
+	}
+
+	var eligible [][2]*models.TrackWithRating
+	var weights []float64
+	total := 0.0
+	for _, pair := range pairs {
+		a, b := byID[pair.low], byID[pair.high]
+		if a == nil || b == nil || mm.isJustUndone(a, b) {
+			continue // Track supprimé, exclu des duels ou paire tout juste annulée
+		}
+		weight := 1 / float64(1+mm.sessionCount(a.Track.ID)+mm.sessionCount(b.Track.ID))
+		eligible = append(eligible, [2]*models.TrackWithRating{a, b})
+		weights = append(weights, weight)
+		total += weight
+	}
+	if len(eligible) == 0 {
+		return nil, nil
+	}
+
+	chosen := eligible[len(eligible)-1]
+	target := mm.rand.Float64() * total
+	for i, weight := range weights {
+		target -= weight
+		if target < 0 {
+			chosen = eligible[i]
+			break
+		}
+	}
+
+	// Côtés aléatoires pour ne pas avantager un track par sa position
+	if mm.rand.Intn(2) == 0 {
+		return chosen[0], chosen[1]
+	}
+	return chosen[1], chosen[0]
+}
+
+// maxUndoneRetries limite les tirages pour éviter une paire annulée
+const maxUndoneRetries = 10
+
+// selectMatch tire une paire en exploration ou en matchmaking équilibré
+func (mm *Matchmaker) selectMatch(allTracks []models.TrackWithRating) (*models.TrackWithRating, *models.TrackWithRating) {
 	// Déterminer si on fait de l'exploration ou du matchmaking équilibré
 	shouldExplore := mm.shouldExplore(allTracks)
 
 	var leftTrack, rightTrack *models.TrackWithRating
 
+	if mm.config.Mode == config.MatchModeUncertainty {
+		if leftTrack, rightTrack = mm.uncertaintyMatch(allTracks); leftTrack != nil {
+			return leftTrack, rightTrack
+		}
+	}
+
 	if shouldExplore {
 		leftTrack, rightTrack = mm.explorationMatch(allTracks)
 	} else {
@@ -56,7 +514,90 @@ func (mm *Matchmaker) GetNextMatch() (*models.TrackWithRating, *models.TrackWith
 		leftTrack, rightTrack = mm.randomMatch(allTracks)
 	}
 
-	return leftTrack, rightTrack, nil
+	return leftTrack, rightTrack
+}
+
+// candidates récupère les tracks éligibles aux duels selon la configuration
+// Relus en base à chaque match, sans cache : un track ajouté en cours de session
+// (recherche, recommandations) peut apparaître dès le match suivant
+func (mm *Matchmaker) candidates() ([]models.TrackWithRating, error) {
+	tracks, err := mm.db.GetAllTracksWithRatings()
+	if err != nil {
+		return nil, fmt.Errorf("erreur récupération tracks: %w", err)
+	}
+
+	if mm.config.ExcludeExplicit {
+		tracks = models.WithoutExplicit(tracks)
+	}
+
+	if mood := mm.Mood(); mood != "" {
+		tracks = models.WithMood(tracks, mood)
+	}
+
+	return tracks, nil
+}
+
+// SetMood limite les duels aux tracks d'une humeur ("" pour toute la bibliothèque)
+func (mm *Matchmaker) SetMood(mood string) {
+	mm.moodMu.Lock()
+	defer mm.moodMu.Unlock()
+	mm.mood = mood
+}
+
+// Mood retourne l'humeur à laquelle les duels sont limités ("" si aucune)
+func (mm *Matchmaker) Mood() string {
+	mm.moodMu.Lock()
+	defer mm.moodMu.Unlock()
+	return mm.mood
+}
+
+// SetCrossGenres limite les duels aux paires genre A contre genre B ("" pour désactiver)
+func (mm *Matchmaker) SetCrossGenres(genreA, genreB string) {
+	mm.crossGenreMu.Lock()
+	defer mm.crossGenreMu.Unlock()
+	mm.crossGenres = [2]string{genreA, genreB}
+}
+
+// CrossGenres retourne les genres opposés du mode inter-genres ("" si désactivé)
+func (mm *Matchmaker) CrossGenres() (string, string) {
+	mm.crossGenreMu.Lock()
+	defer mm.crossGenreMu.Unlock()
+	return mm.crossGenres[0], mm.crossGenres[1]
+}
+
+// CrossGenreMatch tire un duel opposant un track du genre A (à gauche) à un track
+// du genre B, l'adversaire étant choisi au plus proche en Elo
+// Retourne ErrEmptyGenre si l'un des genres n'a aucun track éligible
+func (mm *Matchmaker) CrossGenreMatch(genreA, genreB string) (*models.TrackWithRating, *models.TrackWithRating, error) {
+	allTracks, err := mm.candidates()
+	if err != nil {
+		return nil, nil, err
+	}
+	return mm.crossGenreMatch(allTracks, genreA, genreB)
+}
+
+// crossGenreMatch tire un duel inter-genres parmi les tracks éligibles
+func (mm *Matchmaker) crossGenreMatch(tracks []models.TrackWithRating, genreA, genreB string) (*models.TrackWithRating, *models.TrackWithRating, error) {
+	sideA := models.WithGenre(tracks, genreA)
+	if len(sideA) == 0 {
+		return nil, nil, fmt.Errorf("%w: %s", ErrEmptyGenre, genreA)
+	}
+	sideB := models.WithGenre(tracks, genreB)
+	if len(sideB) == 0 {
+		return nil, nil, fmt.Errorf("%w: %s", ErrEmptyGenre, genreB)
+	}
+
+	// Le track de gauche est tiré en favorisant les moins vus, puis l'adversaire au plus proche
+	// en Elo (un track des deux genres ne peut pas s'affronter lui-même)
+	for attempt := 0; attempt < maxUndoneRetries; attempt++ {
+		left := mm.pickFresh(mm.withoutCoolingDown(sideA))
+		right := mm.findBestOpponent(left, sideB)
+		if right != nil && (!mm.isJustUndone(left, right) || attempt == maxUndoneRetries-1) {
+			return left, right, nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("%w: %s (seul track commun aux deux genres)", ErrEmptyGenre, genreB)
 }
 
 // shouldExplore détermine si on devrait faire un match d'exploration
@@ -98,13 +639,17 @@ func (mm *Matchmaker) explorationMatch(tracks []models.TrackWithRating) (*models
 	}
 
 	// Sélectionner un track peu joué
-	leftIdx := mm.rand.Intn(len(underplayed))
-	leftTrack := &underplayed[leftIdx]
+	var leftTrack *models.TrackWithRating
+	if mm.config.PopularityExploration {
+		leftTrack = mm.pickByPopularity(underplayed)
+	} else {
+		leftTrack = mm.pickFresh(underplayed)
+	}
 
 	// Sélectionner un adversaire (peut être peu joué ou expérimenté)
 	allOthers := make([]models.TrackWithRating, 0)
-	for i, track := range tracks {
-		if int64(i) != leftTrack.Track.ID { // Éviter le même track
+	for _, track := range tracks {
+		if track.Track.ID != leftTrack.Track.ID { // Éviter le même track
 			allOthers = append(allOthers, track)
 		}
 	}
@@ -113,12 +658,39 @@ func (mm *Matchmaker) explorationMatch(tracks []models.TrackWithRating) (*models
 		return nil, nil
 	}
 
-	rightIdx := mm.rand.Intn(len(allOthers))
-	rightTrack := &allOthers[rightIdx]
+	rightTrack := mm.pickFresh(allOthers)
 
 	return leftTrack, rightTrack
 }
 
+// pickByPopularity tire un track au hasard, pondéré par sa popularité Spotify
+// Retombe sur un tirage uniforme si aucune popularité n'est connue
+func (mm *Matchmaker) pickByPopularity(tracks []models.TrackWithRating) *models.TrackWithRating {
+	totalWeight := 0
+	for _, track := range tracks {
+		if track.Track.Popularity > 0 {
+			totalWeight += track.Track.Popularity
+		}
+	}
+
+	if totalWeight == 0 {
+		return &tracks[mm.rand.Intn(len(tracks))]
+	}
+
+	target := mm.rand.Intn(totalWeight)
+	for i := range tracks {
+		if tracks[i].Track.Popularity <= 0 {
+			continue
+		}
+		target -= tracks[i].Track.Popularity
+		if target < 0 {
+			return &tracks[i]
+		}
+	}
+
+	return &tracks[len(tracks)-1]
+}
+
 // balancedMatch sélectionne un match équilibré basé sur l'Elo
 func (mm *Matchmaker) balancedMatch(tracks []models.TrackWithRating) (*models.TrackWithRating, *models.TrackWithRating) {
 	// Filtrer les tracks avec assez de duels pour un match équilibré
@@ -134,9 +706,8 @@ func (mm *Matchmaker) balancedMatch(tracks []models.TrackWithRating) (*models.Tr
 		return mm.randomMatch(tracks)
 	}
 
-	// Sélectionner le premier track aléatoirement
-	leftIdx := mm.rand.Intn(len(experienced))
-	leftTrack := &experienced[leftIdx]
+	// Sélectionner le premier track aléatoirement, en favorisant les moins vus de la session
+	leftTrack := mm.pickFresh(experienced)
 
 	// Trouver un adversaire avec un Elo proche
 	bestOpponent := mm.findBestOpponent(leftTrack, experienced)
@@ -144,7 +715,74 @@ func (mm *Matchmaker) balancedMatch(tracks []models.TrackWithRating) (*models.Tr
 	return leftTrack, bestOpponent
 }
 
+// ratingDeviation retourne l'incertitude (RD de Glicko) de l'Elo d'un track, inactivité comprise
+func ratingDeviation(track models.TrackWithRating) float64 {
+	return elo.CurrentDeviation(track.Rating, time.Now())
+}
+
+// uncertaintyMatch sélectionne la paire dont les Elo sont les plus incertains (somme des RD²),
+// parmi les paires à moins de UncertaintyEloRange d'écart : le duel le plus informatif
+// Les égalités (ex: tracks jamais joués) sont départagées au hasard ; les RD sont arrondis au
+// point près pour que la remontée due aux secondes écoulées depuis le dernier duel ne fasse
+// pas rejouer sans cesse la même paire
+func (mm *Matchmaker) uncertaintyMatch(tracks []models.TrackWithRating) (*models.TrackWithRating, *models.TrackWithRating) {
+	order := mm.rand.Perm(len(tracks))
+	deviations := make([]float64, len(tracks))
+	for i := range tracks {
+		rd := math.Round(ratingDeviation(tracks[i]))
+		deviations[i] = rd * rd
+	}
+
+	var leftTrack, rightTrack *models.TrackWithRating
+	best := 0.0
+	for a, i := range order {
+		for _, j := range order[a+1:] {
+			if abs(tracks[i].Rating.Elo-tracks[j].Rating.Elo) > UncertaintyEloRange {
+				continue
+			}
+			if score := deviations[i] + deviations[j]; score > best {
+				best = score
+				leftTrack, rightTrack = &tracks[i], &tracks[j]
+			}
+		}
+	}
+
+	return leftTrack, rightTrack
+}
+
+// FindOpponentFor trouve l'adversaire le plus proche en Elo pour un track donné
+// Les tracks d'exclude (ex: l'adversaire précédent) ne sont pas proposés
+func (mm *Matchmaker) FindOpponentFor(target *models.TrackWithRating, exclude ...int64) (*models.TrackWithRating, error) {
+	allTracks, err := mm.candidates()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(exclude) > 0 {
+		excluded := make(map[int64]bool, len(exclude))
+		for _, id := range exclude {
+			excluded[id] = true
+		}
+
+		filtered := allTracks[:0]
+		for _, track := range allTracks {
+			if !excluded[track.Track.ID] {
+				filtered = append(filtered, track)
+			}
+		}
+		allTracks = filtered
+	}
+
+	opponent := mm.findBestOpponent(target, allTracks)
+	if opponent == nil {
+		return nil, fmt.Errorf("aucun adversaire disponible")
+	}
+
+	return opponent, nil
+}
+
 // findBestOpponent trouve le meilleur adversaire basé sur l'Elo
+// Les apparitions dans la session pénalisent les candidats déjà beaucoup vus
 func (mm *Matchmaker) findBestOpponent(target *models.TrackWithRating, candidates []models.TrackWithRating) *models.TrackWithRating {
 	var bestOpponent *models.TrackWithRating
 	bestDifference := int(^uint(0) >> 1) // Max int
@@ -159,11 +797,12 @@ func (mm *Matchmaker) findBestOpponent(target *models.TrackWithRating, candidate
 
 		// Calculer la différence d'Elo
 		eloDiff := abs(candidate.Rating.Elo - target.Rating.Elo)
+		score := eloDiff + mm.sessionPenalty(candidate.Track.ID)
 
 		// Si dans la plage acceptable et meilleur que le précédent
-		if eloDiff <= EloRange && eloDiff < bestDifference {
+		if eloDiff <= EloRange && score < bestDifference {
 			bestOpponent = candidate
-			bestDifference = eloDiff
+			bestDifference = score
 		}
 	}
 
@@ -176,10 +815,10 @@ func (mm *Matchmaker) findBestOpponent(target *models.TrackWithRating, candidate
 				continue
 			}
 
-			eloDiff := abs(candidate.Rating.Elo - target.Rating.Elo)
-			if eloDiff < bestDifference {
+			score := abs(candidate.Rating.Elo-target.Rating.Elo) + mm.sessionPenalty(candidate.Track.ID)
+			if score < bestDifference {
 				bestOpponent = candidate
-				bestDifference = eloDiff
+				bestDifference = score
 			}
 		}
 	}
@@ -193,16 +832,16 @@ func (mm *Matchmaker) randomMatch(tracks []models.TrackWithRating) (*models.Trac
 		return nil, nil
 	}
 
-	// Sélectionner deux indices différents
-	leftIdx := mm.rand.Intn(len(tracks))
-	rightIdx := mm.rand.Intn(len(tracks))
+	// Sélectionner deux tracks différents, en favorisant les moins vus de la session
+	leftTrack := mm.pickFresh(tracks)
+	rightTrack := mm.pickFresh(tracks)
 
 	// S'assurer qu'ils sont différents
-	for rightIdx == leftIdx {
-		rightIdx = mm.rand.Intn(len(tracks))
+	for rightTrack.Track.ID == leftTrack.Track.ID {
+		rightTrack = mm.pickFresh(tracks)
 	}
 
-	return &tracks[leftIdx], &tracks[rightIdx]
+	return leftTrack, rightTrack
 }
 
 // GetMatchQuality évalue la qualité d'un match potentiel
@@ -241,6 +880,29 @@ func (mm *Matchmaker) GetRecentOpponents(trackID int64, limit int) ([]int64, err
 	opponents := make([]int64, 0)
 	seen := make(map[int64]bool)
 
+	// Les duels annulés ne sont plus en base mais comptent comme récents
+	mm.undoneMu.Lock()
+	undone := make([]trackPair, 0, len(mm.justUndone))
+	for pair := range mm.justUndone {
+		undone = append(undone, pair)
+	}
+	mm.undoneMu.Unlock()
+
+	for _, pair := range undone {
+		var opponentID int64
+		if pair.low == trackID {
+			opponentID = pair.high
+		} else if pair.high == trackID {
+			opponentID = pair.low
+		} else {
+			continue
+		}
+		if !seen[opponentID] {
+			opponents = append(opponents, opponentID)
+			seen[opponentID] = true
+		}
+	}
+
 	for _, duel := range duels {
 		var opponentID int64
 
@@ -303,6 +965,27 @@ func abs(x int) int {
 	return x
 }
 
+// CalibrationProgress retourne le pourcentage (0-100) de tracks jouables ayant
+// disputé assez de duels pour le matchmaking équilibré
+func (mm *Matchmaker) CalibrationProgress() (float64, error) {
+	tracks, err := mm.candidates()
+	if err != nil {
+		return 0, err
+	}
+	if len(tracks) == 0 {
+		return 0, nil
+	}
+
+	calibrated := 0
+	for _, track := range tracks {
+		if track.Rating.GetTotalBattles() >= MinBattlesForBalance {
+			calibrated++
+		}
+	}
+
+	return float64(calibrated) * 100 / float64(len(tracks)), nil
+}
+
 // GetMatchmakingStats retourne des statistiques sur le matchmaking
 func (mm *Matchmaker) GetMatchmakingStats() (map[string]interface{}, error) {
 	tracks, err := mm.db.GetAllTracksWithRatings()
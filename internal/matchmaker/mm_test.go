@@ -0,0 +1,272 @@
+package matchmaker
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"path/filepath"
+	"songbattle/internal/config"
+	"songbattle/internal/elo"
+	"songbattle/internal/models"
+	"songbattle/internal/store"
+	"testing"
+)
+
+// addTestTrack insère un track avec son rating initial
+func addTestTrack(t *testing.T, db *store.DB, n int) int64 {
+	t.Helper()
+
+	track := &models.Track{
+		SpotifyID:  fmt.Sprintf("track%d", n),
+		Name:       fmt.Sprintf("Track %d", n),
+		Artist:     fmt.Sprintf("Artist %d", n),
+		Album:      "Album",
+		SpotifyURI: fmt.Sprintf("spotify:track:track%d", n),
+		Popularity: -1,
+	}
+	if err := db.CreateTrack(track); err != nil {
+		t.Fatalf("CreateTrack: %v", err)
+	}
+	return track.ID
+}
+
+func TestTrackAddedMidSessionIsMatched(t *testing.T) {
+	db, err := store.NewDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	defer db.Close()
+
+	for n := 0; n < 4; n++ {
+		addTestTrack(t, db, n)
+	}
+
+	mm := NewMatchmaker(db)
+	mm.rand = rand.New(rand.NewSource(1))
+
+	if _, _, err := mm.GetNextMatch(); err != nil {
+		t.Fatalf("GetNextMatch: %v", err)
+	}
+
+	// Ajout en cours de session, comme depuis la recherche ou les recommandations
+	added := addTestTrack(t, db, 4)
+
+	tracks, err := mm.candidates()
+	if err != nil {
+		t.Fatalf("candidates: %v", err)
+	}
+	found := false
+	for _, track := range tracks {
+		found = found || track.Track.ID == added
+	}
+	if !found {
+		t.Fatalf("track %d added mid-session is not a candidate", added)
+	}
+
+	for i := 0; i < 20; i++ {
+		left, right, err := mm.GetNextMatch()
+		if err != nil {
+			t.Fatalf("GetNextMatch: %v", err)
+		}
+		if left.Track.ID == added || right.Track.ID == added {
+			return
+		}
+	}
+	t.Errorf("track %d added mid-session never matched in 20 battles", added)
+}
+
+// newTestMatchmaker ouvre une base temporaire de n tracks et un matchmaker au tirage reproductible
+func newTestMatchmaker(t *testing.T, n int, cfg config.MatchmakingConfig, seed int64) (*Matchmaker, *store.DB) {
+	t.Helper()
+
+	db, err := store.NewDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	for i := 0; i < n; i++ {
+		addTestTrack(t, db, i)
+	}
+
+	mm := NewMatchmakerWithConfig(db, cfg)
+	mm.rand = rand.New(rand.NewSource(seed))
+	return mm, db
+}
+
+func TestAppearanceCooldown(t *testing.T) {
+	const cooldown = 3
+	cfg := config.Default().Matchmaking
+	cfg.AppearanceCooldown = cooldown
+
+	for seed := int64(1); seed <= 5; seed++ {
+		mm, _ := newTestMatchmaker(t, 12, cfg, seed)
+
+		// Numéro du dernier match de chaque track
+		last := make(map[int64]int)
+		for match := 1; match <= 40; match++ {
+			left, right, err := mm.GetNextMatch()
+			if err != nil {
+				t.Fatalf("GetNextMatch: %v", err)
+			}
+			for _, id := range []int64{left.Track.ID, right.Track.ID} {
+				if previous, seen := last[id]; seen && match-previous <= cooldown {
+					t.Errorf("seed %d: track %d in matches %d and %d, want %d matches of rest",
+						seed, id, previous, match, cooldown)
+				}
+				last[id] = match
+			}
+		}
+	}
+}
+
+// appearanceVariance retourne la variance des apparitions de session des n tracks
+func appearanceVariance(mm *Matchmaker, n int) float64 {
+	counts := mm.SessionAppearances()
+	mean, sum := 0.0, 0.0
+	for _, count := range counts {
+		mean += float64(count)
+	}
+	mean /= float64(n)
+	for id := int64(1); id <= int64(n); id++ {
+		diff := float64(counts[id]) - mean
+		sum += diff * diff
+	}
+	return sum / float64(n)
+}
+
+func TestSessionPenaltySpreadsAppearances(t *testing.T) {
+	const (
+		tracks  = 20
+		matches = 100
+	)
+
+	variance := func(penalty int) float64 {
+		cfg := config.Default().Matchmaking
+		cfg.SessionPenalty = penalty
+
+		total := 0.0
+		for seed := int64(1); seed <= 5; seed++ {
+			mm, _ := newTestMatchmaker(t, tracks, cfg, seed)
+			for i := 0; i < matches; i++ {
+				if _, _, err := mm.GetNextMatch(); err != nil {
+					t.Fatalf("GetNextMatch: %v", err)
+				}
+			}
+			total += appearanceVariance(mm, tracks)
+		}
+		return total / 5
+	}
+
+	// Chaque track apparaît 10 fois en moyenne : la pénalité doit resserrer l'écart autour de cette moyenne
+	uniform, spread := variance(0), variance(config.Default().Matchmaking.SessionPenalty)
+	if spread >= uniform*2/3 {
+		t.Errorf("appearance variance with session penalty = %.2f, want well below %.2f without", spread, uniform)
+	}
+}
+
+func TestUncertaintyModeConverges(t *testing.T) {
+	const (
+		tracks = 16
+		duels  = 80
+		seeds  = 5
+	)
+
+	// Duels simulés, le vainqueur étant tiré d'après une force réelle cachée (50 points d'Elo
+	// par ID, probabilité Elo). Retourne l'écart type du track le moins bien connu, qui mesure
+	// la convergence de toute la bibliothèque, et le nombre de paires différentes jouées
+	simulate := func(uncertainty bool, seed int64) (float64, int) {
+		cfg := config.Default().Matchmaking
+		cfg.Mode = config.MatchModeUncertainty
+		cfg.SessionPenalty = 0
+		mm, db := newTestMatchmaker(t, tracks, cfg, seed)
+		es := elo.NewEloSystem(db)
+
+		outcomes := rand.New(rand.NewSource(seed))
+		pairs := make(map[trackPair]bool)
+		for i := 0; i < duels; i++ {
+			var left, right *models.TrackWithRating
+			if uncertainty {
+				var err error
+				if left, right, err = mm.GetNextMatch(); err != nil {
+					t.Fatalf("GetNextMatch: %v", err)
+				}
+			} else {
+				// Référence : paires tirées uniformément
+				candidates, err := mm.candidates()
+				if err != nil {
+					t.Fatalf("candidates: %v", err)
+				}
+				left, right = mm.randomMatch(candidates)
+			}
+			pairs[newTrackPair(left.Track.ID, right.Track.ID)] = true
+
+			result := models.WinnerRight
+			gap := 50 * float64(right.Track.ID-left.Track.ID)
+			if outcomes.Float64() < 1/(1+math.Pow(10, gap/400)) {
+				result = models.WinnerLeft
+			}
+			if _, err := es.ProcessDuel(left.Track.ID, right.Track.ID, result); err != nil {
+				t.Fatalf("ProcessDuel: %v", err)
+			}
+		}
+
+		all, err := db.GetAllTracksWithRatings()
+		if err != nil {
+			t.Fatalf("GetAllTracksWithRatings: %v", err)
+		}
+		worst := 0.0
+		for _, track := range all {
+			worst = math.Max(worst, ratingDeviation(track))
+		}
+		return worst, len(pairs)
+	}
+
+	for seed := int64(1); seed <= seeds; seed++ {
+		uncertainRD, uncertainPairs := simulate(true, seed)
+		randomRD, _ := simulate(false, seed)
+		if uncertainRD >= randomRD {
+			t.Errorf("seed %d: least known track at RD %.0f in uncertainty mode, want below random pairing (%.0f)",
+				seed, uncertainRD, randomRD)
+		}
+		// Les mêmes paires rejouées en boucle ne départagent jamais le reste de la bibliothèque
+		if uncertainPairs < duels/2 {
+			t.Errorf("seed %d: uncertainty mode played %d distinct pairs in %d duels, want at least %d",
+				seed, uncertainPairs, duels, duels/2)
+		}
+	}
+}
+
+func TestUndoneDuelNotReproposed(t *testing.T) {
+	// Sans pénalité de session, rien d'autre n'écarte la paire tout juste jouée
+	cfg := config.Default().Matchmaking
+	cfg.SessionPenalty = 0
+
+	for seed := int64(1); seed <= 20; seed++ {
+		mm, db := newTestMatchmaker(t, 4, cfg, seed)
+		es := elo.NewEloSystem(db)
+
+		left, right, err := mm.GetNextMatch()
+		if err != nil {
+			t.Fatalf("GetNextMatch: %v", err)
+		}
+		if _, err := es.ProcessDuel(left.Track.ID, right.Track.ID, models.WinnerLeft); err != nil {
+			t.Fatalf("ProcessDuel: %v", err)
+		}
+
+		// Annulation comme depuis l'UI : le duel disparaît puis la paire est signalée
+		undone, _, err := es.UndoLastDuel()
+		if err != nil {
+			t.Fatalf("UndoLastDuel: %v", err)
+		}
+		mm.MarkUndone(undone.LeftTrackID, undone.RightTrackID)
+
+		next, nextRight, err := mm.GetNextMatch()
+		if err != nil {
+			t.Fatalf("GetNextMatch: %v", err)
+		}
+		if newTrackPair(next.Track.ID, nextRight.Track.ID) == newTrackPair(left.Track.ID, right.Track.ID) {
+			t.Errorf("seed %d: undone pair %d-%d proposed again right away", seed, left.Track.ID, right.Track.ID)
+		}
+	}
+}
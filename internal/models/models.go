@@ -3,6 +3,7 @@ package models
 import (
 	"database/sql/driver"
 	"encoding/json"
+	"strings"
 	"time"
 )
 
@@ -19,16 +20,41 @@ type Track struct {
 	PreviewURL        *string       `json:"preview_url" db:"preview_url"`
 	AudioFeaturesJSON AudioFeatures `json:"audio_features" db:"audio_features_json"`
 	CreatedAt         time.Time     `json:"created_at" db:"created_at"`
+	ISRC              string        `json:"isrc" db:"isrc"`
+	Popularity        int           `json:"popularity" db:"popularity"` // 0-100, -1 si inconnue
+	Explicit          bool          `json:"explicit" db:"explicit"`
+	DurationMs        int           `json:"duration_ms" db:"duration_ms"` // 0 si inconnue
+	Unavailable       bool          `json:"unavailable" db:"unavailable"` // Retiré du catalogue Spotify
+	UnavailableAt     *time.Time    `json:"unavailable_at" db:"unavailable_at"`
+	Source            string        `json:"source" db:"source"` // Origine de l'import ("" si inconnue)
+	Mood              string        `json:"mood" db:"mood"`     // Humeur déduite des audio features ("" si inconnue)
+}
+
+// Track sources, recorded when a track is first imported
+const (
+	SourceTopShort       = "top-short"
+	SourceTopMedium      = "top-medium"
+	SourceTopLong        = "top-long"
+	SourceRecommendation = "recommendation"
+	SourceSearch         = "search"
+)
+
+// ArtistSource retourne la source des tracks importés depuis le catalogue d'un artiste
+func ArtistSource(artistID string) string {
+	return "artist:" + artistID
 }
 
 // Rating contient les statistiques Elo d'une chanson
 type Rating struct {
-	TrackID    int64     `json:"track_id" db:"track_id"`
-	Elo        int       `json:"elo" db:"elo"`
-	Wins       int       `json:"wins" db:"wins"`
-	Losses     int       `json:"losses" db:"losses"`
-	Draws      int       `json:"draws" db:"draws"`
-	LastSeenAt time.Time `json:"last_seen_at" db:"last_seen_at"`
+	TrackID     int64     `json:"track_id" db:"track_id"`
+	Elo         int       `json:"elo" db:"elo"`
+	Wins        int       `json:"wins" db:"wins"`
+	Losses      int       `json:"losses" db:"losses"`
+	Draws       int       `json:"draws" db:"draws"`
+	LastSeenAt  time.Time `json:"last_seen_at" db:"last_seen_at"`
+	Appearances int       `json:"appearances" db:"appearances"` // Duels où le track est apparu, skips compris
+	Skips       int       `json:"skips" db:"skips"`             // Duels du track passés sans vote
+	RD          float64   `json:"rd" db:"rd"`                   // Écart type de l'Elo (RD de Glicko), 350 = inconnu
 }
 
 // Duel represents a battle between two songs
@@ -38,6 +64,80 @@ type Duel struct {
 	RightTrackID  int64     `json:"right_track_id" db:"right_track_id"`
 	WinnerTrackID *int64    `json:"winner_track_id" db:"winner_track_id"` // NULL si draw/skip
 	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+
+	// LeftScore is the left track's share of a split group vote (NULL for single-winner duels)
+	LeftScore *float64 `json:"left_score,omitempty" db:"left_score"`
+
+	// Confidence scales the K-factor of a vote cast after little listening (NULL for full weight)
+	Confidence *float64 `json:"confidence,omitempty" db:"confidence"`
+
+	// Result is WinnerLeft, WinnerRight, WinnerDraw or WinnerSkip
+	Result string `json:"result" db:"result"`
+}
+
+// DuelHistoryEntry is a duel with both tracks and the Elo swing it caused
+type DuelHistoryEntry struct {
+	Duel
+	Left           Track
+	Right          Track
+	Rated          bool // false for skips: no Elo change was recorded
+	LeftEloBefore  int
+	LeftEloAfter   int
+	RightEloBefore int
+	RightEloAfter  int
+}
+
+// LeftDelta retourne la variation d'Elo du track de gauche
+func (e *DuelHistoryEntry) LeftDelta() int {
+	return e.LeftEloAfter - e.LeftEloBefore
+}
+
+// RightDelta retourne la variation d'Elo du track de droite
+func (e *DuelHistoryEntry) RightDelta() int {
+	return e.RightEloAfter - e.RightEloBefore
+}
+
+// LoggedDuel is a recorded duel with its outcome, as replayed when recomputing ratings
+type LoggedDuel struct {
+	Duel
+}
+
+// HeadToHead is the record of every duel between two tracks, from the first track's point of view
+type HeadToHead struct {
+	Wins   int // Duels won by the first track
+	Losses int // Duels won by the second track
+	Draws  int
+	Skips  int // Skipped duels (no Elo change)
+}
+
+// Total retourne le nombre de duels entre les deux tracks, skips compris
+func (h HeadToHead) Total() int {
+	return h.Wins + h.Losses + h.Draws + h.Skips
+}
+
+// PairRecord is the head-to-head record of two tracks that have met, from TrackA's point of view
+// TrackA is the pair's lower track ID
+type PairRecord struct {
+	TrackA int64
+	TrackB int64
+	HeadToHead
+}
+
+// EloHistory records the Elo change of a track during a duel
+type EloHistory struct {
+	ID        int64     `json:"id" db:"id"`
+	TrackID   int64     `json:"track_id" db:"track_id"`
+	DuelID    int64     `json:"duel_id" db:"duel_id"`
+	OldElo    int       `json:"old_elo" db:"old_elo"`
+	NewElo    int       `json:"new_elo" db:"new_elo"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	// OldRD and NewRD are the rating deviation around the duel (NULL for duels played before RD tracking)
+	OldRD *float64 `json:"old_rd,omitempty" db:"old_rd"`
+	NewRD *float64 `json:"new_rd,omitempty" db:"new_rd"`
+
+	// OldLastSeenAt is when the track was last seen before the duel (NULL for older duels)
+	OldLastSeenAt *time.Time `json:"old_last_seen_at,omitempty" db:"old_last_seen_at"`
 }
 
 // Meta stores application metadata
@@ -63,6 +163,9 @@ type AudioFeatures struct {
 	Valence          float64 `json:"valence"`
 	Tempo            float64 `json:"tempo"`
 	TimeSignature    int     `json:"time_signature"`
+	// Estimated marks a rough profile computed from the 30-second preview:
+	// only Energy and Tempo are set
+	Estimated bool `json:"estimated,omitempty"`
 }
 
 // Implementation of sql.Scanner and driver.Valuer interfaces for Genres
@@ -127,13 +230,243 @@ const (
 
 // Constants for metadata
 const (
-	MetaKeyAccessToken  = "access_token"
-	MetaKeyRefreshToken = "refresh_token"
-	MetaKeyTokenExpiry  = "token_expiry"
-	MetaKeyDeviceID     = "device_id"
-	MetaKeyAppVersion   = "app_version"
+	MetaKeyAccessToken        = "access_token"
+	MetaKeyRefreshToken       = "refresh_token"
+	MetaKeyTokenExpiry        = "token_expiry"
+	MetaKeyTokenScopes        = "token_scopes"
+	MetaKeyDeviceID           = "device_id"
+	MetaKeyClientID           = "spotify_client_id"
+	MetaKeyAppVersion         = "app_version"
+	MetaKeyImportQueue        = "import_retry_queue"
+	MetaKeyImportSources      = "import_retry_sources"
+	MetaKeyFlaggedTracks      = "flagged_tracks"
+	MetaKeyPostVoteFocus      = "post_vote_focus"
+	MetaKeyDailyGoalReached   = "daily_goal_reached"
+	MetaKeyPinnedTrack        = "pinned_track"
+	MetaKeyOnboardingDone     = "onboarding_done"
+	MetaKeyQuickRated         = "quick_rated_tracks"
+	MetaKeyRivalries          = "rivalries"
+	MetaKeyShowWinProbability = "show_win_probability"
+	MetaKeyCalibratedOnly     = "leaderboard_calibrated_only"
+	MetaKeySessionProgress    = "session_progress"
+	MetaKeyCurrentLeft        = "current_left"
+	MetaKeyCurrentRight       = "current_right"
 )
 
+// PinnedTrack is a track placed in most upcoming duels until it has played enough battles
+type PinnedTrack struct {
+	TrackID      int64 `json:"track_id"`
+	StartBattles int   `json:"start_battles"` // Battles played when the track was pinned
+}
+
+// SessionProgress is the state of the running session, saved periodically so that
+// an abrupt termination does not lose the session counters
+type SessionProgress struct {
+	StartedAt   time.Time     `json:"started_at"`
+	SavedAt     time.Time     `json:"saved_at"`
+	Duels       int           `json:"duels"`
+	Skips       int           `json:"skips"`
+	StartElo    map[int64]int `json:"start_elo"` // Elo of every track when the session started
+	StartLeader string        `json:"start_leader"`
+	Ended       bool          `json:"ended"` // The app quit normally: the session is not resumed
+}
+
+// Rivalry is a pair of tracks the user wants to see battle regularly
+type Rivalry struct {
+	TrackA int64 `json:"track_a"`
+	TrackB int64 `json:"track_b"`
+}
+
+// Involves reports whether the rivalry is between the two given tracks, in either order
+func (r Rivalry) Involves(a, b int64) bool {
+	return (r.TrackA == a && r.TrackB == b) || (r.TrackA == b && r.TrackB == a)
+}
+
+// WithoutExplicit retourne les tracks dont les paroles ne sont pas explicites
+func WithoutExplicit(tracks []TrackWithRating) []TrackWithRating {
+	filtered := make([]TrackWithRating, 0, len(tracks))
+	for _, track := range tracks {
+		if !track.Track.Explicit {
+			filtered = append(filtered, track)
+		}
+	}
+	return filtered
+}
+
+// WithMood retourne les tracks classés dans une humeur
+func WithMood(tracks []TrackWithRating, mood string) []TrackWithRating {
+	filtered := make([]TrackWithRating, 0, len(tracks))
+	for _, track := range tracks {
+		if track.Track.Mood == mood {
+			filtered = append(filtered, track)
+		}
+	}
+	return filtered
+}
+
+// WithGenre retourne les tracks ayant le genre donné
+func WithGenre(tracks []TrackWithRating, genre string) []TrackWithRating {
+	filtered := make([]TrackWithRating, 0, len(tracks))
+	for _, track := range tracks {
+		for _, g := range track.Track.GenresJSON {
+			if g == genre {
+				filtered = append(filtered, track)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// WithoutUnavailable retourne les tracks encore disponibles sur Spotify
+func WithoutUnavailable(tracks []TrackWithRating) []TrackWithRating {
+	filtered := make([]TrackWithRating, 0, len(tracks))
+	for _, track := range tracks {
+		if !track.Track.Unavailable {
+			filtered = append(filtered, track)
+		}
+	}
+	return filtered
+}
+
+// NotSongReason explique pourquoi un item Spotify n'a pas sa place dans les duels
+// (épisode de podcast, fichier local, durée nulle, artiste manquant) ; "" pour une chanson
+func (t *Track) NotSongReason() string {
+	switch {
+	case t.SpotifyID == "":
+		return "no Spotify ID (local file)"
+	case t.SpotifyURI != "" && !strings.HasPrefix(t.SpotifyURI, "spotify:track:"):
+		return "not a track (" + t.SpotifyURI + ")"
+	case t.DurationMs <= 0:
+		return "zero duration"
+	case strings.TrimSpace(t.Artist) == "":
+		return "missing artist"
+	}
+	return ""
+}
+
+// VersionPair is the clean and explicit versions of the same song: Keep has the higher Elo
+// and absorbs Drop when they are merged
+type VersionPair struct {
+	Keep TrackWithRating
+	Drop TrackWithRating
+}
+
+// versionTags sont les mentions de version retirées en fin de titre, en minuscules,
+// avec la version qu'elles désignent (true = explicite)
+// La liste est volontairement courte : "Radio Edit", "Remastered" ou "Live" sont d'autres enregistrements
+var versionTags = map[string]bool{
+	"clean":            false,
+	"clean version":    false,
+	"clean edit":       false,
+	"explicit":         true,
+	"explicit version": true,
+}
+
+// maxVersionDurationGapMs est l'écart de durée maximal entre les deux versions d'un morceau
+const maxVersionDurationGapMs = 5000
+
+// splitVersionTag sépare un titre de sa mention de version finale ("Song (Clean)", "Song [Explicit]",
+// "Song - Clean Version") ; le titre est retourné en minuscules, tag vaut "" sans mention reconnue
+func splitVersionTag(name string) (base, tag string) {
+	base = strings.Join(strings.Fields(strings.ToLower(name)), " ")
+
+	for _, brackets := range [][2]string{{"(", ")"}, {"[", "]"}} {
+		if !strings.HasSuffix(base, brackets[1]) {
+			continue
+		}
+		open := strings.LastIndex(base, " "+brackets[0])
+		if open < 0 {
+			continue
+		}
+		inner := strings.TrimSpace(base[open+2 : len(base)-1])
+		if _, ok := versionTags[inner]; ok {
+			return strings.TrimSpace(base[:open]), inner
+		}
+	}
+
+	if dash := strings.LastIndex(base, " - "); dash >= 0 {
+		inner := strings.TrimSpace(base[dash+3:])
+		if _, ok := versionTags[inner]; ok {
+			return strings.TrimSpace(base[:dash]), inner
+		}
+	}
+
+	return base, ""
+}
+
+// VersionlessName retourne le titre en minuscules sans mention de version clean/explicit,
+// pour reconnaître les deux versions d'un même morceau
+func VersionlessName(name string) string {
+	base, _ := splitVersionTag(name)
+	return base
+}
+
+// IsOtherVersionOf indique si deux tracks sont les versions clean et explicite d'un même morceau :
+// même titre une fois la mention de version retirée, même artiste, un seul des deux explicite,
+// des mentions cohérentes avec le marquage Spotify et des durées quasi identiques
+func (t *Track) IsOtherVersionOf(other *Track) bool {
+	if t.ID == other.ID || t.Explicit == other.Explicit {
+		return false
+	}
+	if !strings.EqualFold(strings.TrimSpace(t.Artist), strings.TrimSpace(other.Artist)) {
+		return false
+	}
+
+	base, tag := splitVersionTag(t.Name)
+	otherBase, otherTag := splitVersionTag(other.Name)
+	if base == "" || base != otherBase {
+		return false
+	}
+	if tag != "" && versionTags[tag] != t.Explicit {
+		return false
+	}
+	if otherTag != "" && versionTags[otherTag] != other.Explicit {
+		return false
+	}
+
+	if t.DurationMs > 0 && other.DurationMs > 0 {
+		gap := t.DurationMs - other.DurationMs
+		if gap < 0 {
+			gap = -gap
+		}
+		if gap > maxVersionDurationGapMs {
+			return false
+		}
+	}
+	return true
+}
+
+// FindVersionPairs retourne les paires clean/explicite de la bibliothèque, dans l'ordre des tracks
+// Un titre présent en plus de deux exemplaires est ignoré : impossible de savoir lesquels fusionner
+func FindVersionPairs(tracks []TrackWithRating) []VersionPair {
+	groups := make(map[string][]TrackWithRating)
+	var keys []string
+	for _, track := range tracks {
+		key := strings.ToLower(strings.TrimSpace(track.Track.Artist)) + "\x00" + VersionlessName(track.Track.Name)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], track)
+	}
+
+	var pairs []VersionPair
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) != 2 || !group[0].Track.IsOtherVersionOf(&group[1].Track) {
+			continue
+		}
+
+		keep, drop := group[0], group[1]
+		if drop.Rating.Elo > keep.Rating.Elo ||
+			(drop.Rating.Elo == keep.Rating.Elo && drop.Rating.GetTotalBattles() > keep.Rating.GetTotalBattles()) {
+			keep, drop = drop, keep
+		}
+		pairs = append(pairs, VersionPair{Keep: keep, Drop: drop})
+	}
+	return pairs
+}
+
 // GetTotalBattles retourne le nombre total de duels d'un track
 func (r *Rating) GetTotalBattles() int {
 	return r.Wins + r.Losses + r.Draws
@@ -0,0 +1,38 @@
+package models
+
+import "testing"
+
+func TestNotSongReason(t *testing.T) {
+	song := Track{
+		SpotifyID:  "4uLU6hMCjMI75M1A2tKUQC",
+		Name:       "Song",
+		Artist:     "Artist",
+		SpotifyURI: "spotify:track:4uLU6hMCjMI75M1A2tKUQC",
+		DurationMs: 213000,
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*Track)
+		want   string
+	}{
+		{"song", func(*Track) {}, ""},
+		{"song without URI", func(t *Track) { t.SpotifyURI = "" }, ""},
+		{"local file", func(t *Track) { t.SpotifyID = ""; t.SpotifyURI = "spotify:local:Artist:Album:Song:213" }, "no Spotify ID (local file)"},
+		{"podcast episode", func(t *Track) { t.SpotifyURI = "spotify:episode:512ojhOuo1ktJprKbVcKyQ" }, "not a track (spotify:episode:512ojhOuo1ktJprKbVcKyQ)"},
+		{"zero duration", func(t *Track) { t.DurationMs = 0 }, "zero duration"},
+		{"negative duration", func(t *Track) { t.DurationMs = -1 }, "zero duration"},
+		{"missing artist", func(t *Track) { t.Artist = "" }, "missing artist"},
+		{"blank artist", func(t *Track) { t.Artist = "  " }, "missing artist"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			track := song
+			tt.modify(&track)
+			if got := track.NotSongReason(); got != tt.want {
+				t.Errorf("NotSongReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
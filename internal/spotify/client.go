@@ -2,7 +2,13 @@ package spotify
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"songbattle/internal/analysis"
+	"songbattle/internal/logging"
 	"songbattle/internal/models"
 	"strconv"
 	"strings"
@@ -47,13 +53,272 @@ func (c *Client) GetUserTopTracks(limit int, timeRange spotify.Range) ([]*models
 
 	tracks := make([]*models.Track, 0, len(topTracks.Tracks))
 	for _, item := range topTracks.Tracks {
-		modelTrack := c.convertFullTrack(&item)
-		tracks = append(tracks, modelTrack)
+		if modelTrack := c.convertFullTrack(&item); modelTrack != nil {
+			tracks = append(tracks, modelTrack)
+		}
+	}
+
+	return tracks, nil
+}
+
+// SearchTracks recherche des tracks par texte libre (titre, artiste...)
+func (c *Client) SearchTracks(query string, limit int) ([]*models.Track, error) {
+	result, err := c.client.Search(c.context, query, spotify.SearchTypeTrack, spotify.Limit(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Tracks == nil {
+		return []*models.Track{}, nil
+	}
+
+	tracks := make([]*models.Track, 0, len(result.Tracks.Tracks))
+	for _, item := range result.Tracks.Tracks {
+		if track := c.convertFullTrack(&item); track != nil {
+			tracks = append(tracks, track)
+		}
 	}
 
 	return tracks, nil
 }
 
+// GetTracks récupère des tracks par leurs IDs Spotify (par batches de 50)
+func (c *Client) GetTracks(trackIDs []string) ([]*models.Track, error) {
+	tracks := make([]*models.Track, 0, len(trackIDs))
+
+	batchSize := 50
+	for i := 0; i < len(trackIDs); i += batchSize {
+		end := i + batchSize
+		if end > len(trackIDs) {
+			end = len(trackIDs)
+		}
+
+		ids := make([]spotify.ID, 0, end-i)
+		for _, id := range trackIDs[i:end] {
+			ids = append(ids, spotify.ID(id))
+		}
+
+		fullTracks, err := c.client.GetTracks(c.context, ids)
+		if err != nil {
+			return tracks, err
+		}
+
+		for _, item := range fullTracks {
+			if item == nil {
+				continue // Track introuvable
+			}
+			if track := c.convertFullTrack(item); track != nil {
+				tracks = append(tracks, track)
+			}
+		}
+	}
+
+	return tracks, nil
+}
+
+// MissingTrackIDs retourne les IDs qui ne correspondent plus à aucun track Spotify (par batches de 50)
+func (c *Client) MissingTrackIDs(trackIDs []string) (map[string]bool, error) {
+	missing := make(map[string]bool)
+
+	batchSize := 50
+	for i := 0; i < len(trackIDs); i += batchSize {
+		end := i + batchSize
+		if end > len(trackIDs) {
+			end = len(trackIDs)
+		}
+
+		ids := make([]spotify.ID, 0, end-i)
+		for _, id := range trackIDs[i:end] {
+			ids = append(ids, spotify.ID(id))
+		}
+
+		fullTracks, err := c.client.GetTracks(c.context, ids)
+		if err != nil {
+			return missing, err
+		}
+
+		// Spotify retourne null, dans l'ordre demandé, pour chaque ID inconnu
+		for j, item := range fullTracks {
+			if item == nil && j < len(ids) {
+				missing[string(ids[j])] = true
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		logging.Printf("[spotify] %d of %d tracks no longer exist", len(missing), len(trackIDs))
+	}
+	return missing, nil
+}
+
+// ParseArtistID extrait l'ID d'un artiste depuis un ID brut, une URI spotify:artist:
+// ou une URL open.spotify.com/artist/
+func ParseArtistID(input string) (string, error) {
+	id, ok := parseSpotifyID(input, "artist")
+	if !ok {
+		return "", fmt.Errorf("ID d'artiste invalide: %q", id)
+	}
+	return id, nil
+}
+
+// ParseTrackID extrait l'ID d'un track depuis un ID brut, une URI spotify:track:
+// ou une URL open.spotify.com/track/
+func ParseTrackID(input string) (string, error) {
+	id, ok := parseSpotifyID(input, "track")
+	if !ok {
+		return "", fmt.Errorf("ID de track invalide: %q", id)
+	}
+	return id, nil
+}
+
+// parseSpotifyID extrait l'ID d'une ressource Spotify (artist, track...) ; ok = false si l'ID est invalide
+func parseSpotifyID(input, kind string) (string, bool) {
+	input = strings.TrimSpace(input)
+
+	if rest, ok := strings.CutPrefix(input, "spotify:"+kind+":"); ok {
+		input = rest
+	} else if idx := strings.Index(input, "/"+kind+"/"); idx >= 0 {
+		input = input[idx+len("/"+kind+"/"):]
+		if end := strings.IndexAny(input, "/?#"); end >= 0 {
+			input = input[:end]
+		}
+	}
+
+	return input, input != "" && !strings.ContainsAny(input, ":/?# ")
+}
+
+// GetArtistTopTracks récupère les titres les plus populaires d'un artiste (10 max)
+func (c *Client) GetArtistTopTracks(artistID string) ([]*models.Track, error) {
+	topTracks, err := c.client.GetArtistsTopTracks(c.context, spotify.ID(artistID), c.userCountry())
+	if err != nil {
+		return nil, err
+	}
+
+	tracks := make([]*models.Track, 0, len(topTracks))
+	for _, item := range topTracks {
+		if track := c.convertFullTrack(&item); track != nil {
+			tracks = append(tracks, track)
+		}
+	}
+
+	return tracks, nil
+}
+
+// GetArtistCatalog récupère le catalogue d'un artiste : ses top tracks puis les titres
+// de ses albums et singles, sans doublons, dans la limite de maxTracks
+// Le booléen retourné indique si le catalogue a été tronqué par la limite
+func (c *Client) GetArtistCatalog(artistID string, maxTracks int) ([]*models.Track, bool, error) {
+	tracks := make([]*models.Track, 0)
+	seenIDs := make(map[string]bool)
+	seenNames := make(map[string]bool) // Même titre sur plusieurs albums (rééditions, compilations)
+
+	add := func(track *models.Track) bool {
+		name := strings.ToLower(track.Name)
+		if seenIDs[track.SpotifyID] || seenNames[name] {
+			return true
+		}
+		if len(tracks) >= maxTracks {
+			return false
+		}
+		seenIDs[track.SpotifyID] = true
+		seenNames[name] = true
+		tracks = append(tracks, track)
+		return true
+	}
+
+	// Les top tracks d'abord : ce sont les plus pertinents si la limite est atteinte
+	topTracks, err := c.GetArtistTopTracks(artistID)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, track := range topTracks {
+		if !add(track) {
+			return tracks, true, nil
+		}
+	}
+
+	albums, err := c.client.GetArtistAlbums(c.context, spotify.ID(artistID),
+		[]spotify.AlbumType{spotify.AlbumTypeAlbum, spotify.AlbumTypeSingle}, spotify.Limit(50))
+	if err != nil {
+		return tracks, false, err
+	}
+
+	for {
+		for _, album := range albums.Albums {
+			albumTracks, err := c.getAlbumTracks(album, artistID)
+			if err != nil {
+				return tracks, false, err
+			}
+			for _, track := range albumTracks {
+				if !add(track) {
+					return tracks, true, nil
+				}
+			}
+		}
+
+		if err := c.client.NextPage(c.context, albums); err == spotify.ErrNoMorePages {
+			break
+		} else if err != nil {
+			return tracks, false, err
+		}
+	}
+
+	return tracks, false, nil
+}
+
+// getAlbumTracks récupère les titres d'un album sur lesquels l'artiste apparaît
+func (c *Client) getAlbumTracks(album spotify.SimpleAlbum, artistID string) ([]*models.Track, error) {
+	page, err := c.client.GetAlbumTracks(c.context, album.ID, spotify.Limit(50))
+	if err != nil {
+		return nil, err
+	}
+
+	year, _ := c.parseYear(album.ReleaseDate)
+
+	tracks := make([]*models.Track, 0, len(page.Tracks))
+	for {
+		for _, item := range page.Tracks {
+			if !hasArtist(item.Artists, artistID) {
+				continue
+			}
+			track := c.convertSimpleTrack(&item)
+			if track == nil {
+				continue
+			}
+			track.Album = album.Name
+			track.Year = year
+			tracks = append(tracks, track)
+		}
+
+		if err := c.client.NextPage(c.context, page); err == spotify.ErrNoMorePages {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	return tracks, nil
+}
+
+// hasArtist vérifie qu'un artiste fait partie des interprètes d'un track
+func hasArtist(artists []spotify.SimpleArtist, artistID string) bool {
+	for _, artist := range artists {
+		if string(artist.ID) == artistID {
+			return true
+		}
+	}
+	return false
+}
+
+// userCountry retourne le pays du compte Spotify (US par défaut)
+func (c *Client) userCountry() string {
+	user, err := c.GetCurrentUser()
+	if err != nil || user.Country == "" {
+		return "US"
+	}
+	return user.Country
+}
+
 // GetRecommendations récupère des recommandations
 func (c *Client) GetRecommendations(seedTracks, seedArtists, seedGenres []string, limit int) ([]*models.Track, error) {
 	seeds := spotify.Seeds{}
@@ -76,15 +341,33 @@ func (c *Client) GetRecommendations(seedTracks, seedArtists, seedGenres []string
 
 	tracks := make([]*models.Track, 0, len(recommendations.Tracks))
 	for _, track := range recommendations.Tracks {
-		modelTrack := c.convertSimpleTrack(&track)
-		tracks = append(tracks, modelTrack)
+		if modelTrack := c.convertSimpleTrack(&track); modelTrack != nil {
+			tracks = append(tracks, modelTrack)
+		}
 	}
 
 	return tracks, nil
 }
 
 // GetAudioFeatures récupère les caractéristiques audio d'un track
+// Si l'API les refuse (403), un profil grossier (énergie, tempo) est estimé à partir de l'extrait
+// de 30 secondes du track ; sans extrait, l'erreur de l'API est retournée
 func (c *Client) GetAudioFeatures(trackID string) (*models.AudioFeatures, error) {
+	features, err := c.apiAudioFeatures(trackID)
+	if err == nil || !isForbidden(err) {
+		return features, err
+	}
+
+	estimated, previewErr := c.previewAudioFeatures(trackID)
+	if previewErr != nil {
+		logging.Printf("[spotify] audio features %s: %v, estimation impossible: %v", trackID, err, previewErr)
+		return nil, err
+	}
+	return estimated, nil
+}
+
+// apiAudioFeatures récupère les caractéristiques audio d'un track auprès de l'API
+func (c *Client) apiAudioFeatures(trackID string) (*models.AudioFeatures, error) {
 	af, err := c.client.GetAudioFeatures(c.context, spotify.ID(trackID))
 	if err != nil {
 		return nil, err
@@ -111,21 +394,115 @@ func (c *Client) GetAudioFeatures(trackID string) (*models.AudioFeatures, error)
 	}, nil
 }
 
+// Téléchargement des extraits pour l'estimation des audio features
+const (
+	previewTimeout  = 10 * time.Second
+	maxPreviewBytes = 2 << 20 // Un extrait de 30 secondes pèse quelques centaines de Ko
+)
+
+// ErrNoPreview signale un track sans extrait audio
+var ErrNoPreview = errors.New("aucun extrait audio pour ce track")
+
+// previewAudioFeatures estime les caractéristiques audio d'un track à partir de son extrait
+func (c *Client) previewAudioFeatures(trackID string) (*models.AudioFeatures, error) {
+	track, err := c.client.GetTrack(c.context, spotify.ID(trackID))
+	if err != nil {
+		return nil, err
+	}
+	if track.PreviewURL == "" {
+		return nil, ErrNoPreview
+	}
+
+	data, err := downloadPreview(c.context, track.PreviewURL)
+	if err != nil {
+		return nil, err
+	}
+
+	features, err := analysis.PreviewFeatures(data)
+	if err != nil {
+		return nil, err
+	}
+	return &features, nil
+}
+
+// downloadPreview télécharge un extrait audio, en limitant sa durée et sa taille
+func downloadPreview(ctx context.Context, url string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, previewTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("téléchargement de l'extrait: HTTP %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxPreviewBytes))
+}
+
 // PlayTrack joue un track sur l'appareil actif
 func (c *Client) PlayTrack(uri string) error {
+	return c.PlayTrackAt(uri, 0)
+}
+
+// PlayTrackAt joue un track à partir d'une position donnée
+func (c *Client) PlayTrackAt(uri string, position time.Duration) error {
 	uris := []spotify.URI{spotify.URI(uri)}
 
 	playOptions := &spotify.PlayOptions{
-		URIs: uris,
+		URIs:       uris,
+		PositionMs: spotify.Numeric(position.Milliseconds()),
+	}
+
+	err := c.client.PlayOpt(c.context, playOptions)
+	if err != nil {
+		logging.Printf("[spotify] play %s at %s: %v", uri, position, err)
+	}
+	return err
+}
+
+// Pause met la lecture en pause
+func (c *Client) Pause() error {
+	return c.client.Pause(c.context)
+}
+
+// IsPlaying indique si le track d'URI donnée est en cours de lecture
+// (y compris sous un ID relinké par Spotify)
+func (c *Client) IsPlaying(uri string) (bool, error) {
+	current, err := c.client.PlayerCurrentlyPlaying(c.context)
+	if err != nil {
+		return false, err
+	}
+	if current == nil || !current.Playing || current.Item == nil {
+		return false, nil
 	}
 
-	return c.client.PlayOpt(c.context, playOptions)
+	if string(current.Item.URI) == uri {
+		return true, nil
+	}
+	linked := current.Item.LinkedFrom
+	return linked != nil && "spotify:track:"+string(linked.ID) == uri, nil
 }
 
+// ErrMissingScope signale un token autorisé avec un ancien jeu de permissions
+var ErrMissingScope = errors.New("le token Spotify n'a pas les permissions requises")
+
 // CreatePlaylist crée une nouvelle playlist
-func (c *Client) CreatePlaylist(userID, name, description string) (*spotify.FullPlaylist, error) {
-	public := false
+// Retourne ErrMissingScope si le token ne permet pas de créer de playlist
+func (c *Client) CreatePlaylist(userID, name, description string, public bool) (*spotify.FullPlaylist, error) {
 	playlist, err := c.client.CreatePlaylistForUser(c.context, userID, name, description, public, false)
+	if err != nil {
+		logging.Printf("[spotify] create playlist %q (public=%t): %v", name, public, err)
+	}
+	if err != nil && IsMissingScope(err) {
+		return nil, fmt.Errorf("%w (%v)", ErrMissingScope, err)
+	}
 	return playlist, err
 }
 
@@ -142,31 +519,154 @@ func (c *Client) AddTracksToPlaylist(playlistID string, trackURIs []string) erro
 	}
 
 	_, err := c.client.AddTracksToPlaylist(c.context, spotify.ID(playlistID), uris...)
+	if err != nil {
+		logging.Printf("[spotify] add %d tracks to playlist %s: %v", len(uris), playlistID, err)
+	}
 	return err
 }
 
-// EnrichTrackWithAudioFeatures enrichit un track avec ses caractéristiques audio
+// EnrichTrackWithAudioFeatures enrichit un track avec ses caractéristiques audio et l'humeur qui en découle
+// Seule l'API est interrogée : un profil estimé n'a pas de valence et fausserait l'humeur,
+// et télécharger un extrait par track ralentirait les imports
 func (c *Client) EnrichTrackWithAudioFeatures(track *models.Track) error {
-	features, err := c.GetAudioFeatures(track.SpotifyID)
+	features, err := c.apiAudioFeatures(track.SpotifyID)
 	if err != nil {
-		// Ne pas échouer si les audio features ne sont pas disponibles
+		// Ne pas échouer si les audio features ne sont pas disponibles,
+		// sauf en cas de rate limit pour pouvoir réessayer plus tard
+		if IsRateLimited(err) {
+			return err
+		}
 		return nil
 	}
 
 	track.AudioFeaturesJSON = *features
+	track.Mood = analysis.Mood(*features)
 	return nil
 }
 
+// IsRateLimited indique si une erreur de l'API Spotify est due au rate limit (HTTP 429)
+func IsRateLimited(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var apiErr spotify.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Status == http.StatusTooManyRequests
+	}
+
+	// Réponse sans body : le client retourne une erreur générique
+	return strings.Contains(err.Error(), fmt.Sprintf("HTTP %d", http.StatusTooManyRequests))
+}
+
+// IsNotFound indique si une erreur de l'API Spotify signale un track introuvable ou indisponible
+func IsNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var apiErr spotify.Error
+	if errors.As(err, &apiErr) {
+		if apiErr.Status == http.StatusNotFound {
+			return true
+		}
+		message := strings.ToLower(apiErr.Message)
+		return apiErr.Status == http.StatusBadRequest &&
+			(strings.Contains(message, "invalid") || strings.Contains(message, "not found"))
+	}
+
+	return false
+}
+
+// isForbidden indique si l'API a refusé la requête (HTTP 403)
+func isForbidden(err error) bool {
+	var apiErr spotify.Error
+	return errors.As(err, &apiErr) && apiErr.Status == http.StatusForbidden
+}
+
+// IsMissingScope indique si une erreur signale un scope OAuth manquant
+// (403 de l'API, hors restriction Premium)
+func IsMissingScope(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrMissingScope) {
+		return true
+	}
+
+	var apiErr spotify.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Status == http.StatusForbidden &&
+			!strings.Contains(strings.ToLower(apiErr.Message), "premium")
+	}
+
+	return false
+}
+
+// apiHost est l'adresse de l'API Web Spotify, utilisée pour tester la connexion
+const apiHost = "api.spotify.com:443"
+
+// IsOnline vérifie que l'API Spotify est joignable
+func IsOnline(timeout time.Duration) bool {
+	conn, err := net.DialTimeout("tcp", apiHost, timeout)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// IsNetworkError indique si une erreur vient d'un problème de connexion
+func IsNetworkError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// RelinkTrack retrouve l'ID Spotify actuel d'un track via son ISRC
+// Met à jour SpotifyID et SpotifyURI et retourne true si l'ID a changé
+func (c *Client) RelinkTrack(track *models.Track) (bool, error) {
+	if track.ISRC == "" {
+		return false, fmt.Errorf("ISRC inconnu pour %s", track.Name)
+	}
+
+	result, err := c.client.Search(c.context, "isrc:"+track.ISRC, spotify.SearchTypeTrack, spotify.Limit(1))
+	if err != nil {
+		return false, err
+	}
+
+	if result.Tracks == nil || len(result.Tracks.Tracks) == 0 {
+		return false, fmt.Errorf("aucun track trouvé pour l'ISRC %s", track.ISRC)
+	}
+
+	current := result.Tracks.Tracks[0]
+	if string(current.ID) == track.SpotifyID {
+		return false, nil
+	}
+
+	logging.Printf("[spotify] relinked %s (ISRC %s): %s -> %s", track.Name, track.ISRC, track.SpotifyID, current.ID)
+	track.SpotifyID = string(current.ID)
+	track.SpotifyURI = string(current.URI)
+	return true, nil
+}
+
 // Fonctions de conversion
 
 // convertFullTrack convertit un FullTrack Spotify en model Track
+// Retourne nil pour les items qui ne sont pas des chansons (voir Track.NotSongReason)
 func (c *Client) convertFullTrack(track *spotify.FullTrack) *models.Track {
+	if !isTrackType(track.Type) {
+		logging.Printf("[spotify] skipped %q: not a track (type %s)", track.Name, track.Type)
+		return nil
+	}
+
 	modelTrack := &models.Track{
 		SpotifyID:  string(track.ID),
 		Name:       track.Name,
 		Artist:     c.joinArtists(track.Artists),
 		Album:      track.Album.Name,
 		SpotifyURI: string(track.URI),
+		Explicit:   track.Explicit,
+		DurationMs: int(track.Duration),
 		CreatedAt:  time.Now(),
 	}
 
@@ -175,6 +675,12 @@ func (c *Client) convertFullTrack(track *spotify.FullTrack) *models.Track {
 		modelTrack.PreviewURL = &track.PreviewURL
 	}
 
+	// ISRC (identifiant stable de l'enregistrement, utilisé pour le relinking)
+	modelTrack.ISRC = track.ExternalIDs["isrc"]
+
+	// Popularité Spotify (0-100)
+	modelTrack.Popularity = int(track.Popularity)
+
 	// Année de sortie
 	if track.Album.ReleaseDate != "" {
 		if year, err := c.parseYear(track.Album.ReleaseDate); err == nil {
@@ -185,16 +691,25 @@ func (c *Client) convertFullTrack(track *spotify.FullTrack) *models.Track {
 	// Genres (généralement vides pour les tracks, disponibles pour les artistes)
 	modelTrack.GenresJSON = make(models.Genres, 0)
 
-	return modelTrack
+	return songOrNil(modelTrack)
 }
 
 // convertSimpleTrack convertit un SimpleTrack Spotify en model Track
+// Retourne nil pour les items qui ne sont pas des chansons (voir Track.NotSongReason)
 func (c *Client) convertSimpleTrack(track *spotify.SimpleTrack) *models.Track {
+	if !isTrackType(track.Type) {
+		logging.Printf("[spotify] skipped %q: not a track (type %s)", track.Name, track.Type)
+		return nil
+	}
+
 	modelTrack := &models.Track{
 		SpotifyID:  string(track.ID),
 		Name:       track.Name,
 		Artist:     c.joinArtists(track.Artists),
 		SpotifyURI: string(track.URI),
+		Popularity: -1, // Non fournie pour les SimpleTrack
+		Explicit:   track.Explicit,
+		DurationMs: int(track.Duration),
 		CreatedAt:  time.Now(),
 	}
 
@@ -203,10 +718,27 @@ func (c *Client) convertSimpleTrack(track *spotify.SimpleTrack) *models.Track {
 		modelTrack.PreviewURL = &track.PreviewURL
 	}
 
+	// ISRC (absent de certaines réponses, comme les tracks d'album)
+	modelTrack.ISRC = track.ExternalIDs.ISRC
+
 	// Genres
 	modelTrack.GenresJSON = make(models.Genres, 0)
 
-	return modelTrack
+	return songOrNil(modelTrack)
+}
+
+// isTrackType vérifie le type d'un item Spotify (absent de certaines réponses)
+func isTrackType(itemType string) bool {
+	return itemType == "" || itemType == "track"
+}
+
+// songOrNil écarte, en le traçant, un item converti qui n'est pas une chanson
+func songOrNil(track *models.Track) *models.Track {
+	if reason := track.NotSongReason(); reason != "" {
+		logging.Printf("[spotify] skipped %q (%s): %s", track.Name, track.SpotifyID, reason)
+		return nil
+	}
+	return track
 }
 
 // joinArtists joint les noms des artistes
@@ -0,0 +1,58 @@
+package spotify
+
+import (
+	"testing"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+func TestConvertTracks(t *testing.T) {
+	simple := func(itemType, id, uri string, duration int, artists ...string) spotify.SimpleTrack {
+		track := spotify.SimpleTrack{
+			ID:       spotify.ID(id),
+			Name:     "Item",
+			URI:      spotify.URI(uri),
+			Duration: spotify.Numeric(duration),
+			Type:     itemType,
+		}
+		for _, name := range artists {
+			track.Artists = append(track.Artists, spotify.SimpleArtist{Name: name})
+		}
+		return track
+	}
+
+	tests := []struct {
+		name  string
+		track spotify.SimpleTrack
+		want  bool // Converti (chanson) ou écarté
+	}{
+		{"song", simple("track", "id1", "spotify:track:id1", 213000, "Artist"), true},
+		{"song without type", simple("", "id1", "spotify:track:id1", 213000, "Artist"), true},
+		{"episode type", simple("episode", "id2", "spotify:episode:id2", 1800000, "Show"), false},
+		{"episode URI", simple("track", "id2", "spotify:episode:id2", 1800000, "Show"), false},
+		{"local file", simple("track", "", "spotify:local:Artist:Album:Item:213", 213000, "Artist"), false},
+		{"zero duration", simple("track", "id3", "spotify:track:id3", 0, "Artist"), false},
+		{"no artist", simple("track", "id4", "spotify:track:id4", 213000), false},
+	}
+
+	c := &Client{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			track := tt.track
+			if got := c.convertSimpleTrack(&track); (got != nil) != tt.want {
+				t.Errorf("convertSimpleTrack kept = %v, want %v", got != nil, tt.want)
+			}
+
+			full := spotify.FullTrack{SimpleTrack: tt.track, Popularity: 50}
+			full.Album.ReleaseDate = "2019-05-17"
+			full.ExternalIDs = map[string]string{"isrc": "FRZ039800212"}
+			got := c.convertFullTrack(&full)
+			if (got != nil) != tt.want {
+				t.Fatalf("convertFullTrack kept = %v, want %v", got != nil, tt.want)
+			}
+			if got != nil && (got.ISRC != "FRZ039800212" || got.Year != 2019 || got.Popularity != 50 || got.Artist != "Artist") {
+				t.Errorf("convertFullTrack = %+v, want ISRC, year, popularity and artist copied", got)
+			}
+		})
+	}
+}
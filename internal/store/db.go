@@ -2,10 +2,12 @@ package store
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
-	"log"
 	"path/filepath"
+	"songbattle/internal/logging"
 	"songbattle/internal/models"
+	"strconv"
 	"time"
 
 	_ "modernc.org/sqlite"
@@ -15,6 +17,17 @@ type DB struct {
 	*sql.DB
 }
 
+// querier est implémenté par *sql.DB et *sql.Tx
+type querier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// Tx expose les opérations d'un duel au sein d'une transaction
+type Tx struct {
+	tx *sql.Tx
+}
+
 // NewDB initializes database connection and runs migrations
 func NewDB(dbPath string) (*DB, error) {
 	// Create parent directory if needed
@@ -24,7 +37,9 @@ func NewDB(dbPath string) (*DB, error) {
 		// os.MkdirAll(dir, 0755)
 	}
 
-	db, err := sql.Open("sqlite", dbPath+"?_foreign_keys=on")
+	// Transactions IMMEDIATE : le verrou d'écriture est pris dès BEGIN, ce qui évite
+	// les interblocages lecture→écriture ; busy_timeout fait patienter les écrivains concurrents
+	db, err := sql.Open("sqlite", dbPath+"?_foreign_keys=on&_txlock=immediate&_pragma=busy_timeout(5000)")
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -41,7 +56,15 @@ func NewDB(dbPath string) (*DB, error) {
 		return nil, fmt.Errorf("migration failed: %w", err)
 	}
 
-	log.Println("Database initialized successfully")
+	// Un track sans rating (transaction interrompue, bug de migration) serait écarté des
+	// jointures : lui redonner le rating initial plutôt que de le cacher
+	if created, err := insertMissingRatings(store.DB); err != nil {
+		logging.Printf("[store] creating missing ratings failed: %v", err)
+	} else if created > 0 {
+		logging.Printf("[store] %d missing rating(s) created", created)
+	}
+
+	logging.Printf("[store] database %s initialized", dbPath)
 	return store, nil
 }
 
@@ -88,9 +111,23 @@ func (db *DB) migrate() error {
 			value TEXT NOT NULL
 		)`,
 
+		// Historique des Elo : une ligne par track et par duel
+		// created_at est un timestamp Unix pour des comparaisons de dates fiables
+		`CREATE TABLE IF NOT EXISTS elo_history (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			track_id INTEGER NOT NULL,
+			duel_id INTEGER,
+			old_elo INTEGER NOT NULL,
+			new_elo INTEGER NOT NULL,
+			created_at INTEGER NOT NULL,
+			FOREIGN KEY (track_id) REFERENCES tracks(id) ON DELETE CASCADE,
+			FOREIGN KEY (duel_id) REFERENCES duels(id) ON DELETE CASCADE
+		)`,
+
 		`CREATE INDEX IF NOT EXISTS idx_tracks_spotify_id ON tracks(spotify_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_ratings_elo ON ratings(elo DESC)`,
 		`CREATE INDEX IF NOT EXISTS idx_duels_created_at ON duels(created_at DESC)`,
+		`CREATE INDEX IF NOT EXISTS idx_elo_history_track ON elo_history(track_id, created_at)`,
 	}
 
 	for _, migration := range migrations {
@@ -99,12 +136,185 @@ func (db *DB) migrate() error {
 		}
 	}
 
+	// Colonnes ajoutées après la création initiale du schéma
+	// backfill initialise la colonne à partir des données existantes quand elle vient d'être ajoutée
+	columns := []struct{ table, column, definition, backfill string }{
+		{"tracks", "isrc", "TEXT NOT NULL DEFAULT ''", ""},
+		{"tracks", "popularity", "INTEGER NOT NULL DEFAULT -1", ""},
+		{"tracks", "explicit", "BOOLEAN NOT NULL DEFAULT 0", ""},
+		{"tracks", "duration_ms", "INTEGER NOT NULL DEFAULT 0", ""},
+		{"tracks", "unavailable", "BOOLEAN NOT NULL DEFAULT 0", ""},
+		{"tracks", "unavailable_at", "DATETIME", ""},
+		{"tracks", "source", "TEXT NOT NULL DEFAULT ''", ""},
+		{"tracks", "mood", "TEXT NOT NULL DEFAULT ''", ""},
+		{"duels", "left_score", "REAL", ""},
+		{"duels", "confidence", "REAL", ""},
+		// Résultat du duel ; avant cette colonne, un duel sans vainqueur était un nul s'il avait
+		// modifié l'Elo (elo_history), un skip sinon (voir aussi backfillLegacyDraws)
+		{"duels", "result", "TEXT NOT NULL DEFAULT ''", `
+			UPDATE duels SET result = CASE
+				WHEN winner_track_id = left_track_id THEN 'left'
+				WHEN winner_track_id = right_track_id THEN 'right'
+				WHEN EXISTS (SELECT 1 FROM elo_history h WHERE h.duel_id = duels.id) THEN 'draw'
+				ELSE 'skip'
+			END`},
+		{"ratings", "appearances", "INTEGER NOT NULL DEFAULT 0", `
+			UPDATE ratings SET appearances = (
+				SELECT COUNT(*) FROM duels d WHERE d.left_track_id = ratings.track_id OR d.right_track_id = ratings.track_id)`},
+		{"ratings", "skips", "INTEGER NOT NULL DEFAULT 0", `
+			UPDATE ratings SET skips = (
+				SELECT COUNT(*) FROM duels d
+				WHERE (d.left_track_id = ratings.track_id OR d.right_track_id = ratings.track_id)
+				  AND d.result = 'skip')`},
+		// Écart type estimé d'après le nombre de duels : chacun apporte q²/4 d'information, q = ln(10)/400
+		{"ratings", "rd", "REAL NOT NULL DEFAULT 350", `
+			UPDATE ratings SET rd = MAX(30.0, 1.0 / sqrt(
+				1.0 / (350.0 * 350.0) + (wins + losses + draws) * (ln(10.0) / 400) * (ln(10.0) / 400) / 4))`},
+		{"elo_history", "old_rd", "REAL", ""},
+		{"elo_history", "new_rd", "REAL", ""},
+		{"elo_history", "old_last_seen_at", "INTEGER", ""},
+	}
+
+	// Initialisations qui ne tiennent pas en une requête, lancées juste après le backfill SQL
+	fixups := map[string]func() error{
+		"duels.result": db.backfillLegacyDraws,
+	}
+
+	for _, c := range columns {
+		added, err := db.addColumnIfMissing(c.table, c.column, c.definition)
+		if err != nil {
+			return fmt.Errorf("erreur ajout colonne %s.%s: %w", c.table, c.column, err)
+		}
+		if added && c.backfill != "" {
+			if _, err := db.Exec(c.backfill); err != nil {
+				return fmt.Errorf("erreur initialisation colonne %s.%s: %w", c.table, c.column, err)
+			}
+		}
+		if fixup := fixups[c.table+"."+c.column]; added && fixup != nil {
+			if err := fixup(); err != nil {
+				return fmt.Errorf("erreur initialisation colonne %s.%s: %w", c.table, c.column, err)
+			}
+		}
+	}
+
+	// Index dépendant des colonnes ajoutées
+	indexes := []string{
+		`CREATE INDEX IF NOT EXISTS idx_tracks_isrc ON tracks(isrc)`,
+	}
+
+	for _, index := range indexes {
+		if _, err := db.Exec(index); err != nil {
+			return fmt.Errorf("erreur création index: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// backfillLegacyDraws retrouve les nuls enregistrés avant l'historique des Elo : sans
+// historique, rien ne les distingue d'un skip dans duels. Le compteur draws de chaque track
+// dit combien de nuls lui restent à attribuer ; les duels sans vainqueur les plus anciens
+// sont marqués nuls tant que leurs deux tracks en ont encore
+func (db *DB) backfillLegacyDraws() error {
+	rows, err := db.Query(`
+		SELECT r.track_id, r.draws - (
+			SELECT COUNT(*) FROM duels d
+			WHERE d.result = 'draw' AND (d.left_track_id = r.track_id OR d.right_track_id = r.track_id))
+		FROM ratings r`)
+	if err != nil {
+		return err
+	}
+	remaining := make(map[int64]int)
+	for rows.Next() {
+		var trackID int64
+		var draws int
+		if err := rows.Scan(&trackID, &draws); err != nil {
+			rows.Close()
+			return err
+		}
+		if draws > 0 {
+			remaining[trackID] = draws
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || len(remaining) == 0 {
+		return err
+	}
+
+	rows, err = db.Query(`SELECT id, left_track_id, right_track_id FROM duels WHERE result = 'skip' ORDER BY created_at, id`)
+	if err != nil {
+		return err
+	}
+	var draws []int64
+	for rows.Next() {
+		var id, left, right int64
+		if err := rows.Scan(&id, &left, &right); err != nil {
+			rows.Close()
+			return err
+		}
+		if remaining[left] > 0 && remaining[right] > 0 {
+			remaining[left]--
+			remaining[right]--
+			draws = append(draws, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range draws {
+		if _, err := db.Exec(`UPDATE duels SET result = 'draw' WHERE id = ?`, id); err != nil {
+			return err
+		}
+	}
+	if len(draws) > 0 {
+		logging.Printf("[store] %d legacy draw(s) recovered from the draw counters", len(draws))
+	}
 	return nil
 }
 
+// addColumnIfMissing ajoute une colonne à une table si elle n'existe pas encore
+// et indique si elle vient d'être ajoutée
+func (db *DB) addColumnIfMissing(table, column, definition string) (bool, error) {
+	rows, err := db.Query(fmt.Sprintf(`PRAGMA table_info(%s)`, table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return false, err
+		}
+		if name == column {
+			return false, nil // Déjà présente
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+	rows.Close()
+
+	if _, err := db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, definition)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // === TRACKS ===
 
 // CreateTrack insère un nouveau track et son rating initial
+// Si le spotify_id existe déjà (import concurrent), l'insertion est ignorée :
+// track.ID reçoit l'ID existant et aucun rating n'est créé
 func (db *DB) CreateTrack(track *models.Track) error {
 	tx, err := db.Begin()
 	if err != nil {
@@ -114,14 +324,25 @@ func (db *DB) CreateTrack(track *models.Track) error {
 
 	// Insérer le track
 	result, err := tx.Exec(`
-		INSERT INTO tracks (spotify_id, name, artist, album, year, genres_json, spotify_uri, preview_url, audio_features_json)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		INSERT INTO tracks (spotify_id, name, artist, album, year, genres_json, spotify_uri, preview_url, audio_features_json, isrc, popularity, explicit, duration_ms, source, mood)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(spotify_id) DO NOTHING`,
 		track.SpotifyID, track.Name, track.Artist, track.Album, track.Year,
-		track.GenresJSON, track.SpotifyURI, track.PreviewURL, track.AudioFeaturesJSON)
+		track.GenresJSON, track.SpotifyURI, track.PreviewURL, track.AudioFeaturesJSON, track.ISRC, track.Popularity, track.Explicit, track.DurationMs,
+		track.Source, track.Mood)
 	if err != nil {
 		return err
 	}
 
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if inserted == 0 {
+		// Déjà présent : reprendre l'ID existant
+		return tx.QueryRow(`SELECT id FROM tracks WHERE spotify_id = ?`, track.SpotifyID).Scan(&track.ID)
+	}
+
 	trackID, err := result.LastInsertId()
 	if err != nil {
 		return err
@@ -140,49 +361,186 @@ func (db *DB) CreateTrack(track *models.Track) error {
 	return tx.Commit()
 }
 
+// UpdateTrackSpotifyID remplace l'ID et l'URI Spotify d'un track (relinking)
+func (db *DB) UpdateTrackSpotifyID(trackID int64, spotifyID, spotifyURI string) error {
+	_, err := db.Exec(`
+		UPDATE tracks SET spotify_id = ?, spotify_uri = ? WHERE id = ?`,
+		spotifyID, spotifyURI, trackID)
+	return err
+}
+
+// MarkTrackUnavailable signale un track retiré du catalogue Spotify (lecture et export impossibles)
+// La date du premier signalement est conservée
+func (db *DB) MarkTrackUnavailable(id int64) error {
+	_, err := db.Exec(`
+		UPDATE tracks SET unavailable = 1, unavailable_at = COALESCE(unavailable_at, ?)
+		WHERE id = ?`, time.Now(), id)
+	return err
+}
+
+// MarkTrackAvailable retire le signalement d'un track de nouveau lisible (relinké par exemple)
+func (db *DB) MarkTrackAvailable(id int64) error {
+	_, err := db.Exec(`
+		UPDATE tracks SET unavailable = 0, unavailable_at = NULL
+		WHERE id = ?`, id)
+	return err
+}
+
+// GetUnavailableTracks récupère les tracks signalés indisponibles, du plus ancien signalement au plus récent
+func (db *DB) GetUnavailableTracks() ([]models.TrackWithRating, error) {
+	return db.queryTracksWithRatings(`
+		SELECT` + trackWithRatingColumns + `
+		FROM tracks t
+		JOIN ratings r ON t.id = r.track_id
+		WHERE t.unavailable = 1
+		ORDER BY t.unavailable_at ASC, t.id ASC`)
+}
+
+// DeleteUnavailableTracks supprime les tracks signalés indisponibles et retourne leur nombre
+// Les duels et l'historique Elo qui les concernent sont supprimés avec eux
+func (db *DB) DeleteUnavailableTracks() (int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// Suppressions explicites : les clés étrangères ne sont pas garanties actives
+	const unavailableIDs = `SELECT id FROM tracks WHERE unavailable = 1`
+	cleanups := []string{
+		`DELETE FROM elo_history WHERE track_id IN (` + unavailableIDs + `)
+			OR duel_id IN (SELECT id FROM duels WHERE left_track_id IN (` + unavailableIDs + `) OR right_track_id IN (` + unavailableIDs + `))`,
+		`DELETE FROM duels WHERE left_track_id IN (` + unavailableIDs + `) OR right_track_id IN (` + unavailableIDs + `)`,
+		`DELETE FROM ratings WHERE track_id IN (` + unavailableIDs + `)`,
+	}
+	for _, cleanup := range cleanups {
+		if _, err := tx.Exec(cleanup); err != nil {
+			return 0, err
+		}
+	}
+
+	result, err := tx.Exec(`DELETE FROM tracks WHERE unavailable = 1`)
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, tx.Commit()
+}
+
+// trackColumns liste les colonnes de tracks (alias t) lues par trackScanDest
+const trackColumns = `
+	t.id, t.spotify_id, t.name, t.artist, t.album, t.year, t.genres_json, t.spotify_uri, t.preview_url, t.audio_features_json, t.created_at,
+	t.isrc, t.popularity, t.explicit, t.duration_ms, t.unavailable, t.unavailable_at, t.source, t.mood`
+
+// ratingColumns liste les colonnes de ratings (alias r) lues par ratingScanDest
+const ratingColumns = `
+	r.track_id, r.elo, r.wins, r.losses, r.draws, r.last_seen_at, r.appearances, r.skips, r.rd`
+
+// trackWithRatingColumns liste les colonnes lues par scanTrackWithRating
+const trackWithRatingColumns = trackColumns + `,` + ratingColumns
+
+// baselineRatingColumns remplace ratingColumns dans une jointure externe (LEFT JOIN ratings) :
+// un track sans rating est lu avec le rating initial, jamais vu, plutôt que d'être écarté
+const baselineRatingColumns = `
+	t.id, COALESCE(r.elo, 1200), COALESCE(r.wins, 0), COALESCE(r.losses, 0), COALESCE(r.draws, 0), r.last_seen_at,
+	COALESCE(r.appearances, 0), COALESCE(r.skips, 0), COALESCE(r.rd, 350)`
+
+// trackScanDest retourne les destinations de Scan correspondant à trackColumns
+func trackScanDest(track *models.Track) []interface{} {
+	return []interface{}{
+		&track.ID, &track.SpotifyID, &track.Name, &track.Artist, &track.Album, &track.Year,
+		&track.GenresJSON, &track.SpotifyURI, &track.PreviewURL, &track.AudioFeaturesJSON, &track.CreatedAt,
+		&track.ISRC, &track.Popularity, &track.Explicit, &track.DurationMs, &track.Unavailable, &track.UnavailableAt,
+		&track.Source, &track.Mood,
+	}
+}
+
+// ratingScanDest retourne les destinations de Scan correspondant à ratingColumns
+func ratingScanDest(rating *models.Rating) []interface{} {
+	return []interface{}{
+		&rating.TrackID, &rating.Elo, &rating.Wins, &rating.Losses, &rating.Draws, (*nullableTime)(&rating.LastSeenAt),
+		&rating.Appearances, &rating.Skips, &rating.RD,
+	}
+}
+
+// nullableTime lit une date pouvant valoir NULL (rating absent d'une jointure externe),
+// auquel cas la date reste nulle
+type nullableTime time.Time
+
+// Scan implémente sql.Scanner
+func (n *nullableTime) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*n = nullableTime{}
+	case time.Time:
+		*n = nullableTime(v)
+	default:
+		return fmt.Errorf("date invalide: %T", value)
+	}
+	return nil
+}
+
 // GetTrackBySpotifyID récupère un track par son ID Spotify
 func (db *DB) GetTrackBySpotifyID(spotifyID string) (*models.Track, error) {
 	var track models.Track
 	err := db.QueryRow(`
-		SELECT id, spotify_id, name, artist, album, year, genres_json, spotify_uri, preview_url, audio_features_json, created_at
-		FROM tracks WHERE spotify_id = ?`, spotifyID).Scan(
-		&track.ID, &track.SpotifyID, &track.Name, &track.Artist, &track.Album, &track.Year,
-		&track.GenresJSON, &track.SpotifyURI, &track.PreviewURL, &track.AudioFeaturesJSON, &track.CreatedAt)
+		SELECT`+trackColumns+`
+		FROM tracks t WHERE t.spotify_id = ?`, spotifyID).Scan(trackScanDest(&track)...)
 	if err != nil {
 		return nil, err
 	}
 	return &track, nil
 }
 
-// GetTrackWithRating récupère un track avec son rating
-func (db *DB) GetTrackWithRating(trackID int64) (*models.TrackWithRating, error) {
+// GetTrackByISRC récupère un track par son ISRC (même enregistrement, autre version Spotify)
+func (db *DB) GetTrackByISRC(isrc string) (*models.Track, error) {
 	var track models.Track
-	var rating models.Rating
-
 	err := db.QueryRow(`
-		SELECT t.id, t.spotify_id, t.name, t.artist, t.album, t.year, t.genres_json, t.spotify_uri, t.preview_url, t.audio_features_json, t.created_at,
-		       r.track_id, r.elo, r.wins, r.losses, r.draws, r.last_seen_at
-		FROM tracks t
-		JOIN ratings r ON t.id = r.track_id
-		WHERE t.id = ?`, trackID).Scan(
-		&track.ID, &track.SpotifyID, &track.Name, &track.Artist, &track.Album, &track.Year,
-		&track.GenresJSON, &track.SpotifyURI, &track.PreviewURL, &track.AudioFeaturesJSON, &track.CreatedAt,
-		&rating.TrackID, &rating.Elo, &rating.Wins, &rating.Losses, &rating.Draws, &rating.LastSeenAt)
+		SELECT`+trackColumns+`
+		FROM tracks t WHERE t.isrc = ? AND t.isrc != ''
+		LIMIT 1`, isrc).Scan(trackScanDest(&track)...)
 	if err != nil {
 		return nil, err
 	}
+	return &track, nil
+}
+
+// FindExistingTrack retourne le track de la bibliothèque correspondant à track : même ID
+// Spotify, ou même ISRC (même enregistrement sous un autre ID, version régionale ou d'album)
+// Retourne nil sans erreur si le track est nouveau
+func (db *DB) FindExistingTrack(track *models.Track) (*models.Track, error) {
+	existing, err := db.GetTrackBySpotifyID(track.SpotifyID)
+	if err == sql.ErrNoRows && track.ISRC != "" {
+		existing, err = db.GetTrackByISRC(track.ISRC)
+	}
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return existing, err
+}
 
-	return &models.TrackWithRating{Track: track, Rating: rating}, nil
+// rowScanner est implémenté par *sql.Row et *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
 }
 
-// GetAllTracksWithRatings récupère tous les tracks avec leurs ratings
-func (db *DB) GetAllTracksWithRatings() ([]models.TrackWithRating, error) {
-	rows, err := db.Query(`
-		SELECT t.id, t.spotify_id, t.name, t.artist, t.album, t.year, t.genres_json, t.spotify_uri, t.preview_url, t.audio_features_json, t.created_at,
-		       r.track_id, r.elo, r.wins, r.losses, r.draws, r.last_seen_at
-		FROM tracks t
-		JOIN ratings r ON t.id = r.track_id
-		ORDER BY r.elo DESC`)
+// scanTrackWithRating lit une ligne sélectionnée avec trackWithRatingColumns
+func scanTrackWithRating(row rowScanner) (models.TrackWithRating, error) {
+	var track models.Track
+	var rating models.Rating
+
+	err := row.Scan(append(trackScanDest(&track), ratingScanDest(&rating)...)...)
+
+	return models.TrackWithRating{Track: track, Rating: rating}, err
+}
+
+// queryTracksWithRatings exécute une requête sélectionnant trackWithRatingColumns
+func (db *DB) queryTracksWithRatings(query string, args ...interface{}) ([]models.TrackWithRating, error) {
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -190,41 +548,81 @@ func (db *DB) GetAllTracksWithRatings() ([]models.TrackWithRating, error) {
 
 	var tracks []models.TrackWithRating
 	for rows.Next() {
-		var track models.Track
-		var rating models.Rating
-
-		err := rows.Scan(
-			&track.ID, &track.SpotifyID, &track.Name, &track.Artist, &track.Album, &track.Year,
-			&track.GenresJSON, &track.SpotifyURI, &track.PreviewURL, &track.AudioFeaturesJSON, &track.CreatedAt,
-			&rating.TrackID, &rating.Elo, &rating.Wins, &rating.Losses, &rating.Draws, &rating.LastSeenAt)
+		track, err := scanTrackWithRating(rows)
 		if err != nil {
 			return nil, err
 		}
+		tracks = append(tracks, track)
+	}
+
+	return tracks, rows.Err()
+}
 
-		tracks = append(tracks, models.TrackWithRating{Track: track, Rating: rating})
+// GetTrackWithRating récupère un track avec son rating (créé s'il manque, comme GetRating)
+func (db *DB) GetTrackWithRating(trackID int64) (*models.TrackWithRating, error) {
+	if err := insertMissingRating(db.DB, trackID); err != nil {
+		return nil, err
 	}
 
-	return tracks, nil
+	track, err := scanTrackWithRating(db.QueryRow(`
+		SELECT`+trackWithRatingColumns+`
+		FROM tracks t
+		JOIN ratings r ON t.id = r.track_id
+		WHERE t.id = ?`, trackID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &track, nil
+}
+
+// baselineRankingOrder trie par Elo décroissant ; à Elo égal (fréquent avant calibration),
+// les tracks les plus joués puis l'ordre alphabétique garantissent un classement stable
+// Pour une jointure externe : un rating absent vaut le rating initial
+const baselineRankingOrder = `
+		ORDER BY COALESCE(r.elo, 1200) DESC, COALESCE(r.wins + r.losses + r.draws, 0) DESC, t.name ASC, t.id ASC`
+
+// GetAllTracksWithRatings récupère tous les tracks avec leurs ratings
+// Un track sans rating (données incohérentes, voir RepairOrphans) est retourné avec le rating
+// initial plutôt que de disparaître des duels et du classement
+func (db *DB) GetAllTracksWithRatings() ([]models.TrackWithRating, error) {
+	return db.queryTracksWithRatings(`
+		SELECT` + trackColumns + `,` + baselineRatingColumns + `
+		FROM tracks t
+		LEFT JOIN ratings r ON t.id = r.track_id` + baselineRankingOrder)
 }
 
 // === RATINGS ===
 
 // UpdateRating met à jour les statistiques d'un track
 func (db *DB) UpdateRating(rating *models.Rating) error {
-	_, err := db.Exec(`
-		UPDATE ratings SET elo = ?, wins = ?, losses = ?, draws = ?, last_seen_at = ?
+	return updateRating(db.DB, rating)
+}
+
+func updateRating(q querier, rating *models.Rating) error {
+	_, err := q.Exec(`
+		UPDATE ratings SET elo = ?, wins = ?, losses = ?, draws = ?, last_seen_at = ?, appearances = ?, skips = ?, rd = ?
 		WHERE track_id = ?`,
-		rating.Elo, rating.Wins, rating.Losses, rating.Draws, rating.LastSeenAt, rating.TrackID)
+		rating.Elo, rating.Wins, rating.Losses, rating.Draws, rating.LastSeenAt, rating.Appearances, rating.Skips, rating.RD, rating.TrackID)
 	return err
 }
 
 // GetRating récupère le rating d'un track
+// Un track sans rating (voir RepairOrphans) reçoit d'abord le rating initial, comme dans
+// GetAllTracksWithRatings : un duel proposé avec lui peut toujours être joué
 func (db *DB) GetRating(trackID int64) (*models.Rating, error) {
+	return getRating(db.DB, trackID)
+}
+
+func getRating(q querier, trackID int64) (*models.Rating, error) {
+	if err := insertMissingRating(q, trackID); err != nil {
+		return nil, err
+	}
+
 	var rating models.Rating
-	err := db.QueryRow(`
-		SELECT track_id, elo, wins, losses, draws, last_seen_at
-		FROM ratings WHERE track_id = ?`, trackID).Scan(
-		&rating.TrackID, &rating.Elo, &rating.Wins, &rating.Losses, &rating.Draws, &rating.LastSeenAt)
+	err := q.QueryRow(`
+		SELECT`+ratingColumns+`
+		FROM ratings r WHERE r.track_id = ?`, trackID).Scan(ratingScanDest(&rating)...)
 	if err != nil {
 		return nil, err
 	}
@@ -232,46 +630,219 @@ func (db *DB) GetRating(trackID int64) (*models.Rating, error) {
 }
 
 // GetTopTracks récupère les N meilleurs tracks par Elo
+// Comme dans GetAllTracksWithRatings, un track sans rating est classé avec le rating initial
 func (db *DB) GetTopTracks(limit int) ([]models.TrackWithRating, error) {
-	rows, err := db.Query(`
-		SELECT t.id, t.spotify_id, t.name, t.artist, t.album, t.year, t.genres_json, t.spotify_uri, t.preview_url, t.audio_features_json, t.created_at,
-		       r.track_id, r.elo, r.wins, r.losses, r.draws, r.last_seen_at
+	return db.queryTracksWithRatings(`
+		SELECT`+trackColumns+`,`+baselineRatingColumns+`
 		FROM tracks t
-		JOIN ratings r ON t.id = r.track_id
-		ORDER BY r.elo DESC
+		LEFT JOIN ratings r ON t.id = r.track_id`+baselineRankingOrder+`
 		LIMIT ?`, limit)
+}
+
+// GetTopTrackPerYear récupère le meilleur track (même ordre que le classement) de chaque
+// année présente dans la bibliothèque, par année croissante ; les tracks sans année sont ignorés
+func (db *DB) GetTopTrackPerYear() ([]models.TrackWithRating, error) {
+	return db.queryTracksWithRatings(`
+		SELECT` + trackColumns + `,` + baselineRatingColumns + `
+		FROM (
+			SELECT t.id, ROW_NUMBER() OVER (
+				PARTITION BY t.year` + baselineRankingOrder + `
+			) AS place
+			FROM tracks t
+			LEFT JOIN ratings r ON t.id = r.track_id
+			WHERE t.year > 0
+		) best
+		JOIN tracks t ON t.id = best.id
+		LEFT JOIN ratings r ON t.id = r.track_id
+		WHERE best.place = 1
+		ORDER BY t.year ASC`)
+}
+
+// === GENRES ===
+
+// GetGenres récupère la liste triée des genres présents dans la bibliothèque
+func (db *DB) GetGenres() ([]string, error) {
+	rows, err := db.Query(`
+		SELECT DISTINCT g.value
+		FROM tracks t, json_each(CAST(t.genres_json AS TEXT)) g
+		ORDER BY g.value`)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var tracks []models.TrackWithRating
+	var genres []string
 	for rows.Next() {
-		var track models.Track
-		var rating models.Rating
+		var genre string
+		if err := rows.Scan(&genre); err != nil {
+			return nil, err
+		}
+		genres = append(genres, genre)
+	}
 
-		err := rows.Scan(
-			&track.ID, &track.SpotifyID, &track.Name, &track.Artist, &track.Album, &track.Year,
-			&track.GenresJSON, &track.SpotifyURI, &track.PreviewURL, &track.AudioFeaturesJSON, &track.CreatedAt,
-			&rating.TrackID, &rating.Elo, &rating.Wins, &rating.Losses, &rating.Draws, &rating.LastSeenAt)
-		if err != nil {
+	return genres, rows.Err()
+}
+
+// GetLeaderboardByGenre récupère les N meilleurs tracks d'un genre
+// Un track avec plusieurs genres apparaît dans chacun des classements correspondants
+func (db *DB) GetLeaderboardByGenre(genre string, limit int) ([]models.TrackWithRating, error) {
+	return db.queryTracksWithRatings(`
+		SELECT`+trackColumns+`,`+baselineRatingColumns+`
+		FROM tracks t
+		LEFT JOIN ratings r ON t.id = r.track_id
+		WHERE EXISTS (
+			SELECT 1 FROM json_each(CAST(t.genres_json AS TEXT)) g WHERE g.value = ?
+		)`+baselineRankingOrder+`
+		LIMIT ?`, genre, limit)
+}
+
+// GetSources récupère les sources d'import présentes dans la bibliothèque
+func (db *DB) GetSources() ([]string, error) {
+	rows, err := db.Query(`
+		SELECT DISTINCT source
+		FROM tracks
+		WHERE source != ''
+		ORDER BY source`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sources []string
+	for rows.Next() {
+		var source string
+		if err := rows.Scan(&source); err != nil {
 			return nil, err
 		}
+		sources = append(sources, source)
+	}
+
+	return sources, rows.Err()
+}
 
-		tracks = append(tracks, models.TrackWithRating{Track: track, Rating: rating})
+// GetLeaderboardBySource récupère les N meilleurs tracks importés depuis une source
+func (db *DB) GetLeaderboardBySource(source string, limit int) ([]models.TrackWithRating, error) {
+	return db.queryTracksWithRatings(`
+		SELECT`+trackColumns+`,`+baselineRatingColumns+`
+		FROM tracks t
+		LEFT JOIN ratings r ON t.id = r.track_id
+		WHERE t.source = ?`+baselineRankingOrder+`
+		LIMIT ?`, source, limit)
+}
+
+// GetMoods récupère les humeurs présentes dans la bibliothèque
+func (db *DB) GetMoods() ([]string, error) {
+	rows, err := db.Query(`
+		SELECT DISTINCT mood
+		FROM tracks
+		WHERE mood != ''
+		ORDER BY mood`)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	return tracks, nil
+	var moods []string
+	for rows.Next() {
+		var mood string
+		if err := rows.Scan(&mood); err != nil {
+			return nil, err
+		}
+		moods = append(moods, mood)
+	}
+
+	return moods, rows.Err()
+}
+
+// GetLeaderboardByMood récupère les N meilleurs tracks d'une humeur
+func (db *DB) GetLeaderboardByMood(mood string, limit int) ([]models.TrackWithRating, error) {
+	return db.queryTracksWithRatings(`
+		SELECT`+trackColumns+`,`+baselineRatingColumns+`
+		FROM tracks t
+		LEFT JOIN ratings r ON t.id = r.track_id
+		WHERE t.mood = ?`+baselineRankingOrder+`
+		LIMIT ?`, mood, limit)
+}
+
+// UpdateMoods enregistre l'humeur de plusieurs tracks (ID du track → humeur) en une transaction
+func (db *DB) UpdateMoods(moods map[int64]string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for trackID, mood := range moods {
+		if _, err := tx.Exec(`UPDATE tracks SET mood = ? WHERE id = ?`, mood, trackID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// hasAudioFeatures filtre les tracks (alias t) dont les audio features ont été récupérées
+// (tempo nul = jamais enrichi, comme dans la comparaison)
+const hasAudioFeatures = `json_extract(t.audio_features_json, '$.tempo') > 0`
+
+// GetAverageAudioFeatures calcule la moyenne des audio features des tracks enrichis
+// (valeurs nulles si aucun ; voir CountTracksWithAudioFeatures pour la taille de l'échantillon)
+func (db *DB) GetAverageAudioFeatures() (models.AudioFeatures, error) {
+	var avg models.AudioFeatures
+	err := db.QueryRow(`
+		SELECT
+			COALESCE(AVG(json_extract(t.audio_features_json, '$.danceability')), 0),
+			COALESCE(AVG(json_extract(t.audio_features_json, '$.energy')), 0),
+			COALESCE(AVG(json_extract(t.audio_features_json, '$.loudness')), 0),
+			COALESCE(AVG(json_extract(t.audio_features_json, '$.speechiness')), 0),
+			COALESCE(AVG(json_extract(t.audio_features_json, '$.acousticness')), 0),
+			COALESCE(AVG(json_extract(t.audio_features_json, '$.instrumentalness')), 0),
+			COALESCE(AVG(json_extract(t.audio_features_json, '$.liveness')), 0),
+			COALESCE(AVG(json_extract(t.audio_features_json, '$.valence')), 0),
+			COALESCE(AVG(json_extract(t.audio_features_json, '$.tempo')), 0)
+		FROM tracks t
+		WHERE `+hasAudioFeatures).Scan(
+		&avg.Danceability, &avg.Energy, &avg.Loudness, &avg.Speechiness, &avg.Acousticness,
+		&avg.Instrumentalness, &avg.Liveness, &avg.Valence, &avg.Tempo)
+	return avg, err
+}
+
+// CountTracksWithAudioFeatures compte les tracks dont les audio features ont été récupérées
+func (db *DB) CountTracksWithAudioFeatures() (int, error) {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM tracks t WHERE ` + hasAudioFeatures).Scan(&count)
+	return count, err
+}
+
+// ContestedWinRateMargin est l'écart maximal à 50% de victoires d'un track disputé
+const ContestedWinRateMargin = 0.10
+
+// GetMostContested récupère les tracks disputés : taux de victoire proche de 50%
+// (même calcul que Rating.GetWinRate) avec au moins minBattles duels, les plus joués d'abord
+func (db *DB) GetMostContested(limit, minBattles int) ([]models.TrackWithRating, error) {
+	return db.queryTracksWithRatings(`
+		SELECT`+trackWithRatingColumns+`
+		FROM tracks t
+		JOIN ratings r ON t.id = r.track_id
+		WHERE r.wins + r.losses + r.draws >= MAX(?, 1)
+		  AND ABS(CAST(r.wins AS REAL) / (r.wins + r.losses + r.draws) - 0.5) <= ?
+		ORDER BY r.wins + r.losses + r.draws DESC,
+		         ABS(CAST(r.wins AS REAL) / (r.wins + r.losses + r.draws) - 0.5) ASC
+		LIMIT ?`, minBattles, ContestedWinRateMargin, limit)
 }
 
 // === DUELS ===
 
 // CreateDuel enregistre un nouveau duel
 func (db *DB) CreateDuel(duel *models.Duel) error {
-	result, err := db.Exec(`
-		INSERT INTO duels (left_track_id, right_track_id, winner_track_id, created_at)
-		VALUES (?, ?, ?, ?)`,
-		duel.LeftTrackID, duel.RightTrackID, duel.WinnerTrackID, duel.CreatedAt)
+	return createDuel(db.DB, duel)
+}
+
+func createDuel(q querier, duel *models.Duel) error {
+	result, err := q.Exec(`
+		INSERT INTO duels (left_track_id, right_track_id, winner_track_id, created_at, left_score, confidence, result)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		duel.LeftTrackID, duel.RightTrackID, duel.WinnerTrackID, duel.CreatedAt, duel.LeftScore, duel.Confidence, duel.Result)
 	if err != nil {
 		return err
 	}
@@ -285,29 +856,179 @@ func (db *DB) CreateDuel(duel *models.Duel) error {
 	return nil
 }
 
-// GetDuelHistory récupère l'historique des duels
-func (db *DB) GetDuelHistory(limit int) ([]models.Duel, error) {
+// === HISTORIQUE ELO ===
+
+// GetEloBaselines retourne l'Elo de référence de chaque track ayant un historique :
+// le dernier Elo enregistré avant since, ou à défaut l'Elo précédant son premier duel
+func (db *DB) GetEloBaselines(since time.Time) (map[int64]int, error) {
 	rows, err := db.Query(`
-		SELECT id, left_track_id, right_track_id, winner_track_id, created_at
-		FROM duels
-		ORDER BY created_at DESC
-		LIMIT ?`, limit)
+		SELECT h.track_id, COALESCE(
+			(SELECT b.new_elo FROM elo_history b
+			 WHERE b.track_id = h.track_id AND b.created_at < ?
+			 ORDER BY b.created_at DESC, b.id DESC LIMIT 1),
+			(SELECT f.old_elo FROM elo_history f
+			 WHERE f.track_id = h.track_id
+			 ORDER BY f.created_at, f.id LIMIT 1))
+		FROM (SELECT DISTINCT track_id FROM elo_history) h`, since.Unix())
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var duels []models.Duel
+	baselines := make(map[int64]int)
 	for rows.Next() {
-		var duel models.Duel
-		err := rows.Scan(&duel.ID, &duel.LeftTrackID, &duel.RightTrackID, &duel.WinnerTrackID, &duel.CreatedAt)
-		if err != nil {
+		var trackID int64
+		var elo int
+		if err := rows.Scan(&trackID, &elo); err != nil {
 			return nil, err
 		}
-		duels = append(duels, duel)
+		baselines[trackID] = elo
 	}
 
-	return duels, nil
+	return baselines, rows.Err()
+}
+
+// GetDuelCount retourne le nombre total de duels enregistrés
+func (db *DB) GetDuelCount() (int, error) {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM duels`).Scan(&count)
+	return count, err
+}
+
+// GetDuelCountForDate compte les duels joués le jour donné (fuseau de day)
+func (db *DB) GetDuelCountForDate(day time.Time) (int, error) {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	end := start.AddDate(0, 0, 1)
+
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*) FROM duels
+		WHERE created_at >= ? AND created_at < ?`, start, end).Scan(&count)
+	return count, err
+}
+
+// GetDuelHistory récupère l'historique des duels
+func (db *DB) GetDuelHistory(limit int) ([]models.Duel, error) {
+	rows, err := db.Query(`
+		SELECT id, left_track_id, right_track_id, winner_track_id, created_at
+		FROM duels
+		ORDER BY created_at DESC
+		LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var duels []models.Duel
+	for rows.Next() {
+		var duel models.Duel
+		err := rows.Scan(&duel.ID, &duel.LeftTrackID, &duel.RightTrackID, &duel.WinnerTrackID, &duel.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		duels = append(duels, duel)
+	}
+
+	return duels, nil
+}
+
+// GetDuelHistoryDetailed récupère les derniers duels, du plus récent au plus ancien,
+// avec les deux tracks et leurs Elo avant/après tirés de l'historique Elo
+// Un skip n'a pas d'historique : Rated est faux et les Elo restent à 0
+func (db *DB) GetDuelHistoryDetailed(limit int) ([]models.DuelHistoryEntry, error) {
+	rows, err := db.Query(`
+		SELECT d.id, d.left_track_id, d.right_track_id, d.winner_track_id, d.created_at,
+			lt.spotify_id, lt.name, lt.artist, lt.unavailable,
+			rt.spotify_id, rt.name, rt.artist, rt.unavailable,
+			lh.old_elo, lh.new_elo, rh.old_elo, rh.new_elo
+		FROM duels d
+		JOIN tracks lt ON lt.id = d.left_track_id
+		JOIN tracks rt ON rt.id = d.right_track_id
+		LEFT JOIN elo_history lh ON lh.duel_id = d.id AND lh.track_id = d.left_track_id
+		LEFT JOIN elo_history rh ON rh.duel_id = d.id AND rh.track_id = d.right_track_id
+		ORDER BY d.created_at DESC, d.id DESC
+		LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.DuelHistoryEntry
+	for rows.Next() {
+		var entry models.DuelHistoryEntry
+		var leftBefore, leftAfter, rightBefore, rightAfter sql.NullInt64
+		err := rows.Scan(&entry.ID, &entry.LeftTrackID, &entry.RightTrackID, &entry.WinnerTrackID, &entry.CreatedAt,
+			&entry.Left.SpotifyID, &entry.Left.Name, &entry.Left.Artist, &entry.Left.Unavailable,
+			&entry.Right.SpotifyID, &entry.Right.Name, &entry.Right.Artist, &entry.Right.Unavailable,
+			&leftBefore, &leftAfter, &rightBefore, &rightAfter)
+		if err != nil {
+			return nil, err
+		}
+
+		entry.Left.ID = entry.LeftTrackID
+		entry.Right.ID = entry.RightTrackID
+		entry.Rated = leftBefore.Valid && rightBefore.Valid
+		entry.LeftEloBefore, entry.LeftEloAfter = int(leftBefore.Int64), int(leftAfter.Int64)
+		entry.RightEloBefore, entry.RightEloAfter = int(rightBefore.Int64), int(rightAfter.Int64)
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// GetHeadToHead récupère le bilan des duels entre deux tracks, du point de vue du premier
+func (db *DB) GetHeadToHead(trackID, opponentID int64) (models.HeadToHead, error) {
+	var h2h models.HeadToHead
+	err := db.QueryRow(`
+		SELECT
+			COALESCE(SUM(d.winner_track_id = ?), 0),
+			COALESCE(SUM(d.winner_track_id = ?), 0),
+			COALESCE(SUM(d.result = 'draw'), 0),
+			COALESCE(SUM(d.result = 'skip'), 0)
+		FROM duels d
+		WHERE (d.left_track_id = ? AND d.right_track_id = ?)
+		   OR (d.left_track_id = ? AND d.right_track_id = ?)`,
+		trackID, opponentID, trackID, opponentID, opponentID, trackID,
+	).Scan(&h2h.Wins, &h2h.Losses, &h2h.Draws, &h2h.Skips)
+	return h2h, err
+}
+
+// GetPairRecords récupère le bilan de chaque paire de tracks s'étant affrontée, au format long
+// Chaque paire n'apparaît qu'une fois, du point de vue de son plus petit ID ; les paires
+// dont tous les duels ont été skippés sont ignorées
+func (db *DB) GetPairRecords() ([]models.PairRecord, error) {
+	rows, err := db.Query(`
+		SELECT a, b,
+			SUM(winner_track_id = a),
+			SUM(winner_track_id = b),
+			SUM(result = 'draw'),
+			SUM(result = 'skip')
+		FROM (
+			SELECT MIN(d.left_track_id, d.right_track_id) AS a,
+				MAX(d.left_track_id, d.right_track_id) AS b,
+				d.winner_track_id,
+				d.result
+			FROM duels d
+			WHERE d.left_track_id != d.right_track_id
+		)
+		GROUP BY a, b
+		HAVING SUM(result != 'skip') > 0
+		ORDER BY a, b`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []models.PairRecord
+	for rows.Next() {
+		var record models.PairRecord
+		if err := rows.Scan(&record.TrackA, &record.TrackB, &record.Wins, &record.Losses, &record.Draws, &record.Skips); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
 }
 
 // === META ===
@@ -332,6 +1053,841 @@ func (db *DB) DeleteMeta(key string) error {
 	return err
 }
 
+// getMetaJSON décode une métadonnée JSON (found = false si la clé est absente)
+func (db *DB) getMetaJSON(key string, v interface{}) (bool, error) {
+	value, err := db.GetMeta(key)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := json.Unmarshal([]byte(value), v); err != nil {
+		return false, fmt.Errorf("métadonnée %s invalide: %w", key, err)
+	}
+	return true, nil
+}
+
+// setMetaJSON encode une valeur en JSON et la sauvegarde en métadonnée
+func (db *DB) setMetaJSON(key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return db.SetMeta(key, string(data))
+}
+
+// getMetaValue récupère une métadonnée (found = false si la clé est absente)
+func (db *DB) getMetaValue(key string) (string, bool, error) {
+	value, err := db.GetMeta(key)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+// SetMetaInt sauvegarde une métadonnée entière
+func (db *DB) SetMetaInt(key string, value int64) error {
+	return db.SetMeta(key, strconv.FormatInt(value, 10))
+}
+
+// GetMetaInt récupère une métadonnée entière (def si la clé est absente)
+func (db *DB) GetMetaInt(key string, def int64) (int64, error) {
+	value, found, err := db.getMetaValue(key)
+	if err != nil || !found {
+		return def, err
+	}
+
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return def, fmt.Errorf("métadonnée %s invalide: %w", key, err)
+	}
+	return parsed, nil
+}
+
+// SetMetaTime sauvegarde une date en métadonnée (timestamp Unix)
+func (db *DB) SetMetaTime(key string, value time.Time) error {
+	return db.SetMetaInt(key, value.Unix())
+}
+
+// GetMetaTime récupère une date (def si la clé est absente)
+func (db *DB) GetMetaTime(key string, def time.Time) (time.Time, error) {
+	value, found, err := db.getMetaValue(key)
+	if err != nil || !found {
+		return def, err
+	}
+
+	unix, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return def, fmt.Errorf("métadonnée %s invalide: %w", key, err)
+	}
+	return time.Unix(unix, 0), nil
+}
+
+// SetMetaBool sauvegarde une métadonnée booléenne
+func (db *DB) SetMetaBool(key string, value bool) error {
+	return db.SetMeta(key, strconv.FormatBool(value))
+}
+
+// GetMetaBool récupère une métadonnée booléenne (def si la clé est absente)
+func (db *DB) GetMetaBool(key string, def bool) (bool, error) {
+	value, found, err := db.getMetaValue(key)
+	if err != nil || !found {
+		return def, err
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return def, fmt.Errorf("métadonnée %s invalide: %w", key, err)
+	}
+	return parsed, nil
+}
+
+// === IMPORT QUEUE ===
+
+// GetImportQueue récupère les IDs Spotify dont l'import a été reporté
+func (db *DB) GetImportQueue() ([]string, error) {
+	queue := []string{}
+	if _, err := db.getMetaJSON(models.MetaKeyImportQueue, &queue); err != nil {
+		return nil, err
+	}
+	return queue, nil
+}
+
+// SetImportQueue remplace la file des imports reportés (supprimée si vide)
+func (db *DB) SetImportQueue(spotifyIDs []string) error {
+	if len(spotifyIDs) == 0 {
+		return db.DeleteMeta(models.MetaKeyImportQueue)
+	}
+	return db.setMetaJSON(models.MetaKeyImportQueue, spotifyIDs)
+}
+
+// AddToImportQueue ajoute des IDs Spotify à la file des imports reportés
+func (db *DB) AddToImportQueue(spotifyIDs ...string) error {
+	queue, err := db.GetImportQueue()
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(queue))
+	for _, id := range queue {
+		seen[id] = true
+	}
+	for _, id := range spotifyIDs {
+		if !seen[id] {
+			queue = append(queue, id)
+			seen[id] = true
+		}
+	}
+
+	return db.SetImportQueue(queue)
+}
+
+// GetImportSources récupère la source d'origine des imports reportés, par ID Spotify
+func (db *DB) GetImportSources() (map[string]string, error) {
+	sources := map[string]string{}
+	if _, err := db.getMetaJSON(models.MetaKeyImportSources, &sources); err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
+// SetImportSources remplace les sources des imports reportés (supprimées si vides)
+func (db *DB) SetImportSources(sources map[string]string) error {
+	if len(sources) == 0 {
+		return db.DeleteMeta(models.MetaKeyImportSources)
+	}
+	return db.setMetaJSON(models.MetaKeyImportSources, sources)
+}
+
+// AddImportSources complète les sources des imports reportés
+func (db *DB) AddImportSources(added map[string]string) error {
+	if len(added) == 0 {
+		return nil
+	}
+
+	sources, err := db.GetImportSources()
+	if err != nil {
+		return err
+	}
+	for id, source := range added {
+		sources[id] = source
+	}
+	return db.SetImportSources(sources)
+}
+
+// === PIN ===
+
+// GetPinnedTrack récupère le track épinglé (nil si aucun)
+func (db *DB) GetPinnedTrack() (*models.PinnedTrack, error) {
+	var pin models.PinnedTrack
+	found, err := db.getMetaJSON(models.MetaKeyPinnedTrack, &pin)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &pin, nil
+}
+
+// PinTrack épingle un track (remplace l'éventuel track déjà épinglé)
+func (db *DB) PinTrack(trackID int64, startBattles int) error {
+	return db.setMetaJSON(models.MetaKeyPinnedTrack, models.PinnedTrack{
+		TrackID:      trackID,
+		StartBattles: startBattles,
+	})
+}
+
+// UnpinTrack retire l'épinglage
+func (db *DB) UnpinTrack() error {
+	return db.DeleteMeta(models.MetaKeyPinnedTrack)
+}
+
+// === DUEL EN COURS ===
+
+// SetCurrentDuel sauvegarde les tracks du duel affiché, pour le reprendre au prochain lancement
+func (db *DB) SetCurrentDuel(leftTrackID, rightTrackID int64) error {
+	_, err := db.Exec(`
+		INSERT OR REPLACE INTO meta (key, value) VALUES (?, ?), (?, ?)`,
+		models.MetaKeyCurrentLeft, strconv.FormatInt(leftTrackID, 10),
+		models.MetaKeyCurrentRight, strconv.FormatInt(rightTrackID, 10))
+	return err
+}
+
+// GetCurrentDuel récupère les tracks du dernier duel affiché (0, 0 si aucun)
+func (db *DB) GetCurrentDuel() (int64, int64, error) {
+	left, err := db.GetMetaInt(models.MetaKeyCurrentLeft, 0)
+	if err != nil {
+		return 0, 0, err
+	}
+	right, err := db.GetMetaInt(models.MetaKeyCurrentRight, 0)
+	if err != nil {
+		return 0, 0, err
+	}
+	return left, right, nil
+}
+
+// === SESSION ===
+
+// GetSessionProgress récupère la dernière session sauvegardée (nil si aucune)
+func (db *DB) GetSessionProgress() (*models.SessionProgress, error) {
+	var progress models.SessionProgress
+	found, err := db.getMetaJSON(models.MetaKeySessionProgress, &progress)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+// SaveSessionProgress sauvegarde l'état de la session en cours
+func (db *DB) SaveSessionProgress(progress models.SessionProgress) error {
+	return db.setMetaJSON(models.MetaKeySessionProgress, progress)
+}
+
+// === RIVALRIES ===
+
+// GetRivalries récupère les rivalités définies, dans l'ordre de création
+func (db *DB) GetRivalries() ([]models.Rivalry, error) {
+	rivalries := []models.Rivalry{}
+	if _, err := db.getMetaJSON(models.MetaKeyRivalries, &rivalries); err != nil {
+		return nil, err
+	}
+	return rivalries, nil
+}
+
+// AddRivalry enregistre une rivalité entre deux tracks (sans effet si elle existe déjà)
+func (db *DB) AddRivalry(a, b int64) error {
+	rivalries, err := db.GetRivalries()
+	if err != nil {
+		return err
+	}
+	for _, rivalry := range rivalries {
+		if rivalry.Involves(a, b) {
+			return nil // Déjà définie
+		}
+	}
+	return db.setMetaJSON(models.MetaKeyRivalries, append(rivalries, models.Rivalry{TrackA: a, TrackB: b}))
+}
+
+// RemoveRivalry supprime la rivalité entre deux tracks
+func (db *DB) RemoveRivalry(a, b int64) error {
+	rivalries, err := db.GetRivalries()
+	if err != nil {
+		return err
+	}
+
+	remaining := make([]models.Rivalry, 0, len(rivalries))
+	for _, rivalry := range rivalries {
+		if !rivalry.Involves(a, b) {
+			remaining = append(remaining, rivalry)
+		}
+	}
+	return db.setMetaJSON(models.MetaKeyRivalries, remaining)
+}
+
+// === QUICK RATE ===
+
+// quickRateBaseElo est l'Elo de départ d'un track (voir CreateTrack)
+const quickRateBaseElo = 1200
+
+// QuickRateOffsets associe une note de 1 à 5 au décalage appliqué à l'Elo de départ
+var QuickRateOffsets = map[int]int{1: -200, 2: -100, 3: 0, 4: 100, 5: 200}
+
+// SetInitialEloFromScore fixe l'Elo de départ d'un track jamais joué d'après une note de 1 à 5
+// Le track est ensuite retiré de la file de notation rapide
+func (db *DB) SetInitialEloFromScore(id int64, score int) error {
+	offset, ok := QuickRateOffsets[score]
+	if !ok {
+		return fmt.Errorf("note invalide %d (attendu 1 à 5)", score)
+	}
+
+	result, err := db.Exec(`
+		UPDATE ratings SET elo = ?
+		WHERE track_id = ? AND wins + losses + draws = 0`,
+		quickRateBaseElo+offset, id)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return fmt.Errorf("track %d introuvable ou déjà joué en duel", id)
+	}
+
+	var rated []int64
+	if _, err := db.getMetaJSON(models.MetaKeyQuickRated, &rated); err != nil {
+		return err
+	}
+	return db.setMetaJSON(models.MetaKeyQuickRated, append(rated, id))
+}
+
+// GetQuickRateQueue récupère les tracks jamais joués ni notés (et lisibles), les derniers importés d'abord
+func (db *DB) GetQuickRateQueue() ([]models.TrackWithRating, error) {
+	var rated []int64
+	if _, err := db.getMetaJSON(models.MetaKeyQuickRated, &rated); err != nil {
+		return nil, err
+	}
+	ratedSet := make(map[int64]bool, len(rated))
+	for _, id := range rated {
+		ratedSet[id] = true
+	}
+
+	tracks, err := db.queryTracksWithRatings(`
+		SELECT` + trackWithRatingColumns + `
+		FROM tracks t
+		JOIN ratings r ON t.id = r.track_id
+		WHERE r.wins + r.losses + r.draws = 0 AND t.unavailable = 0
+		ORDER BY t.created_at DESC, t.id DESC`)
+	if err != nil {
+		return nil, err
+	}
+
+	queue := tracks[:0]
+	for _, track := range tracks {
+		if !ratedSet[track.Track.ID] {
+			queue = append(queue, track)
+		}
+	}
+	return queue, nil
+}
+
+// === FLAGS ===
+
+// GetFlaggedTrackIDs récupère les IDs des tracks marqués pour réécoute
+func (db *DB) GetFlaggedTrackIDs() ([]int64, error) {
+	ids := []int64{}
+	if _, err := db.getMetaJSON(models.MetaKeyFlaggedTracks, &ids); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// IsTrackFlagged indique si un track est marqué pour réécoute
+func (db *DB) IsTrackFlagged(trackID int64) (bool, error) {
+	ids, err := db.GetFlaggedTrackIDs()
+	if err != nil {
+		return false, err
+	}
+	for _, id := range ids {
+		if id == trackID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// FlagTrack marque un track pour réécoute
+func (db *DB) FlagTrack(trackID int64) error {
+	ids, err := db.GetFlaggedTrackIDs()
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if id == trackID {
+			return nil // Déjà marqué
+		}
+	}
+	return db.setMetaJSON(models.MetaKeyFlaggedTracks, append(ids, trackID))
+}
+
+// UnflagTrack retire un track de la liste de réécoute
+func (db *DB) UnflagTrack(trackID int64) error {
+	ids, err := db.GetFlaggedTrackIDs()
+	if err != nil {
+		return err
+	}
+
+	remaining := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if id != trackID {
+			remaining = append(remaining, id)
+		}
+	}
+	return db.setMetaJSON(models.MetaKeyFlaggedTracks, remaining)
+}
+
+// GetFlaggedTracks récupère les tracks marqués pour réécoute, dans l'ordre de marquage
+func (db *DB) GetFlaggedTracks() ([]models.TrackWithRating, error) {
+	ids, err := db.GetFlaggedTrackIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	tracks := make([]models.TrackWithRating, 0, len(ids))
+	for _, id := range ids {
+		track, err := db.GetTrackWithRating(id)
+		if err != nil {
+			continue // Track supprimé entre-temps
+		}
+		tracks = append(tracks, *track)
+	}
+	return tracks, nil
+}
+
+// === INTÉGRITÉ ===
+
+// orphanRatingIDs sélectionne les ratings dont le track n'existe plus
+const orphanRatingIDs = `SELECT track_id FROM ratings WHERE track_id NOT IN (SELECT id FROM tracks)`
+
+// orphanDuelIDs sélectionne les duels dont l'un des deux tracks n'existe plus
+const orphanDuelIDs = `
+	SELECT id FROM duels
+	WHERE left_track_id NOT IN (SELECT id FROM tracks) OR right_track_id NOT IN (SELECT id FROM tracks)`
+
+// unratedTrackIDs sélectionne les tracks sans rating, exclus de toutes les jointures track/rating
+const unratedTrackIDs = `SELECT id FROM tracks WHERE id NOT IN (SELECT track_id FROM ratings)`
+
+// FindOrphans récupère les lignes qui référencent un track supprimé hors des suppressions
+// en cascade : ratings (par track_id) et duels (par id)
+func (db *DB) FindOrphans() (ratings, duels []int64, err error) {
+	if ratings, err = db.queryIDs(orphanRatingIDs); err != nil {
+		return nil, nil, err
+	}
+	if duels, err = db.queryIDs(orphanDuelIDs); err != nil {
+		return nil, nil, err
+	}
+	return ratings, duels, nil
+}
+
+// FindUnratedTracks récupère les tracks sans rating : absents des duels comme du classement
+func (db *DB) FindUnratedTracks() ([]int64, error) {
+	return db.queryIDs(unratedTrackIDs)
+}
+
+// RepairOrphans supprime les ratings et duels orphelins, avec l'historique Elo qui les concerne,
+// puis crée un rating initial pour les tracks qui n'en ont pas
+// Retourne le nombre de ratings et duels supprimés et le nombre de ratings créés
+func (db *DB) RepairOrphans() (deleted, created int64, err error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	// L'historique d'abord : il référence les duels à supprimer
+	if _, err := tx.Exec(`
+		DELETE FROM elo_history
+		WHERE track_id NOT IN (SELECT id FROM tracks) OR duel_id IN (` + orphanDuelIDs + `)`); err != nil {
+		return 0, 0, err
+	}
+
+	for _, query := range []string{
+		`DELETE FROM duels WHERE id IN (` + orphanDuelIDs + `)`,
+		`DELETE FROM ratings WHERE track_id IN (` + orphanRatingIDs + `)`,
+	} {
+		result, err := tx.Exec(query)
+		if err != nil {
+			return 0, 0, err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return 0, 0, err
+		}
+		deleted += n
+	}
+
+	if created, err = insertMissingRatings(tx); err != nil {
+		return 0, 0, err
+	}
+
+	return deleted, created, tx.Commit()
+}
+
+// insertMissingRating crée le rating initial d'un track qui n'en a pas (sans effet sinon)
+func insertMissingRating(q querier, trackID int64) error {
+	_, err := q.Exec(`
+		INSERT OR IGNORE INTO ratings (track_id, elo, wins, losses, draws, last_seen_at)
+		SELECT id, 1200, 0, 0, 0, ? FROM tracks WHERE id = ?`,
+		time.Now(), trackID)
+	return err
+}
+
+// insertMissingRatings crée le rating initial des tracks qui n'en ont pas et retourne leur nombre
+func insertMissingRatings(q querier) (int64, error) {
+	result, err := q.Exec(`
+		INSERT INTO ratings (track_id, elo, wins, losses, draws, last_seen_at)
+		SELECT id, 1200, 0, 0, 0, ? FROM tracks WHERE id NOT IN (SELECT track_id FROM ratings)`,
+		time.Now())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// queryIDs exécute une requête retournant une colonne d'IDs
+func (db *DB) queryIDs(query string) ([]int64, error) {
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// === FUSION ===
+
+// RetargetTrackReferences reporte sur toID l'épinglage, les rivalités et le marquage de fromID
+// (track fusionné dans toID) ; une rivalité entre les deux tracks disparaît
+func (db *DB) RetargetTrackReferences(fromID, toID int64) error {
+	pin, err := db.GetPinnedTrack()
+	if err != nil {
+		return err
+	}
+	if pin != nil && pin.TrackID == fromID {
+		if err := db.PinTrack(toID, pin.StartBattles); err != nil {
+			return err
+		}
+	}
+
+	rivalries, err := db.GetRivalries()
+	if err != nil {
+		return err
+	}
+	retargeted := make([]models.Rivalry, 0, len(rivalries))
+	for _, rivalry := range rivalries {
+		if rivalry.TrackA == fromID {
+			rivalry.TrackA = toID
+		}
+		if rivalry.TrackB == fromID {
+			rivalry.TrackB = toID
+		}
+		duplicate := rivalry.TrackA == rivalry.TrackB
+		for _, kept := range retargeted {
+			duplicate = duplicate || kept.Involves(rivalry.TrackA, rivalry.TrackB)
+		}
+		if !duplicate {
+			retargeted = append(retargeted, rivalry)
+		}
+	}
+	if err := db.setMetaJSON(models.MetaKeyRivalries, retargeted); err != nil {
+		return err
+	}
+
+	flagged, err := db.IsTrackFlagged(fromID)
+	if err != nil || !flagged {
+		return err
+	}
+	if err := db.UnflagTrack(fromID); err != nil {
+		return err
+	}
+	return db.FlagTrack(toID)
+}
+
+// === TRANSACTIONS ===
+
+// WithTx exécute fn dans une transaction, validée si fn ne retourne pas d'erreur
+func (db *DB) WithTx(fn func(tx *Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(&Tx{tx: tx}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetRating récupère le rating d'un track dans la transaction
+func (t *Tx) GetRating(trackID int64) (*models.Rating, error) {
+	return getRating(t.tx, trackID)
+}
+
+// UpdateRating met à jour les statistiques d'un track dans la transaction
+func (t *Tx) UpdateRating(rating *models.Rating) error {
+	return updateRating(t.tx, rating)
+}
+
+// CreateDuel enregistre un duel dans la transaction
+func (t *Tx) CreateDuel(duel *models.Duel) error {
+	return createDuel(t.tx, duel)
+}
+
+// CountAppearance compte une apparition en duel pour les deux tracks, et un skip si skipped ;
+// delta vaut -1 pour décompter un duel annulé
+func (t *Tx) CountAppearance(leftTrackID, rightTrackID int64, skipped bool, delta int) error {
+	skips := 0
+	if skipped {
+		skips = delta
+	}
+	_, err := t.tx.Exec(`
+		UPDATE ratings SET appearances = MAX(appearances + ?, 0), skips = MAX(skips + ?, 0)
+		WHERE track_id IN (?, ?)`,
+		delta, skips, leftTrackID, rightTrackID)
+	return err
+}
+
+// GetLastDuel récupère le dernier duel enregistré avec son résultat (nil s'il n'y en a aucun)
+func (t *Tx) GetLastDuel() (*models.LoggedDuel, error) {
+	var duel models.LoggedDuel
+	err := t.tx.QueryRow(`
+		SELECT d.id, d.left_track_id, d.right_track_id, d.winner_track_id, d.created_at, d.left_score, d.confidence, d.result
+		FROM duels d
+		ORDER BY d.id DESC
+		LIMIT 1`,
+	).Scan(&duel.ID, &duel.LeftTrackID, &duel.RightTrackID, &duel.WinnerTrackID, &duel.CreatedAt, &duel.LeftScore, &duel.Confidence, &duel.Result)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &duel, nil
+}
+
+// GetEloHistoryForDuel récupère les variations d'Elo enregistrées pour un duel
+func (t *Tx) GetEloHistoryForDuel(duelID int64) ([]models.EloHistory, error) {
+	rows, err := t.tx.Query(`
+		SELECT id, track_id, duel_id, old_elo, new_elo, created_at, old_rd, new_rd, old_last_seen_at
+		FROM elo_history
+		WHERE duel_id = ?`, duelID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []models.EloHistory
+	for rows.Next() {
+		var entry models.EloHistory
+		var createdAt int64
+		var oldLastSeen sql.NullInt64
+		if err := rows.Scan(&entry.ID, &entry.TrackID, &entry.DuelID, &entry.OldElo, &entry.NewElo, &createdAt, &entry.OldRD, &entry.NewRD, &oldLastSeen); err != nil {
+			return nil, err
+		}
+		entry.CreatedAt = time.Unix(createdAt, 0)
+		if oldLastSeen.Valid {
+			seen := time.Unix(oldLastSeen.Int64, 0)
+			entry.OldLastSeenAt = &seen
+		}
+		history = append(history, entry)
+	}
+
+	return history, rows.Err()
+}
+
+// DeleteDuel supprime un duel et son historique d'Elo
+func (t *Tx) DeleteDuel(duelID int64) error {
+	if _, err := t.tx.Exec(`DELETE FROM elo_history WHERE duel_id = ?`, duelID); err != nil {
+		return err
+	}
+	_, err := t.tx.Exec(`DELETE FROM duels WHERE id = ?`, duelID)
+	return err
+}
+
+// GetDuelLog récupère tous les duels dans l'ordre chronologique avec leur résultat
+// Les duels dont un track n'existe plus sont ignorés
+func (t *Tx) GetDuelLog() ([]models.LoggedDuel, error) {
+	rows, err := t.tx.Query(`
+		SELECT d.id, d.left_track_id, d.right_track_id, d.winner_track_id, d.created_at, d.left_score, d.confidence, d.result
+		FROM duels d
+		JOIN ratings lr ON lr.track_id = d.left_track_id
+		JOIN ratings rr ON rr.track_id = d.right_track_id
+		ORDER BY d.created_at ASC, d.id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var duels []models.LoggedDuel
+	for rows.Next() {
+		var duel models.LoggedDuel
+		if err := rows.Scan(&duel.ID, &duel.LeftTrackID, &duel.RightTrackID, &duel.WinnerTrackID, &duel.CreatedAt, &duel.LeftScore, &duel.Confidence, &duel.Result); err != nil {
+			return nil, err
+		}
+		duels = append(duels, duel)
+	}
+
+	return duels, rows.Err()
+}
+
+// GetStartingElos récupère l'Elo de chaque track avant son premier duel : l'Elo d'avant son
+// premier duel historisé, ou son Elo actuel s'il n'a jamais joué (notation rapide comprise)
+// Un track ayant joué des duels sans historique (base antérieure à elo_history) repart de
+// initialElo : son Elo actuel contient déjà ces duels, qui vont être rejoués
+func (t *Tx) GetStartingElos(initialElo int) (map[int64]int, error) {
+	rows, err := t.tx.Query(`
+		SELECT r.track_id, CASE
+			WHEN EXISTS (
+				SELECT 1 FROM duels d
+				WHERE (d.left_track_id = r.track_id OR d.right_track_id = r.track_id)
+				  AND d.result != 'skip'
+				  AND NOT EXISTS (SELECT 1 FROM elo_history h WHERE h.duel_id = d.id AND h.track_id = r.track_id))
+			THEN ?
+			ELSE COALESCE(
+				(SELECT h.old_elo FROM elo_history h WHERE h.track_id = r.track_id ORDER BY h.id LIMIT 1),
+				r.elo)
+		END
+		FROM ratings r`, initialElo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	elos := make(map[int64]int)
+	for rows.Next() {
+		var trackID int64
+		var elo int
+		if err := rows.Scan(&trackID, &elo); err != nil {
+			return nil, err
+		}
+		elos[trackID] = elo
+	}
+
+	return elos, rows.Err()
+}
+
+// ResetRatings remet à zéro les compteurs de tous les tracks, leur écart type à sa valeur
+// initiale et leur Elo à leur Elo de départ
+func (t *Tx) ResetRatings(startingElos map[int64]int) error {
+	if _, err := t.tx.Exec(`UPDATE ratings SET wins = 0, losses = 0, draws = 0, rd = 350`); err != nil {
+		return err
+	}
+	for trackID, elo := range startingElos {
+		if _, err := t.tx.Exec(`UPDATE ratings SET elo = ? WHERE track_id = ?`, elo, trackID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetEloRange récupère l'Elo moyen, minimal et maximal des tracks (0 track : moyenne 0)
+func (t *Tx) GetEloRange() (mean float64, minElo, maxElo, count int, err error) {
+	err = t.tx.QueryRow(`
+		SELECT COALESCE(AVG(r.elo), 0), COALESCE(MIN(r.elo), 0), COALESCE(MAX(r.elo), 0), COUNT(*)
+		FROM ratings r
+		JOIN tracks t ON t.id = r.track_id`).Scan(&mean, &minElo, &maxElo, &count)
+	return mean, minElo, maxElo, count, err
+}
+
+// ShiftElos décale l'Elo de tous les tracks de delta, historique compris : le replay des
+// duels (-recompute) repart des Elo décalés et -diff-since ne voit pas le décalage
+func (t *Tx) ShiftElos(delta int) error {
+	if _, err := t.tx.Exec(`UPDATE ratings SET elo = elo + ? WHERE track_id IN (SELECT id FROM tracks)`, delta); err != nil {
+		return err
+	}
+	_, err := t.tx.Exec(`UPDATE elo_history SET old_elo = old_elo + ?, new_elo = new_elo + ?`, delta, delta)
+	return err
+}
+
+// ClearEloHistory supprime tout l'historique des Elo
+func (t *Tx) ClearEloHistory() error {
+	_, err := t.tx.Exec(`DELETE FROM elo_history`)
+	return err
+}
+
+// AddEloHistory enregistre la variation d'Elo (et d'écart type) d'un track lors d'un duel,
+// ainsi que sa date de dernière apparition avant le duel (restaurée par l'undo)
+func (t *Tx) AddEloHistory(trackID, duelID int64, oldElo, newElo int, oldRD, newRD float64, oldLastSeen, at time.Time) error {
+	var lastSeen *int64
+	if !oldLastSeen.IsZero() {
+		unix := oldLastSeen.Unix()
+		lastSeen = &unix
+	}
+	_, err := t.tx.Exec(`
+		INSERT INTO elo_history (track_id, duel_id, old_elo, new_elo, old_rd, new_rd, old_last_seen_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		trackID, duelID, oldElo, newElo, oldRD, newRD, lastSeen, at.Unix())
+	return err
+}
+
+// CountDuelsBetween compte les duels entre deux tracks : décidés (un vainqueur), nuls et skips
+func (t *Tx) CountDuelsBetween(a, b int64) (decided, draws, skips int, err error) {
+	err = t.tx.QueryRow(`
+		SELECT
+			COUNT(CASE WHEN d.winner_track_id IS NOT NULL THEN 1 END),
+			COUNT(CASE WHEN d.result = 'draw' THEN 1 END),
+			COUNT(CASE WHEN d.result = 'skip' THEN 1 END)
+		FROM duels d
+		WHERE (d.left_track_id = ? AND d.right_track_id = ?) OR (d.left_track_id = ? AND d.right_track_id = ?)`,
+		a, b, b, a).Scan(&decided, &draws, &skips)
+	return decided, draws, skips, err
+}
+
+// MergeTrack fond le track dropID dans keepID : les duels entre les deux sont supprimés,
+// les autres duels et l'historique des Elo passent à keepID, puis dropID et son rating sont supprimés
+// Le rating de keepID n'est pas modifié (voir elo.MergeTracks)
+func (t *Tx) MergeTrack(dropID, keepID int64) error {
+	// Suppressions explicites : les clés étrangères ne sont pas garanties actives
+	const mutualDuels = `SELECT id FROM duels
+		WHERE (left_track_id = ? AND right_track_id = ?) OR (left_track_id = ? AND right_track_id = ?)`
+	statements := []struct {
+		query string
+		args  []interface{}
+	}{
+		{`DELETE FROM elo_history WHERE duel_id IN (` + mutualDuels + `)`, []interface{}{dropID, keepID, keepID, dropID}},
+		{`DELETE FROM duels WHERE id IN (` + mutualDuels + `)`, []interface{}{dropID, keepID, keepID, dropID}},
+		{`UPDATE duels SET left_track_id = ? WHERE left_track_id = ?`, []interface{}{keepID, dropID}},
+		{`UPDATE duels SET right_track_id = ? WHERE right_track_id = ?`, []interface{}{keepID, dropID}},
+		{`UPDATE duels SET winner_track_id = ? WHERE winner_track_id = ?`, []interface{}{keepID, dropID}},
+		{`UPDATE elo_history SET track_id = ? WHERE track_id = ?`, []interface{}{keepID, dropID}},
+		{`DELETE FROM ratings WHERE track_id = ?`, []interface{}{dropID}},
+		{`DELETE FROM tracks WHERE id = ?`, []interface{}{dropID}},
+	}
+	for _, statement := range statements {
+		if _, err := t.tx.Exec(statement.query, statement.args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CopyTo écrit une copie cohérente de la base dans path, qui ne doit pas déjà exister
+func (db *DB) CopyTo(path string) error {
+	_, err := db.Exec(`VACUUM INTO ?`, path)
+	return err
+}
+
 // Close ferme la connexion à la base de données
 func (db *DB) Close() error {
 	return db.DB.Close()
@@ -0,0 +1,257 @@
+package store
+
+import (
+	"fmt"
+	"path/filepath"
+	"songbattle/internal/models"
+	"sync"
+	"testing"
+)
+
+// newTestDB ouvre une base temporaire vide
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+
+	db, err := NewDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// testTrack retourne un track minimal identifié par spotifyID
+func testTrack(spotifyID, name string) *models.Track {
+	return &models.Track{
+		SpotifyID:  spotifyID,
+		Name:       name,
+		Artist:     "Artist",
+		Album:      "Album",
+		SpotifyURI: "spotify:track:" + spotifyID,
+		Popularity: -1,
+	}
+}
+
+func TestCreateTrackConcurrentDuplicates(t *testing.T) {
+	db := newTestDB(t)
+
+	const (
+		distinct = 5
+		workers  = 8 // Imports concurrents du même lot de tracks
+	)
+	ids := make([][]int64, workers)
+	errs := make(chan error, workers*distinct)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		ids[w] = make([]int64, distinct)
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < distinct; i++ {
+				track := testTrack(fmt.Sprintf("track%d", i), fmt.Sprintf("Track %d", i))
+				if err := db.CreateTrack(track); err != nil {
+					errs <- err
+					continue
+				}
+				ids[w][i] = track.ID
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("CreateTrack: %v", err)
+	}
+
+	// Chaque import reçoit l'ID du track existant
+	for w := 1; w < workers; w++ {
+		for i := 0; i < distinct; i++ {
+			if ids[w][i] != ids[0][i] {
+				t.Errorf("worker %d got ID %d for track%d, worker 0 got %d", w, ids[w][i], i, ids[0][i])
+			}
+		}
+	}
+
+	var tracks, ratings int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM tracks`).Scan(&tracks); err != nil {
+		t.Fatalf("count tracks: %v", err)
+	}
+	if err := db.QueryRow(`SELECT COUNT(*) FROM ratings`).Scan(&ratings); err != nil {
+		t.Fatalf("count ratings: %v", err)
+	}
+	if tracks != distinct || ratings != distinct {
+		t.Errorf("got %d tracks and %d ratings, want %d of each", tracks, ratings, distinct)
+	}
+}
+
+func TestRankingOrderTiebreak(t *testing.T) {
+	db := newTestDB(t)
+
+	// Insérés dans le désordre : l'ordre d'insertion ne doit pas décider des égalités
+	tracks := []struct {
+		name      string
+		elo, wins int
+	}{
+		{"Charlie", 1200, 0},
+		{"Alpha", 1200, 0},
+		{"Bravo", 1200, 3},
+		{"Delta", 1300, 0},
+	}
+	for i, tt := range tracks {
+		track := testTrack(fmt.Sprintf("track%d", i), tt.name)
+		if err := db.CreateTrack(track); err != nil {
+			t.Fatalf("CreateTrack: %v", err)
+		}
+		if _, err := db.Exec(`UPDATE ratings SET elo = ?, wins = ? WHERE track_id = ?`, tt.elo, tt.wins, track.ID); err != nil {
+			t.Fatalf("set rating: %v", err)
+		}
+	}
+
+	// Elo d'abord, puis le plus de duels, puis le nom
+	want := []string{"Delta", "Bravo", "Alpha", "Charlie"}
+	queries := map[string]func() ([]models.TrackWithRating, error){
+		"GetTopTracks":            func() ([]models.TrackWithRating, error) { return db.GetTopTracks(-1) },
+		"GetAllTracksWithRatings": db.GetAllTracksWithRatings,
+	}
+	for name, query := range queries {
+		for call := 0; call < 3; call++ {
+			ranking, err := query()
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			got := make([]string, len(ranking))
+			for i, track := range ranking {
+				got[i] = track.Track.Name
+			}
+			if fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Errorf("%s call %d = %v, want %v", name, call+1, got, want)
+			}
+		}
+	}
+}
+
+func TestTrackWithoutRating(t *testing.T) {
+	db := newTestDB(t)
+
+	var ids []int64
+	for i, name := range []string{"Rated", "Orphan"} {
+		track := testTrack(fmt.Sprintf("track%d", i), name)
+		track.GenresJSON = models.Genres{"rock"}
+		track.Source = "liked"
+		track.Mood = "calm"
+		track.Year = 1999
+		if err := db.CreateTrack(track); err != nil {
+			t.Fatalf("CreateTrack: %v", err)
+		}
+		ids = append(ids, track.ID)
+	}
+	if _, err := db.Exec(`UPDATE ratings SET elo = 1100 WHERE track_id = ?`, ids[0]); err != nil {
+		t.Fatalf("set elo: %v", err)
+	}
+	if _, err := db.Exec(`DELETE FROM ratings WHERE track_id = ?`, ids[1]); err != nil {
+		t.Fatalf("delete rating: %v", err)
+	}
+
+	// Les classements le gardent, au rating initial
+	queries := map[string]func() ([]models.TrackWithRating, error){
+		"GetAllTracksWithRatings": db.GetAllTracksWithRatings,
+		"GetTopTracks":            func() ([]models.TrackWithRating, error) { return db.GetTopTracks(-1) },
+		"GetLeaderboardByGenre":   func() ([]models.TrackWithRating, error) { return db.GetLeaderboardByGenre("rock", -1) },
+		"GetLeaderboardBySource":  func() ([]models.TrackWithRating, error) { return db.GetLeaderboardBySource("liked", -1) },
+		"GetLeaderboardByMood":    func() ([]models.TrackWithRating, error) { return db.GetLeaderboardByMood("calm", -1) },
+	}
+	for name, query := range queries {
+		ranking, err := query()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(ranking) != 2 || ranking[0].Track.ID != ids[1] || ranking[0].Rating.Elo != 1200 {
+			t.Errorf("%s = %+v, want the orphan first at 1200", name, ranking)
+		}
+	}
+	best, err := db.GetTopTrackPerYear()
+	if err != nil {
+		t.Fatalf("GetTopTrackPerYear: %v", err)
+	}
+	if len(best) != 1 || best[0].Track.ID != ids[1] {
+		t.Errorf("GetTopTrackPerYear = %+v, want the orphan", best)
+	}
+
+	// Un duel proposé avec lui lit son rating dans une transaction : le rating initial est créé
+	err = db.WithTx(func(tx *Tx) error {
+		rating, err := tx.GetRating(ids[1])
+		if err != nil {
+			return err
+		}
+		if rating.Elo != 1200 || rating.Wins+rating.Losses+rating.Draws != 0 {
+			t.Errorf("created rating = %+v, want the initial rating", rating)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("GetRating in a transaction: %v", err)
+	}
+	if _, err := db.Exec(`DELETE FROM ratings WHERE track_id = ?`, ids[1]); err != nil {
+		t.Fatalf("delete rating: %v", err)
+	}
+	track, err := db.GetTrackWithRating(ids[1])
+	if err != nil {
+		t.Fatalf("GetTrackWithRating: %v", err)
+	}
+	if track.Rating.TrackID != ids[1] || track.Rating.Elo != 1200 {
+		t.Errorf("GetTrackWithRating rating = %+v, want the initial rating", track.Rating)
+	}
+
+	var ratings int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM ratings`).Scan(&ratings); err != nil {
+		t.Fatalf("count ratings: %v", err)
+	}
+	if ratings != 2 {
+		t.Errorf("%d ratings, want 2", ratings)
+	}
+}
+
+func TestFindExistingTrack(t *testing.T) {
+	db := newTestDB(t)
+
+	saved := testTrack("album-version", "Song")
+	saved.ISRC = "FRZ039800212"
+	if err := db.CreateTrack(saved); err != nil {
+		t.Fatalf("CreateTrack: %v", err)
+	}
+	noISRC := testTrack("no-isrc", "Other song")
+	if err := db.CreateTrack(noISRC); err != nil {
+		t.Fatalf("CreateTrack: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		spotifyID string
+		isrc      string
+		want      int64 // ID du track attendu, 0 si nouveau
+	}{
+		{"same Spotify ID", "album-version", "FRZ039800212", saved.ID},
+		{"same ISRC under another Spotify ID", "single-version", "FRZ039800212", saved.ID},
+		{"other ISRC", "remix", "FRZ039800999", 0},
+		{"empty ISRC does not match tracks without one", "unknown", "", 0},
+		{"same Spotify ID without ISRC", "no-isrc", "", noISRC.ID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			track := testTrack(tt.spotifyID, "Candidate")
+			track.ISRC = tt.isrc
+
+			existing, err := db.FindExistingTrack(track)
+			if err != nil {
+				t.Fatalf("FindExistingTrack: %v", err)
+			}
+			var got int64
+			if existing != nil {
+				got = existing.ID
+			}
+			if got != tt.want {
+				t.Errorf("FindExistingTrack(%s, %q) = track %d, want %d", tt.spotifyID, tt.isrc, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"songbattle/internal/elo"
+	"songbattle/internal/models"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Animation du compteur d'Elo après un vote (~1 seconde)
+const (
+	eloAnimationFrames   = 10
+	eloAnimationInterval = 100 * time.Millisecond
+)
+
+// eloAnimation fait défiler l'Elo des cards de l'ancienne à la nouvelle valeur
+type eloAnimation struct {
+	id      int // Identifie l'animation en cours, pour ignorer les frames périmées
+	changes []elo.EloChange
+	frame   int
+}
+
+// EloAnimationFrameMsg fait avancer l'animation d'une frame
+type EloAnimationFrameMsg struct{ ID int }
+
+// startEloAnimation lance l'animation des changements d'Elo d'un vote
+// (ou affiche directement le résultat si l'animation est désactivée)
+func (m Model) startEloAnimation(changes []elo.EloChange) (Model, tea.Cmd) {
+	if len(changes) == 0 {
+		return m, nil
+	}
+
+	m.eloAnimation = eloAnimation{id: m.eloAnimation.id + 1, changes: changes}
+	if !m.animate {
+		// Afficher directement les nouveaux Elo
+		m.eloAnimation.frame = eloAnimationFrames
+		return m, nil
+	}
+	return m, m.nextAnimationFrame()
+}
+
+// nextAnimationFrame programme la frame suivante
+func (m Model) nextAnimationFrame() tea.Cmd {
+	id := m.eloAnimation.id
+	return tea.Tick(eloAnimationInterval, func(time.Time) tea.Msg {
+		return EloAnimationFrameMsg{ID: id}
+	})
+}
+
+// handleEloAnimationFrame avance l'animation jusqu'à la dernière frame
+func (m Model) handleEloAnimationFrame(msg EloAnimationFrameMsg) (tea.Model, tea.Cmd) {
+	if msg.ID != m.eloAnimation.id || m.eloAnimation.changes == nil {
+		return m, nil
+	}
+
+	m.eloAnimation.frame++
+	if m.eloAnimation.frame >= eloAnimationFrames {
+		return m, nil
+	}
+	return m, m.nextAnimationFrame()
+}
+
+// stopEloAnimation termine l'animation (nouveau duel affiché)
+func (m *Model) stopEloAnimation() {
+	m.eloAnimation.changes = nil
+	m.eloAnimation.frame = 0
+}
+
+// displayElo retourne l'Elo à afficher sur la card d'un track, animé si besoin
+// Sans animation en cours, c'est l'Elo chargé avec le duel
+func (m Model) displayElo(track *models.TrackWithRating) int {
+	for _, change := range m.eloAnimation.changes {
+		if change.TrackID != track.Track.ID {
+			continue
+		}
+
+		frame := m.eloAnimation.frame
+		if frame > eloAnimationFrames {
+			frame = eloAnimationFrames
+		}
+		return change.OldElo + (change.NewElo-change.OldElo)*frame/eloAnimationFrames
+	}
+
+	return track.Rating.Elo
+}
@@ -0,0 +1,96 @@
+package ui
+
+import (
+	"songbattle/internal/logging"
+	"songbattle/internal/models"
+	"songbattle/internal/store"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sessionResumeWindow est le délai après la dernière sauvegarde pendant lequel une session
+// interrompue brutalement est reprise au lancement suivant
+const sessionResumeWindow = 30 * time.Minute
+
+// SessionAutosaveMsg déclenche la sauvegarde périodique des compteurs de session
+type SessionAutosaveMsg struct{}
+
+// loadSessionStats reprend la session interrompue par un arrêt brutal s'il y en a une récente
+// (et si la sauvegarde automatique est activée), sinon démarre une nouvelle session
+func loadSessionStats(db *store.DB, tracks []models.TrackWithRating, autosave bool) sessionStats {
+	if !autosave {
+		return newSessionStats(tracks)
+	}
+
+	progress, err := db.GetSessionProgress()
+	if err != nil {
+		logging.Printf("[session] saved progress unreadable: %v", err)
+	}
+	if progress == nil || progress.Ended || time.Since(progress.SavedAt) > sessionResumeWindow {
+		return newSessionStats(tracks)
+	}
+
+	logging.Printf("[session] resuming session started %s (%d duels, %d skips)", progress.StartedAt.Format(time.RFC3339), progress.Duels, progress.Skips)
+	return sessionStats{
+		startedAt:   progress.StartedAt,
+		startElo:    progress.StartElo,
+		startLeader: progress.StartLeader,
+		duels:       progress.Duels,
+		skips:       progress.Skips,
+		savedDuels:  progress.Duels,
+		savedSkips:  progress.Skips,
+	}
+}
+
+// progress retourne l'état de la session à sauvegarder
+func (s sessionStats) progress(ended bool) models.SessionProgress {
+	return models.SessionProgress{
+		StartedAt:   s.startedAt,
+		SavedAt:     time.Now(),
+		Duels:       s.duels,
+		Skips:       s.skips,
+		StartElo:    s.startElo,
+		StartLeader: s.startLeader,
+		Ended:       ended,
+	}
+}
+
+// scheduleAutosave programme la prochaine sauvegarde des compteurs de session
+func (m Model) scheduleAutosave() tea.Cmd {
+	if m.autosaveInterval <= 0 {
+		return nil
+	}
+	return tea.Tick(m.autosaveInterval, func(time.Time) tea.Msg {
+		return SessionAutosaveMsg{}
+	})
+}
+
+// handleSessionAutosave sauvegarde les compteurs s'ils ont changé depuis la dernière sauvegarde ;
+// l'écriture se fait hors de la boucle de rendu
+func (m Model) handleSessionAutosave() (tea.Model, tea.Cmd) {
+	if m.session.duels == m.session.savedDuels && m.session.skips == m.session.savedSkips {
+		return m, m.scheduleAutosave()
+	}
+
+	m.session.savedDuels = m.session.duels
+	m.session.savedSkips = m.session.skips
+
+	db, progress := m.db, m.session.progress(false)
+	save := func() tea.Msg {
+		if err := db.SaveSessionProgress(progress); err != nil {
+			logging.Printf("[session] autosave failed: %v", err)
+		}
+		return nil
+	}
+	return m, tea.Batch(save, m.scheduleAutosave())
+}
+
+// EndSession marque la session comme terminée normalement : elle ne sera pas reprise
+// au prochain lancement (appelé en quittant, et en changeant de bibliothèque)
+func (m Model) EndSession() error {
+	if m.autosaveInterval <= 0 {
+		return nil
+	}
+	return m.db.SaveSessionProgress(m.session.progress(true))
+}
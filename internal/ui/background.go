@@ -0,0 +1,45 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// backgroundDoneMsg enveloppe le résultat d'une opération de fond suivie
+type backgroundDoneMsg struct {
+	Msg tea.Msg
+}
+
+// runInBackground suit une opération longue (export, import) jusqu'à son résultat,
+// pour pouvoir avertir l'utilisateur s'il quitte avant la fin
+func (m Model) runInBackground(cmd tea.Cmd) (Model, tea.Cmd) {
+	m.inFlight++
+	return m, func() tea.Msg {
+		return backgroundDoneMsg{Msg: cmd()}
+	}
+}
+
+// handleBackgroundDone décompte l'opération terminée puis traite son résultat
+func (m Model) handleBackgroundDone(msg backgroundDoneMsg) (tea.Model, tea.Cmd) {
+	if m.inFlight > 0 {
+		m.inFlight--
+	}
+	if m.inFlight == 0 {
+		m.quitPending = false
+	}
+	if msg.Msg == nil {
+		return m, nil
+	}
+	return m.Update(msg.Msg)
+}
+
+// confirmQuit demande une seconde pression sur 'q' tant qu'une opération est en cours
+// Retourne true si l'application peut quitter
+func (m *Model) confirmQuit() bool {
+	if m.inFlight == 0 || m.quitPending {
+		return true
+	}
+
+	m.quitPending = true
+	m.statusMessage = "⚠️  Opération en cours, appuyez à nouveau sur 'q' pour quitter quand même"
+	return false
+}
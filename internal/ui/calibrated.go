@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"fmt"
+	"songbattle/internal/models"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// setLeaderboard affiche un classement chargé, sans les tracks encore en calibration
+// (moins de fairStartBattles duels) si le classement est limité aux tracks calibrés
+func (m *Model) setLeaderboard(tracks []models.TrackWithRating) {
+	m.leaderboardAll = tracks
+	m.leaderboard = tracks
+	m.leaderboardCalibrating = 0
+	if !m.calibratedOnly {
+		return
+	}
+
+	calibrated := make([]models.TrackWithRating, 0, len(tracks))
+	for _, track := range tracks {
+		if track.Rating.GetTotalBattles() >= m.fairStartBattles {
+			calibrated = append(calibrated, track)
+		}
+	}
+	m.leaderboard = calibrated
+	m.leaderboardCalibrating = len(tracks) - len(calibrated)
+}
+
+// handleToggleCalibratedOnly masque ou réaffiche les tracks en calibration dans le classement
+// Les tracks masqués restent en base et en duel ; la préférence est conservée entre les sessions
+func (m Model) handleToggleCalibratedOnly() (tea.Model, tea.Cmd) {
+	calibratedOnly := !m.calibratedOnly
+	if err := m.db.SetMetaBool(models.MetaKeyCalibratedOnly, calibratedOnly); err != nil {
+		m.statusMessage = "⚠️  Impossible de sauvegarder la préférence d'affichage"
+		return m, nil
+	}
+
+	// Garder le curseur sur le même track s'il reste affiché
+	var cursorID int64
+	if m.leaderboardCursor < len(m.leaderboard) {
+		cursorID = m.leaderboard[m.leaderboardCursor].Track.ID
+	}
+
+	m.calibratedOnly = calibratedOnly
+	m.setLeaderboard(m.leaderboardAll)
+
+	m.leaderboardCursor = 0
+	for i, track := range m.leaderboard {
+		if track.Track.ID == cursorID {
+			m.leaderboardCursor = i
+			break
+		}
+	}
+
+	if calibratedOnly {
+		m.statusMessage = fmt.Sprintf("🎯 Classement limité aux tracks calibrés (%d+ duels)", m.fairStartBattles)
+	} else {
+		m.statusMessage = "🎯 Classement complet, tracks en calibration compris"
+	}
+	return m, nil
+}
@@ -0,0 +1,216 @@
+package ui
+
+import (
+	"fmt"
+	"songbattle/internal/models"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// trackComparison contient les deux tracks comparés côte à côte et leur face-à-face
+type trackComparison struct {
+	left, right models.TrackWithRating
+	headToHead  models.HeadToHead // Du point de vue du track de gauche
+}
+
+// compareLabelWidth et compareColumnWidth dimensionnent les colonnes de la comparaison
+const (
+	compareLabelWidth  = 16
+	compareColumnWidth = 30
+)
+
+// handleShowCompare compare les deux tracks sélectionnés dans le classement, sans enregistrer de duel
+func (m Model) handleShowCompare() (tea.Model, tea.Cmd) {
+	ids := m.selectedTrackIDs()
+	if len(ids) != 2 {
+		m.statusMessage = "⚠️  Sélectionnez exactement 2 tracks (m puis ␣) pour les comparer"
+		return m, nil
+	}
+
+	left, err := m.db.GetTrackWithRating(ids[0])
+	if err != nil {
+		m.statusMessage = "⚠️  Impossible de charger les tracks à comparer"
+		return m, nil
+	}
+	right, err := m.db.GetTrackWithRating(ids[1])
+	if err != nil {
+		m.statusMessage = "⚠️  Impossible de charger les tracks à comparer"
+		return m, nil
+	}
+
+	headToHead, err := m.db.GetHeadToHead(ids[0], ids[1])
+	if err != nil {
+		m.statusMessage = "⚠️  Impossible de charger le face-à-face"
+		return m, nil
+	}
+
+	m.compare = &trackComparison{left: *left, right: *right, headToHead: headToHead}
+	m.loadAudioAverages()
+	m.currentView = ViewCompare
+	m.statusMessage = ""
+	return m, nil
+}
+
+// handleCompareKey gère le clavier de la comparaison (lecture seule : retour uniquement)
+func (m Model) handleCompareKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "q", "esc", "escape", "=":
+		m.compare = nil
+		m.currentView = ViewLeaderboard
+	}
+
+	return m, nil
+}
+
+// renderCompare affiche les statistiques des deux tracks en deux colonnes
+// La meilleure valeur de chaque ligne chiffrée est mise en avant
+func (m Model) renderCompare() string {
+	if m.compare == nil {
+		return m.renderLoading()
+	}
+
+	left, right := m.compare.left, m.compare.right
+	h2h := m.compare.headToHead
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		Width(compareLabelWidth)
+
+	columnStyle := lipgloss.NewStyle().
+		Width(compareColumnWidth)
+
+	betterStyle := columnStyle.
+		Foreground(ColorSuccess).
+		Bold(true)
+
+	sectionStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true).
+		MarginTop(1)
+
+	// row affiche une ligne ; compare > 0 met la gauche en avant, < 0 la droite
+	row := func(label, leftValue, rightValue string, compare float64) string {
+		leftCell, rightCell := columnStyle.Render(leftValue), columnStyle.Render(rightValue)
+		if compare > 0 {
+			leftCell = betterStyle.Render(leftValue)
+		} else if compare < 0 {
+			rightCell = betterStyle.Render(rightValue)
+		}
+		return lipgloss.JoinHorizontal(lipgloss.Top, labelStyle.Render(label), leftCell, rightCell)
+	}
+
+	tiers := m.libraryStats.tiers
+	lines := []string{
+		lipgloss.JoinHorizontal(lipgloss.Top,
+			labelStyle.Render(""),
+			columnStyle.Foreground(ColorPrimary).Bold(true).Render(Truncate(trackTitle(left.Track), compareColumnWidth-2)),
+			columnStyle.Foreground(ColorPrimary).Bold(true).Render(Truncate(trackTitle(right.Track), compareColumnWidth-2))),
+		row("", Truncate(left.Track.Artist, compareColumnWidth-2), Truncate(right.Track.Artist, compareColumnWidth-2), 0),
+		row("Source", sourceLabel(left.Track.Source), sourceLabel(right.Track.Source), 0),
+		row("Mood", moodLabel(left.Track.Mood), moodLabel(right.Track.Mood), 0),
+
+		sectionStyle.Render("Rating"),
+		row("Elo", fmt.Sprintf("%d %s", left.Rating.Elo, RenderTierBadge(tiers.Tier(left.Rating.Elo))),
+			fmt.Sprintf("%d %s", right.Rating.Elo, RenderTierBadge(tiers.Tier(right.Rating.Elo))),
+			float64(left.Rating.Elo-right.Rating.Elo)),
+		row("Win rate", compareWinRate(left.Rating), compareWinRate(right.Rating),
+			left.Rating.GetWinRate()-right.Rating.GetWinRate()),
+		row("Battles", fmt.Sprint(left.Rating.GetTotalBattles()), fmt.Sprint(right.Rating.GetTotalBattles()), 0),
+		row("W / D / L", fmt.Sprintf("%d / %d / %d", left.Rating.Wins, left.Rating.Draws, left.Rating.Losses),
+			fmt.Sprintf("%d / %d / %d", right.Rating.Wins, right.Rating.Draws, right.Rating.Losses), 0),
+		row("Shown", fmt.Sprint(left.Rating.Appearances), fmt.Sprint(right.Rating.Appearances), 0),
+		row("Skipped", compareSkips(left.Rating), compareSkips(right.Rating), 0),
+
+		sectionStyle.Render("Head to head"),
+	}
+
+	if h2h.Total() == 0 {
+		lines = append(lines, labelStyle.Render("")+lipgloss.NewStyle().Foreground(ColorMuted).Render("Never met in a battle"))
+	} else {
+		lines = append(lines,
+			row("Wins", fmt.Sprint(h2h.Wins), fmt.Sprint(h2h.Losses), float64(h2h.Wins-h2h.Losses)),
+			labelStyle.Render("")+lipgloss.NewStyle().Foreground(ColorMuted).Render(
+				fmt.Sprintf("%d draws, %d skips", h2h.Draws, h2h.Skips)))
+	}
+
+	lines = append(lines, sectionStyle.Render("Audio features"))
+	leftFeatures, leftOK := compareFeatures(left.Track)
+	rightFeatures, rightOK := compareFeatures(right.Track)
+	if !leftOK && !rightOK {
+		lines = append(lines, labelStyle.Render("")+lipgloss.NewStyle().Foreground(ColorMuted).Render("No audio features stored"))
+	} else {
+		// feature affiche une valeur suivie de son écart à la moyenne de la bibliothèque, si connue
+		averages := m.audioAverages
+		feature := func(value float64, ok bool, format, delta string) string {
+			if !ok {
+				return "—"
+			}
+			text := fmt.Sprintf(format, value)
+			if averages != nil {
+				text += " (" + delta + ")"
+			}
+			return text
+		}
+		var avg models.AudioFeatures
+		if averages != nil {
+			avg = averages.features
+		}
+		percent := func(label string, l, r, average float64) string {
+			return row(label, feature(l*100, leftOK, "%.0f%%", percentDelta(l, average)),
+				feature(r*100, rightOK, "%.0f%%", percentDelta(r, average)), 0)
+		}
+		lines = append(lines,
+			percent("Danceability", leftFeatures.Danceability, rightFeatures.Danceability, avg.Danceability),
+			percent("Energy", leftFeatures.Energy, rightFeatures.Energy, avg.Energy),
+			percent("Valence", leftFeatures.Valence, rightFeatures.Valence, avg.Valence),
+			percent("Acousticness", leftFeatures.Acousticness, rightFeatures.Acousticness, avg.Acousticness),
+			row("Tempo", feature(leftFeatures.Tempo, leftOK, "%.0f BPM", tempoDelta(leftFeatures.Tempo, avg.Tempo)),
+				feature(rightFeatures.Tempo, rightOK, "%.0f BPM", tempoDelta(rightFeatures.Tempo, avg.Tempo)), 0),
+		)
+		if averages != nil {
+			lines = append(lines, labelStyle.Render("")+averages.note())
+		}
+	}
+
+	controls := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		Padding(1, 0).
+		Render("q back  •  read-only: nothing is recorded")
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		RenderHeader(),
+		"",
+		lipgloss.NewStyle().Foreground(ColorPrimary).Bold(true).Render("⚖️  Compare"),
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, lines...),
+		controls,
+		RenderFooter(m.statusMessage),
+	)
+}
+
+// compareWinRate formate le taux de victoire ("—" sans duel)
+func compareWinRate(rating models.Rating) string {
+	if rating.GetTotalBattles() == 0 {
+		return "—"
+	}
+	return fmt.Sprintf("%.0f%%", rating.GetWinRate())
+}
+
+// compareSkips formate les skips d'un track et leur part dans ses apparitions
+func compareSkips(rating models.Rating) string {
+	if rating.Appearances == 0 {
+		return "—"
+	}
+	return fmt.Sprintf("%d (%.0f%%)", rating.Skips, float64(rating.Skips)/float64(rating.Appearances)*100)
+}
+
+// compareFeatures retourne les caractéristiques audio stockées d'un track (ok = false si jamais récupérées)
+func compareFeatures(track models.Track) (models.AudioFeatures, bool) {
+	features := track.AudioFeaturesJSON
+	return features, features.Tempo > 0
+}
@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"fmt"
+	"songbattle/internal/models"
+	"strings"
+)
+
+// viewNames nomme chaque vue dans les rapports de crash
+var viewNames = map[ViewState]string{
+	ViewDuel:           "duel",
+	ViewAudioFeatures:  "audio features",
+	ViewLoading:        "loading",
+	ViewError:          "error",
+	ViewLeaderboard:    "leaderboard",
+	ViewGenres:         "genres",
+	ViewFlagged:        "flagged",
+	ViewSearch:         "search",
+	ViewSessionSummary: "session summary",
+	ViewStats:          "stats",
+	ViewImportNeeded:   "import needed",
+	ViewOnboarding:     "onboarding",
+	ViewQuickRate:      "quick rate",
+	ViewHistory:        "history",
+	ViewCompare:        "compare",
+	ViewLibraries:      "libraries",
+	ViewGroupVote:      "group vote",
+	ViewYears:          "years",
+	ViewCrossGenres:    "cross genres",
+	ViewReplay:         "replay",
+}
+
+// CrashContext décrit l'état de l'interface au moment d'un crash (vue, statut, tracks affichés)
+// Le texte est écrit tel quel dans le rapport de crash local
+func (m Model) CrashContext() string {
+	view, ok := viewNames[m.currentView]
+	if !ok {
+		view = fmt.Sprintf("unknown (%d)", m.currentView)
+	}
+
+	lines := []string{
+		"View: " + view,
+		"Status: " + m.statusMessage,
+	}
+	if m.errorMessage != "" {
+		lines = append(lines, "Error: "+m.errorMessage)
+	}
+	lines = append(lines,
+		"Left track: "+crashTrack(m.leftTrack),
+		"Right track: "+crashTrack(m.rightTrack),
+		fmt.Sprintf("Offline: %t", m.offline),
+		fmt.Sprintf("Terminal: %dx%d", m.width, m.height),
+	)
+	return strings.Join(lines, "\n")
+}
+
+// crashTrack décrit un track du duel en cours pour le rapport de crash
+func crashTrack(track *models.TrackWithRating) string {
+	if track == nil {
+		return "none"
+	}
+	return fmt.Sprintf("#%d %s - %s (Elo %d)", track.Track.ID, track.Track.Artist, track.Track.Name, track.Rating.Elo)
+}
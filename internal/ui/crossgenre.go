@@ -0,0 +1,141 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// handleShowCrossGenres ouvre le choix des deux genres à opposer
+// Si le mode inter-genres est actif, il est désactivé à la place
+func (m Model) handleShowCrossGenres() (tea.Model, tea.Cmd) {
+	if genreA, _ := m.matchmaker.CrossGenres(); genreA != "" {
+		m.matchmaker.SetCrossGenres("", "")
+		m.statusMessage = "🎸 Duels sur tous les genres"
+		return m, m.setupNextDuel
+	}
+
+	genres, err := m.db.GetGenres()
+	if err != nil {
+		m.statusMessage = "⚠️  Impossible de charger les genres"
+		return m, nil
+	}
+	if len(genres) < 2 {
+		m.statusMessage = "Il faut au moins 2 genres pour opposer des genres (genres importés depuis Spotify)"
+		return m, nil
+	}
+
+	m.genres = genres
+	m.crossGenreCursor = 0
+	m.crossGenreFirst = ""
+	m.currentView = ViewCrossGenres
+	m.statusMessage = ""
+	return m, nil
+}
+
+// handleCrossGenreKey gère le clavier du choix des genres : le premier ↵ choisit
+// le genre de gauche, le second celui de droite et lance les duels
+func (m Model) handleCrossGenreKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "up", "k":
+		if m.crossGenreCursor > 0 {
+			m.crossGenreCursor--
+		}
+
+	case "down", "j":
+		if m.crossGenreCursor < len(m.genres)-1 {
+			m.crossGenreCursor++
+		}
+
+	case "enter":
+		genre := m.genres[m.crossGenreCursor]
+		if m.crossGenreFirst == "" {
+			m.crossGenreFirst = genre
+			m.statusMessage = "Choisissez le genre à opposer à " + genre
+			return m, nil
+		}
+		if genre == m.crossGenreFirst {
+			m.statusMessage = "Choisissez un autre genre que " + genre
+			return m, nil
+		}
+		return m.startCrossGenres(m.crossGenreFirst, genre)
+
+	case "q", "esc", "escape", "G":
+		// Revenir sur le premier choix avant de quitter
+		if m.crossGenreFirst != "" {
+			m.crossGenreFirst = ""
+			m.statusMessage = ""
+			return m, nil
+		}
+		m.currentView = ViewDuel
+		m.statusMessage = ""
+	}
+
+	return m, nil
+}
+
+// startCrossGenres active le mode inter-genres si chaque genre a des tracks éligibles aux duels
+func (m Model) startCrossGenres(genreA, genreB string) (tea.Model, tea.Cmd) {
+	if _, _, err := m.matchmaker.CrossGenreMatch(genreA, genreB); err != nil {
+		m.statusMessage = fmt.Sprintf("⚠️  Duel %s vs %s impossible : %v", genreA, genreB, err)
+		return m, nil
+	}
+
+	m.matchmaker.SetCrossGenres(genreA, genreB)
+	m.crossGenreFirst = ""
+	m.currentView = ViewDuel
+	m.statusMessage = fmt.Sprintf("🎸 %s (gauche) vs %s (droite)", genreA, genreB)
+	return m, m.setupNextDuel
+}
+
+// renderCrossGenres affiche la liste des genres, le genre déjà choisi étant repéré
+func (m Model) renderCrossGenres() string {
+	genreStyle := lipgloss.NewStyle().
+		Foreground(ColorSecondary).
+		Width(40)
+
+	selectedStyle := lipgloss.NewStyle().
+		Background(ColorPrimary).
+		Foreground(lipgloss.Color("#000000")).
+		Bold(true)
+
+	start, end := scrollWindow(m.crossGenreCursor, len(m.genres), m.layout().LeaderboardRows)
+
+	var lines []string
+	for i := start; i < end; i++ {
+		marker := "  "
+		if m.genres[i] == m.crossGenreFirst {
+			marker = "◀ "
+		}
+		line := genreStyle.Render(marker + Truncate(m.genres[i], 36))
+		if i == m.crossGenreCursor {
+			line = selectedStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	title := "🎸 Genre battle: pick the left genre"
+	if m.crossGenreFirst != "" {
+		title = "🎸 Genre battle: " + m.crossGenreFirst + " vs ..."
+	}
+
+	controls := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		Padding(1, 0).
+		Render("↑↓ navigate  ↵ pick  q back")
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		RenderHeader(),
+		"",
+		lipgloss.NewStyle().Foreground(ColorPrimary).Bold(true).Render(title),
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, lines...),
+		controls,
+		RenderFooter(m.statusMessage),
+	)
+}
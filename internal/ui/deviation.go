@@ -0,0 +1,42 @@
+package ui
+
+import (
+	"songbattle/internal/elo"
+	"songbattle/internal/models"
+	"sort"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// currentDeviation retourne l'incertitude de l'Elo d'un track à afficher, inactivité comprise
+func currentDeviation(rating models.Rating) float64 {
+	return elo.CurrentDeviation(rating, time.Now())
+}
+
+// handleToggleConservative bascule entre le classement et le classement par borne basse de l'Elo :
+// un track peu joué ne passe devant un track confirmé que s'il le devance nettement
+func (m Model) handleToggleConservative() (tea.Model, tea.Cmd) {
+	if m.leaderboardMode == leaderboardConservative {
+		return m.handleShowLeaderboard()
+	}
+
+	tracks, err := m.db.GetAllTracksWithRatings()
+	if err != nil {
+		m.statusMessage = "⚠️  Failed to load leaderboard"
+		return m, nil
+	}
+
+	now := time.Now()
+	sort.SliceStable(tracks, func(i, j int) bool {
+		return elo.ConservativeElo(tracks[i].Rating, now) > elo.ConservativeElo(tracks[j].Rating, now)
+	})
+
+	m.setLeaderboard(tracks)
+	m.leaderboardCursor = 0
+	m.leaderboardGenre = ""
+	m.leaderboardSource = ""
+	m.leaderboardMood = ""
+	m.leaderboardMode = leaderboardConservative
+	return m, nil
+}
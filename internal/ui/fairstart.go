@@ -0,0 +1,25 @@
+package ui
+
+import (
+	"fmt"
+	"songbattle/internal/models"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// hidesStats indique si l'Elo et le bilan d'un track sont masqués sur sa card :
+// avec le départ équitable, tant qu'il n'a pas disputé assez de duels
+func (m Model) hidesStats(track *models.TrackWithRating) bool {
+	return m.fairStart && track != nil && track.Rating.GetTotalBattles() < m.fairStartBattles
+}
+
+// handleToggleFairStart active ou désactive le masquage des Elo pour la session
+func (m Model) handleToggleFairStart() (tea.Model, tea.Cmd) {
+	m.fairStart = !m.fairStart
+	if m.fairStart {
+		m.statusMessage = fmt.Sprintf("🙈 Départ équitable : Elo masqué avant %d duels", m.fairStartBattles)
+	} else {
+		m.statusMessage = "👀 Départ équitable désactivé : tous les Elo sont affichés"
+	}
+	return m, nil
+}
@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"fmt"
+	"songbattle/internal/models"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// minAverageSample est le nombre de tracks enrichis en dessous duquel la moyenne de la
+// bibliothèque est signalée comme peu représentative
+const minAverageSample = 10
+
+// audioFeaturesUnavailable est affiché quand ni l'API ni l'extrait du track ne donnent d'audio features
+const audioFeaturesUnavailable = "⚠️  Audio features indisponible (permissions Spotify limitées)"
+
+// audioAverages est la moyenne des audio features de la bibliothèque, calculée à la demande
+// puis gardée jusqu'au prochain ajout de tracks
+type audioAverages struct {
+	features models.AudioFeatures
+	tracks   int // Tracks enrichis sur lesquels porte la moyenne
+}
+
+// loadAudioAverages retourne la moyenne en cache, ou la calcule (nil si aucun track n'est enrichi)
+func (m *Model) loadAudioAverages() *audioAverages {
+	if m.audioAverages != nil {
+		return m.audioAverages
+	}
+
+	count, err := m.db.CountTracksWithAudioFeatures()
+	if err != nil || count == 0 {
+		return nil
+	}
+	features, err := m.db.GetAverageAudioFeatures()
+	if err != nil {
+		return nil
+	}
+
+	m.audioAverages = &audioAverages{features: features, tracks: count}
+	return m.audioAverages
+}
+
+// percentDelta formate l'écart d'une caractéristique (0-1) à la moyenne, en points ("+15")
+func percentDelta(value, average float64) string {
+	return fmt.Sprintf("%+.0f", (value-average)*100)
+}
+
+// tempoDelta formate l'écart de tempo à la moyenne ("+12 BPM")
+func tempoDelta(value, average float64) string {
+	return fmt.Sprintf("%+.0f BPM", value-average)
+}
+
+// note explique la référence des écarts, en signalant un échantillon trop petit
+func (a *audioAverages) note() string {
+	note := fmt.Sprintf("± vs your library average (%d tracks with audio features)", a.tracks)
+	if a.tracks < minAverageSample {
+		note = fmt.Sprintf("± vs your library average (only %d tracks with audio features: a rough guide)", a.tracks)
+	}
+	return lipgloss.NewStyle().Foreground(ColorMuted).Render(note)
+}
+
+// estimatedNote signale un profil estimé à partir de l'extrait, moins fiable que celui de l'API
+func estimatedNote() string {
+	return lipgloss.NewStyle().Foreground(ColorMuted).Render("≈ Rough estimate from the 30-second preview (energy and tempo only)")
+}
@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"fmt"
+	"songbattle/internal/models"
+	"songbattle/internal/store"
+	"time"
+)
+
+// dailyGoal suit la progression vers l'objectif de duels du jour
+type dailyGoal struct {
+	goal int // 0 = objectif désactivé
+	done int
+	day  string // Jour de la progression (2006-01-02)
+}
+
+// loadDailyGoal compte les duels du jour
+func loadDailyGoal(db *store.DB, goal int) dailyGoal {
+	now := time.Now()
+	daily := dailyGoal{goal: goal, day: now.Format("2006-01-02")}
+	if goal > 0 {
+		daily.done, _ = db.GetDuelCountForDate(now)
+	}
+	return daily
+}
+
+// refreshDailyGoal recompte les duels du jour et célèbre l'objectif une fois par jour
+// Retourne le message de célébration ("" si rien de nouveau)
+func (m *Model) refreshDailyGoal() string {
+	if m.dailyGoal.goal <= 0 {
+		return ""
+	}
+
+	m.dailyGoal = loadDailyGoal(m.db, m.dailyGoal.goal)
+	if m.dailyGoal.done < m.dailyGoal.goal {
+		return ""
+	}
+
+	// Jour de la dernière célébration, pour ne pas la répéter à chaque duel
+	if celebrated, err := m.db.GetMeta(models.MetaKeyDailyGoalReached); err == nil && celebrated == m.dailyGoal.day {
+		return ""
+	}
+	if err := m.db.SetMeta(models.MetaKeyDailyGoalReached, m.dailyGoal.day); err != nil {
+		return ""
+	}
+
+	return fmt.Sprintf("🎉 Objectif du jour atteint : %d duels !", m.dailyGoal.goal)
+}
+
+// render affiche la progression du jour ("🎯 3/10 today", "" si désactivé)
+func (g dailyGoal) render() string {
+	if g.goal <= 0 {
+		return ""
+	}
+	if g.done >= g.goal {
+		return fmt.Sprintf("✅ %d/%d today", g.done, g.goal)
+	}
+	return fmt.Sprintf("🎯 %d/%d today", g.done, g.goal)
+}
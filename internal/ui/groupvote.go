@@ -0,0 +1,161 @@
+package ui
+
+import (
+	"fmt"
+	"songbattle/internal/elo"
+	"songbattle/internal/models"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxGroupVotes borne le nombre de voix d'un côté lors d'un vote en groupe
+const maxGroupVotes = 99
+
+// handleShowGroupVote ouvre la saisie d'un vote partagé pour le duel en cours
+func (m Model) handleShowGroupVote() (tea.Model, tea.Cmd) {
+	if m.leftTrack == nil || m.rightTrack == nil {
+		return m, nil
+	}
+
+	m.groupVotes = [2]int{}
+	m.groupSide = m.focus
+	m.currentView = ViewGroupVote
+	m.statusMessage = ""
+	return m, nil
+}
+
+// handleGroupVoteKey gère le clavier de la saisie du vote en groupe
+func (m Model) handleGroupVoteKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	switch key {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "left", "h":
+		m.groupSide = FocusLeft
+
+	case "right", "l":
+		m.groupSide = FocusRight
+
+	case "up", "k", "+":
+		if m.groupVotes[m.groupSide] < maxGroupVotes {
+			m.groupVotes[m.groupSide]++
+		}
+
+	case "down", "j", "-":
+		if m.groupVotes[m.groupSide] > 0 {
+			m.groupVotes[m.groupSide]--
+		}
+
+	case "0", "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		m.groupVotes[m.groupSide] = int(key[0] - '0')
+
+	case "enter":
+		return m.handleGroupVoteSubmit()
+
+	case "q", "esc", "escape", "V":
+		m.currentView = ViewDuel
+		m.statusMessage = ""
+	}
+
+	return m, nil
+}
+
+// handleGroupVoteSubmit enregistre le vote partagé : l'Elo est mis à jour avec
+// la part des voix de chaque côté comme score réel (ex: 3 contre 2 = 0.6 / 0.4)
+func (m Model) handleGroupVoteSubmit() (tea.Model, tea.Cmd) {
+	left, right := m.groupVotes[FocusLeft], m.groupVotes[FocusRight]
+	if left+right == 0 {
+		m.statusMessage = "Aucune voix saisie"
+		return m, nil
+	}
+
+	leftScore := float64(left) / float64(left+right)
+	changes, err := m.eloSystem.ProcessWeightedDuel(m.leftTrack.Track.ID, m.rightTrack.Track.ID, leftScore)
+	if err != nil {
+		return m, m.sendError(fmt.Errorf("erreur traitement vote en groupe: %w", err))
+	}
+
+	m.currentView = ViewDuel
+	winner := elo.ScoreResult(leftScore)
+	var outcome *voteOutcome
+	var status string
+	switch winner {
+	case models.WinnerLeft:
+		outcome = &voteOutcome{winnerID: m.leftTrack.Track.ID, loserID: m.rightTrack.Track.ID}
+		status = fmt.Sprintf("👥 %s l'emporte %d-%d !", m.leftTrack.Track.Name, left, right) + formatEloDeltas(changes, winner)
+	case models.WinnerRight:
+		outcome = &voteOutcome{winnerID: m.rightTrack.Track.ID, loserID: m.leftTrack.Track.ID}
+		status = fmt.Sprintf("👥 %s l'emporte %d-%d !", m.rightTrack.Track.Name, right, left) + formatEloDeltas(changes, winner)
+	default:
+		status = fmt.Sprintf("👥 Égalité %d-%d", left, right) + formatEloDeltas(changes, models.WinnerLeft)
+	}
+
+	return m.finishVote(changes, winner, outcome, status)
+}
+
+// renderGroupVote affiche les voix de chaque côté et le score qui en résulte
+func (m Model) renderGroupVote() string {
+	if m.leftTrack == nil || m.rightTrack == nil {
+		return m.renderLoading()
+	}
+
+	sideStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorBorder).
+		Padding(0, 2).
+		Width(m.layout().CardWidth).
+		Align(lipgloss.Center)
+
+	focusedStyle := sideStyle.
+		BorderForeground(ColorPrimary)
+
+	countStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true)
+
+	side := func(position FocusPosition, track *models.TrackWithRating) string {
+		style := sideStyle
+		if m.groupSide == position {
+			style = focusedStyle
+		}
+		return style.Render(lipgloss.JoinVertical(
+			lipgloss.Center,
+			Truncate(trackTitle(track.Track), m.layout().CardWidth-4),
+			lipgloss.NewStyle().Foreground(ColorMuted).Render(Truncate(track.Track.Artist, m.layout().CardWidth-4)),
+			"",
+			countStyle.Render(fmt.Sprintf("%d votes", m.groupVotes[position])),
+		))
+	}
+
+	left, right := m.groupVotes[FocusLeft], m.groupVotes[FocusRight]
+	split := "Enter each side's votes"
+	if left+right > 0 {
+		share := float64(left) / float64(left+right)
+		split = fmt.Sprintf("Score %.0f%% / %.0f%%", share*100, (1-share)*100)
+	}
+
+	controls := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		Padding(1, 0).
+		Render("←→ side  ↑↓ or 0-9 votes  ↵ record  q cancel")
+
+	sides := lipgloss.JoinHorizontal(lipgloss.Center, side(FocusLeft, m.leftTrack), "   ", side(FocusRight, m.rightTrack))
+	if m.vertical() {
+		sides = lipgloss.JoinVertical(lipgloss.Center, side(FocusLeft, m.leftTrack), side(FocusRight, m.rightTrack))
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		RenderHeader(),
+		"",
+		lipgloss.NewStyle().Foreground(ColorPrimary).Bold(true).Render("👥 Group vote"),
+		"",
+		sides,
+		"",
+		split,
+		controls,
+		RenderFooter(m.statusMessage),
+	)
+}
@@ -0,0 +1,150 @@
+package ui
+
+import (
+	"fmt"
+	"songbattle/internal/models"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// historyLimit est le nombre de duels chargés dans l'historique
+const historyLimit = 200
+
+// handleShowHistory affiche les derniers duels avec la variation d'Elo de chaque côté
+func (m Model) handleShowHistory() (tea.Model, tea.Cmd) {
+	history, err := m.db.GetDuelHistoryDetailed(historyLimit)
+	if err != nil {
+		m.statusMessage = "⚠️  Impossible de charger l'historique"
+		return m, nil
+	}
+
+	m.history = history
+	m.historyCursor = 0
+	m.currentView = ViewHistory
+	return m, nil
+}
+
+// handleHistoryKey gère le clavier dans l'historique (défilement et retour uniquement)
+func (m Model) handleHistoryKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "up", "k":
+		if m.historyCursor > 0 {
+			m.historyCursor--
+		}
+
+	case "down", "j":
+		if m.historyCursor < len(m.history)-1 {
+			m.historyCursor++
+		}
+
+	case "q", "esc", "escape", "y":
+		m.history = nil
+		m.currentView = ViewDuel
+		m.statusMessage = "Back to battles"
+	}
+
+	return m, nil
+}
+
+// renderHistory affiche l'historique des duels, le plus récent en haut
+func (m Model) renderHistory() string {
+	if len(m.history) == 0 {
+		return lipgloss.JoinVertical(
+			lipgloss.Center,
+			RenderHeader(),
+			"",
+			"No battles yet - vote in a battle to start your history",
+			"",
+			"Press Escape to return",
+		)
+	}
+
+	whenStyle := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		Width(13)
+
+	nameStyle := lipgloss.NewStyle().
+		Width(32)
+
+	versusStyle := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		Width(4)
+
+	selectedStyle := lipgloss.NewStyle().
+		Background(ColorPrimary).
+		Foreground(lipgloss.Color("#000000")).
+		Bold(true)
+
+	// Fenêtre de LeaderboardRows lignes centrée sur le curseur, comme le classement
+	rows := m.layout().LeaderboardRows
+	start, end := scrollWindow(m.historyCursor, len(m.history), rows)
+
+	var lines []string
+	for i := start; i < end; i++ {
+		entry := &m.history[i]
+
+		line := lipgloss.JoinHorizontal(
+			lipgloss.Top,
+			whenStyle.Render(historyTime(entry.CreatedAt)),
+			nameStyle.Render(historySide(entry, entry.Left, entry.LeftDelta())),
+			versusStyle.Render("vs"),
+			nameStyle.Render(historySide(entry, entry.Right, entry.RightDelta())),
+		)
+		if i == m.historyCursor {
+			line = selectedStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	controls := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		Padding(1, 0).
+		Render("↑↓ scroll  q back  •  u in a battle undoes the top row")
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		RenderHeader(),
+		"",
+		lipgloss.NewStyle().Foreground(ColorPrimary).Bold(true).Render(
+			fmt.Sprintf("📜 Last %d battles", len(m.history))),
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, lines...),
+		controls,
+		RenderFooter(m.statusMessage),
+	)
+}
+
+// historySide affiche un côté d'un duel : nom (🏆 pour le vainqueur) et variation d'Elo
+func historySide(entry *models.DuelHistoryEntry, track models.Track, delta int) string {
+	name := Truncate(trackTitle(track), 22)
+	if entry.WinnerTrackID != nil && *entry.WinnerTrackID == track.ID {
+		name = "🏆 " + name
+	}
+
+	if !entry.Rated {
+		return name + lipgloss.NewStyle().Foreground(ColorMuted).Render(" skip")
+	}
+
+	deltaStyle := lipgloss.NewStyle().Foreground(ColorMuted)
+	switch {
+	case delta > 0:
+		deltaStyle = deltaStyle.Foreground(ColorSuccess)
+	case delta < 0:
+		deltaStyle = deltaStyle.Foreground(ColorError)
+	}
+	return name + " " + deltaStyle.Render(fmt.Sprintf("%+d", delta))
+}
+
+// historyTime formate la date d'un duel (heure seule pour aujourd'hui)
+func historyTime(at time.Time) string {
+	now := time.Now()
+	if at.Year() == now.Year() && at.YearDay() == now.YearDay() {
+		return at.Local().Format("15:04")
+	}
+	return at.Local().Format("Jan 02 15:04")
+}
@@ -0,0 +1,255 @@
+package ui
+
+import (
+	"fmt"
+	"songbattle/internal/config"
+	"sort"
+	"strings"
+)
+
+// Actions du clavier remappables (section keymap de la configuration)
+// Les vues secondaires (recherche, rejeu, vote de groupe...) gardent leurs propres touches
+const (
+	ActionQuit            = "quit"
+	ActionBack            = "back"
+	ActionFocusLeft       = "focus-left"
+	ActionFocusRight      = "focus-right"
+	ActionUp              = "up"
+	ActionDown            = "down"
+	ActionVote            = "vote"
+	ActionVoteLeft        = "vote-left"
+	ActionVoteRight       = "vote-right"
+	ActionDraw            = "draw"
+	ActionPlay            = "play"
+	ActionSkip            = "skip"
+	ActionReshuffle       = "reshuffle"
+	ActionUndo            = "undo"
+	ActionRequeueLoser    = "requeue-loser"
+	ActionPin             = "pin"
+	ActionRivalry         = "rivalry"
+	ActionFlag            = "flag"
+	ActionAudioFeatures   = "audio-features"
+	ActionOpenSpotify     = "open-spotify"
+	ActionExportPlaylist  = "export-playlist"
+	ActionLeaderboard     = "leaderboard"
+	ActionGenres          = "genres"
+	ActionFlagged         = "flagged"
+	ActionSearch          = "search"
+	ActionStats           = "stats"
+	ActionHistory         = "history"
+	ActionQuickRate       = "quick-rate"
+	ActionPostVoteFocus   = "post-vote-focus"
+	ActionWinProbability  = "win-probability"
+	ActionFairStart       = "fair-start"
+	ActionGroupVote       = "group-vote"
+	ActionYears           = "years"
+	ActionCrossGenres     = "cross-genres"
+	ActionReplay          = "replay"
+	ActionQuickSession    = "quick-session"
+	ActionLibraries       = "libraries"
+	ActionRecommendations = "recommendations"
+	ActionMood            = "mood"
+	ActionContested       = "contested"
+	ActionConservative    = "conservative"
+	ActionSource          = "source"
+	ActionCalibratedOnly  = "calibrated-only"
+	ActionSelectMode      = "select-mode"
+	ActionQueueSelection  = "queue-selection"
+	ActionCompare         = "compare"
+	ActionExportSelection = "export-selection"
+)
+
+// defaultKeys liste les touches par défaut de chaque action
+// vote-left et vote-right n'ont pas de touche par défaut : on vote pour le track avec le focus
+var defaultKeys = map[string][]string{
+	ActionQuit:            {"q"},
+	ActionBack:            {"esc", "escape"},
+	ActionFocusLeft:       {"left", "h"},
+	ActionFocusRight:      {"right", "l"},
+	ActionUp:              {"up", "k"},
+	ActionDown:            {"down", "j"},
+	ActionVote:            {"enter"},
+	ActionVoteLeft:        nil,
+	ActionVoteRight:       nil,
+	ActionDraw:            {"D"},
+	ActionPlay:            {" "},
+	ActionSkip:            {"s"},
+	ActionReshuffle:       {"n"},
+	ActionUndo:            {"u"},
+	ActionRequeueLoser:    {"a"},
+	ActionPin:             {"*"},
+	ActionRivalry:         {"w"},
+	ActionFlag:            {"b"},
+	ActionAudioFeatures:   {"t"},
+	ActionOpenSpotify:     {"g"},
+	ActionExportPlaylist:  {"p"},
+	ActionLeaderboard:     {"c"},
+	ActionGenres:          {"f"},
+	ActionFlagged:         {"v"},
+	ActionSearch:          {"/"},
+	ActionStats:           {"i"},
+	ActionHistory:         {"y"},
+	ActionQuickRate:       {"r"},
+	ActionPostVoteFocus:   {"o"},
+	ActionWinProbability:  {"%"},
+	ActionFairStart:       {"H"},
+	ActionGroupVote:       {"V"},
+	ActionYears:           {"Y"},
+	ActionCrossGenres:     {"G"},
+	ActionReplay:          {"P"},
+	ActionQuickSession:    {"Q"},
+	ActionLibraries:       {"L"},
+	ActionRecommendations: {"R"},
+	ActionMood:            {"M"},
+	ActionContested:       {"x"},
+	ActionConservative:    {"B"},
+	ActionSource:          {"z"},
+	ActionCalibratedOnly:  {"C"},
+	ActionSelectMode:      {"m"},
+	ActionQueueSelection:  {"d"},
+	ActionCompare:         {"="},
+	ActionExportSelection: {"e"},
+}
+
+// reservedKeys ne peuvent pas être remappées : ctrl+c quitte toujours
+var reservedKeys = map[string]bool{"ctrl+c": true}
+
+// keyLabels sont les symboles affichés pour les touches nommées
+var keyLabels = map[string]string{
+	"left":  "←",
+	"right": "→",
+	"up":    "↑",
+	"down":  "↓",
+	"enter": "↵",
+	" ":     "␣",
+}
+
+// Keymap associe les touches aux actions du clavier
+type Keymap struct {
+	actions map[string]string   // Touche -> action
+	keys    map[string][]string // Action -> touches, dans l'ordre de la configuration
+}
+
+// DefaultKeymap retourne les touches par défaut
+func DefaultKeymap() Keymap {
+	keymap, _ := NewKeymap(nil)
+	return keymap
+}
+
+// NewKeymap applique les touches configurées aux touches par défaut
+// Une action inconnue, une touche réservée ou une touche partagée par deux actions est une erreur
+func NewKeymap(overrides config.KeymapConfig) (Keymap, error) {
+	keymap := Keymap{actions: map[string]string{}, keys: map[string][]string{}}
+	for action, keys := range defaultKeys {
+		keymap.keys[action] = keys
+	}
+
+	for action, keys := range overrides {
+		if _, ok := defaultKeys[action]; !ok {
+			return DefaultKeymap(), fmt.Errorf("action inconnue %q (actions: %s)", action, strings.Join(keymapActions(), ", "))
+		}
+		keymap.keys[action] = keys
+	}
+
+	// Parcours trié : le message d'un conflit ne dépend pas de l'ordre de la map
+	for _, action := range keymapActions() {
+		for _, key := range keymap.keys[action] {
+			if key == "" || reservedKeys[key] {
+				return DefaultKeymap(), fmt.Errorf("touche %q non assignable (%s)", key, action)
+			}
+			if other, ok := keymap.actions[key]; ok && other != action {
+				return DefaultKeymap(), fmt.Errorf("touche %q assignée à %s et à %s", key, other, action)
+			}
+			keymap.actions[key] = action
+		}
+	}
+
+	return keymap, nil
+}
+
+// keymapActions retourne les noms des actions, triés
+func keymapActions() []string {
+	actions := make([]string, 0, len(defaultKeys))
+	for action := range defaultKeys {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+// action retourne l'action déclenchée par une touche ("" si aucune)
+func (k Keymap) action(key string) string {
+	return k.actions[key]
+}
+
+// label retourne la touche principale d'une action telle qu'affichée ("" si l'action n'a pas de touche)
+func (k Keymap) label(action string) string {
+	keys := k.keys[action]
+	if len(keys) == 0 {
+		return ""
+	}
+	if label, ok := keyLabels[keys[0]]; ok {
+		return label
+	}
+	return keys[0]
+}
+
+// hint formate une aide "touche libellé" pour les barres de contrôles, vide si l'action n'a pas de touche
+func (k Keymap) hint(action, text string) string {
+	label := k.label(action)
+	if label == "" {
+		return ""
+	}
+	return label + " " + text
+}
+
+// hints assemble des aides "touche libellé" (paires action, libellé) en sautant les actions sans touche
+func (k Keymap) hints(pairs ...string) string {
+	hints := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		hints = append(hints, k.hint(pairs[i], pairs[i+1]))
+	}
+	return joinHints(hints...)
+}
+
+// navigationHint formate l'aide des touches de déplacement (ex: "↑↓ navigate")
+func (k Keymap) navigationHint(first, second, text string) string {
+	label := k.navigationLabel(first, second)
+	if label == "" {
+		return ""
+	}
+	return label + " " + text
+}
+
+// joinHints sépare les aides par deux espaces en sautant les aides vides
+func joinHints(hints ...string) string {
+	shown := make([]string, 0, len(hints))
+	for _, hint := range hints {
+		if hint != "" {
+			shown = append(shown, hint)
+		}
+	}
+	return strings.Join(shown, "  ")
+}
+
+// navigationLabel retourne les touches de déplacement affichées ensemble : "←→" pour des flèches,
+// "a/b" sinon ("" si l'une des deux actions n'a pas de touche)
+func (k Keymap) navigationLabel(first, second string) string {
+	a, b := k.label(first), k.label(second)
+	if a == "" || b == "" {
+		return ""
+	}
+	if isArrow(a) && isArrow(b) {
+		return a + b
+	}
+	return a + "/" + b
+}
+
+// isArrow indique si un libellé est une flèche
+func isArrow(label string) bool {
+	switch label {
+	case "←", "→", "↑", "↓":
+		return true
+	}
+	return false
+}
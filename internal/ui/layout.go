@@ -0,0 +1,104 @@
+package ui
+
+import (
+	"songbattle/internal/config"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// LayoutConfig regroupe les dimensions de l'interface (cards, fenêtre du classement)
+type LayoutConfig = config.LayoutConfig
+
+// Dimensions minimales restant lisibles
+const (
+	minCardWidth       = 24
+	minCardHeight      = 8 // Contenu de la card (6 lignes) + padding
+	minLeaderboardRows = 5
+
+	// Lignes du classement tant que la hauteur du terminal est inconnue (leaderboard_rows: 0)
+	defaultLeaderboardRows = 15
+
+	// Lignes occupées autour du tableau du classement (header, contrôles, footer)
+	leaderboardChrome = 12
+
+	// Largeur en dessous de laquelle les cards sont empilées (orientation auto)
+	minHorizontalWidth = 2*minCardWidth + versusWidth
+)
+
+// vertical indique si les cards de duel sont empilées plutôt que côte à côte
+func (m Model) vertical() bool {
+	switch m.layoutConfig.Orientation {
+	case config.OrientationVertical:
+		return true
+	case config.OrientationHorizontal:
+		return false
+	}
+	return m.width > 0 && m.width < minHorizontalWidth
+}
+
+// layout adapte la configuration à la taille actuelle du terminal
+func (m Model) layout() LayoutConfig {
+	layout := m.layoutConfig
+
+	// Les deux cards et la colonne VS doivent tenir dans la largeur (une seule card empilée)
+	fit := (m.width - versusWidth) / 2
+	if m.vertical() {
+		fit = m.width - 2 // Bordures de la card
+	}
+	if m.width > 0 && layout.CardWidth > fit {
+		layout.CardWidth = fit
+	}
+
+	// Le classement occupe toute la hauteur disponible, dans la limite de leaderboard_rows s'il est défini
+	if fit := m.height - leaderboardChrome; m.height > 0 && (layout.LeaderboardRows == 0 || layout.LeaderboardRows > fit) {
+		layout.LeaderboardRows = fit
+	} else if layout.LeaderboardRows == 0 {
+		layout.LeaderboardRows = defaultLeaderboardRows
+	}
+
+	if layout.CardWidth < minCardWidth {
+		layout.CardWidth = minCardWidth
+	}
+	if layout.CardHeight < minCardHeight {
+		layout.CardHeight = minCardHeight
+	}
+	if layout.LeaderboardRows < minLeaderboardRows {
+		layout.LeaderboardRows = minLeaderboardRows
+	}
+
+	return layout
+}
+
+// joinCards place les deux cards de duel côte à côte, ou l'une sous l'autre en orientation verticale,
+// séparées par le VS
+func (m Model) joinCards(layout LayoutConfig, left, right string) string {
+	if m.vertical() {
+		return lipgloss.JoinVertical(lipgloss.Center, left, RenderVersusRow(), right)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Center, left, RenderVersus(layout), right)
+}
+
+// duelWidth retourne la largeur de la zone de duel, sur laquelle header et contrôles sont centrés
+func (m Model) duelWidth(layout LayoutConfig) int {
+	if m.vertical() {
+		return layout.CardWidth + 2 // Bordures de la card
+	}
+	return 2*layout.CardWidth + versusWidth
+}
+
+// scrollWindow retourne la plage [start, end) des lignes visibles d'une liste de total lignes,
+// avec le curseur centré (marge de la moitié de la fenêtre) sauf en début et fin de liste
+func scrollWindow(cursor, total, rows int) (start, end int) {
+	if total <= rows {
+		return 0, total
+	}
+
+	start = cursor - rows/2
+	if start < 0 {
+		start = 0
+	}
+	if start+rows > total {
+		start = total - rows
+	}
+	return start, start + rows
+}
@@ -0,0 +1,176 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"songbattle/internal/config"
+	"songbattle/internal/logging"
+	"songbattle/internal/store"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// SetDBPath indique le chemin de la base ouverte, pour repérer la bibliothèque courante
+func (m *Model) SetDBPath(path string) {
+	m.dbPath = path
+}
+
+// DB retourne la base ouverte, qui change après un changement de bibliothèque
+func (m Model) DB() *store.DB {
+	return m.db
+}
+
+// isCurrentLibrary indique si la bibliothèque correspond à la base ouverte
+func (m Model) isCurrentLibrary(library config.LibraryConfig) bool {
+	current, err := filepath.Abs(m.dbPath)
+	if err != nil {
+		return false
+	}
+	path, err := filepath.Abs(library.ResolvedPath())
+	return err == nil && path == current
+}
+
+// handleShowLibraries affiche le sélecteur des bibliothèques déclarées dans la configuration
+func (m Model) handleShowLibraries() (tea.Model, tea.Cmd) {
+	if len(m.cfg.Libraries) == 0 {
+		m.statusMessage = "Aucune bibliothèque configurée (section libraries: du fichier de configuration)"
+		return m, nil
+	}
+
+	m.libraryCursor = 0
+	for i, library := range m.cfg.Libraries {
+		if m.isCurrentLibrary(library) {
+			m.libraryCursor = i
+			break
+		}
+	}
+
+	m.currentView = ViewLibraries
+	m.statusMessage = ""
+	return m, nil
+}
+
+// handleLibraryKey gère le clavier du sélecteur de bibliothèques
+func (m Model) handleLibraryKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "up", "k":
+		if m.libraryCursor > 0 {
+			m.libraryCursor--
+		}
+
+	case "down", "j":
+		if m.libraryCursor < len(m.cfg.Libraries)-1 {
+			m.libraryCursor++
+		}
+
+	case "enter":
+		return m.switchLibrary(m.cfg.Libraries[m.libraryCursor])
+
+	case "q", "esc", "escape", "L":
+		m.currentView = ViewDuel
+		m.statusMessage = ""
+	}
+
+	return m, nil
+}
+
+// switchLibrary ouvre une autre base et reconstruit les composants qui en dépendent
+// (Elo, matchmaking, statistiques, session) ; la connexion Spotify est conservée
+// L'ancienne base n'est fermée qu'une fois la nouvelle ouverte
+func (m Model) switchLibrary(library config.LibraryConfig) (tea.Model, tea.Cmd) {
+	if m.isCurrentLibrary(library) {
+		m.currentView = ViewDuel
+		m.statusMessage = "📚 " + library.Name + " est déjà ouverte"
+		return m, nil
+	}
+
+	// Les opérations de fond écrivent dans la base courante : attendre leur fin
+	if m.inFlight > 0 {
+		m.statusMessage = "⏳ Opération en cours, changez de bibliothèque une fois terminée"
+		return m, nil
+	}
+
+	db, err := store.NewDB(library.ResolvedPath())
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("⚠️  Impossible d'ouvrir %s: %v", library.Name, err)
+		return m, nil
+	}
+
+	// Le token Spotify suit l'utilisateur d'une bibliothèque à l'autre
+	if err := m.auth.SwitchDB(db); err != nil {
+		logging.Printf("[library] token not copied to %s: %v", library.Path, err)
+	}
+
+	next := newModelWithAuth(db, m.auth, m.clientID, m.cfg)
+	next.spotifyClient = m.spotifyClient
+	next.offline = m.offline
+	next.width = m.width
+	next.height = m.height
+	next.dbPath = library.ResolvedPath()
+	next.currentView = ViewDuel
+	next.statusMessage = "📚 Bibliothèque " + library.Name
+
+	if err := m.EndSession(); err != nil {
+		logging.Printf("[library] saving session of %s: %v", m.dbPath, err)
+	}
+	if err := m.db.Close(); err != nil {
+		logging.Printf("[library] closing %s: %v", m.dbPath, err)
+	}
+
+	return *next, next.setupNextDuel
+}
+
+// renderLibraries affiche les bibliothèques configurées, la bibliothèque ouverte étant repérée
+func (m Model) renderLibraries() string {
+	nameStyle := lipgloss.NewStyle().
+		Foreground(ColorSecondary).
+		Width(24)
+
+	pathStyle := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		Width(50)
+
+	selectedStyle := lipgloss.NewStyle().
+		Background(ColorPrimary).
+		Foreground(lipgloss.Color("#000000")).
+		Bold(true)
+
+	var lines []string
+	for i, library := range m.cfg.Libraries {
+		marker := "  "
+		if m.isCurrentLibrary(library) {
+			marker = "● "
+		}
+
+		line := lipgloss.JoinHorizontal(
+			lipgloss.Top,
+			marker,
+			nameStyle.Render(Truncate(library.Name, 22)),
+			pathStyle.Render(Truncate(library.Path, 48)),
+		)
+		if i == m.libraryCursor {
+			line = selectedStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	controls := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		Padding(1, 0).
+		Render("↑↓ navigate  ↵ open  q back  •  ● current library")
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		RenderHeader(),
+		"",
+		lipgloss.NewStyle().Foreground(ColorPrimary).Bold(true).Render("📚 Libraries"),
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, lines...),
+		controls,
+		RenderFooter(m.statusMessage),
+	)
+}
@@ -2,9 +2,13 @@ package ui
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"songbattle/internal/auth"
+	"songbattle/internal/config"
 	"songbattle/internal/elo"
+	"songbattle/internal/export"
+	"songbattle/internal/logging"
 	"songbattle/internal/matchmaker"
 	"songbattle/internal/models"
 	"songbattle/internal/spotify"
@@ -25,6 +29,21 @@ const (
 	ViewLoading
 	ViewError
 	ViewLeaderboard
+	ViewGenres
+	ViewFlagged
+	ViewSearch
+	ViewSessionSummary
+	ViewStats
+	ViewImportNeeded
+	ViewOnboarding
+	ViewQuickRate
+	ViewHistory
+	ViewCompare
+	ViewLibraries
+	ViewGroupVote
+	ViewYears
+	ViewCrossGenres
+	ViewReplay
 )
 
 // FocusPosition représente quel élément a le focus
@@ -35,11 +54,42 @@ const (
 	FocusRight
 )
 
+// PostVoteFocus définit où placer le focus au duel suivant un vote
+type PostVoteFocus string
+
+const (
+	PostVoteFocusWinner    PostVoteFocus = "keep-winner-side" // Focus sur le côté choisi
+	PostVoteFocusLeft      PostVoteFocus = "always-left"      // Focus toujours à gauche
+	PostVoteFocusAlternate PostVoteFocus = "alternate"        // Focus alterné à chaque duel
+)
+
+// postVoteFocusModes liste les modes dans l'ordre de rotation de la touche 'o'
+var postVoteFocusModes = []PostVoteFocus{PostVoteFocusWinner, PostVoteFocusLeft, PostVoteFocusAlternate}
+
+// leaderboardMode distingue les sous-vues du classement
+type leaderboardMode int
+
+const (
+	leaderboardByElo        leaderboardMode = iota
+	leaderboardContested                    // Tracks au taux de victoire proche de 50%
+	leaderboardConservative                 // Classement par borne basse de l'Elo (Elo - 2 RD)
+)
+
+// OnlineCheckTimeout est le délai du test de connexion à Spotify au lancement
+const OnlineCheckTimeout = 3 * time.Second
+
+// playlistExportSize est le nombre de meilleurs tracks exportés par la touche p
+const playlistExportSize = 50
+
+// contestedMinBattles est le nombre minimal de duels pour qu'un track soit jugé disputé
+const contestedMinBattles = 6
+
 // Model représente le modèle principal de l'application Bubble Tea
 type Model struct {
 	// État de la vue
-	currentView ViewState
-	focus       FocusPosition
+	currentView   ViewState
+	focus         FocusPosition
+	postVoteFocus PostVoteFocus
 
 	// Composants du système
 	db            *store.DB
@@ -51,11 +101,21 @@ type Model struct {
 	// Configuration
 	clientID string
 	ctx      context.Context
+	cfg      *config.Config // Pour reconstruire les composants en changeant de bibliothèque
+	dbPath   string         // Chemin de la base ouverte
 
 	// État du duel actuel
 	leftTrack  *models.TrackWithRating
 	rightTrack *models.TrackWithRating
 
+	// Vote en groupe : voix de chaque côté (indexées par FocusPosition) et côté en saisie
+	groupVotes [2]int
+	groupSide  FocusPosition
+
+	// Probabilité de victoire du track de gauche au début du duel (touche %)
+	leftWinProbability float64
+	showWinProbability bool
+
 	// Messages et état
 	statusMessage string
 	errorMessage  string
@@ -66,11 +126,130 @@ type Model struct {
 	height int
 
 	// Audio features pour l'affichage détaillé
-	currentAudioFeatures map[string]float64
+	currentAudioFeatures   map[string]float64
+	audioFeaturesEstimated bool           // Caractéristiques affichées estimées à partir de l'extrait
+	audioAverages          *audioAverages // Moyenne de la bibliothèque (nil = pas encore calculée)
 
 	// Leaderboard
 	leaderboard       []models.TrackWithRating
 	leaderboardCursor int
+	leaderboardGenre  string // Genre filtré ("" = classement global)
+	leaderboardSource string // Source d'import filtrée ("" = toutes)
+	leaderboardMood   string // Humeur filtrée ("" = toutes)
+	leaderboardMode   leaderboardMode
+
+	// Classement limité aux tracks calibrés (touche C) : leaderboardAll est le classement
+	// chargé, leaderboard celui affiché sans les leaderboardCalibrating tracks en calibration
+	calibratedOnly         bool
+	leaderboardAll         []models.TrackWithRating
+	leaderboardCalibrating int
+
+	// Sélection multiple pour l'export (conservée en défilant et entre sous-vues)
+	leaderboardSelecting bool
+	leaderboardSelected  map[int64]bool
+
+	// Sélecteur de genres
+	genres      []string
+	genreCursor int
+
+	// Choix des genres opposés en mode inter-genres (crossGenreFirst = genre de gauche déjà choisi)
+	crossGenreCursor int
+	crossGenreFirst  string
+
+	// Rejeu de l'historique des duels
+	replay replay
+
+	// Intervalle de sauvegarde des compteurs de session (0 = désactivé)
+	autosaveInterval time.Duration
+
+	// Tracks marqués pour réécoute
+	flaggedTracks []models.TrackWithRating
+	flaggedCursor int
+
+	// Historique des duels
+	history       []models.DuelHistoryEntry
+	historyCursor int
+
+	// Champions par décennie et par année
+	yearRows   []yearRow
+	yearCursor int
+
+	// Comparaison de deux tracks du classement
+	compare *trackComparison
+
+	// Sélecteur de bibliothèques
+	libraryCursor int
+
+	// Recherche de tracks
+	searchQuery   string
+	searchResults []*models.Track
+	searchCursor  int
+	searchEditing bool
+
+	// Statistiques Elo
+	eloStats        map[string]interface{}
+	eloHistogram    map[int]int
+	sessionBusiest  []sessionAppearance
+	sessionDuelSpan [2]int                   // Apparitions min et max d'un track pendant la session
+	mostSkipped     []models.TrackWithRating // Tracks les plus passés sans vote, depuis toujours
+
+	// Résumé de la bibliothèque affiché sous le header du duel
+	libraryStats libraryStats
+
+	// Objectif quotidien affiché dans le footer du duel
+	dailyGoal dailyGoal
+
+	// Dernier vote, pour redonner une chance au perdant avant le duel suivant
+	lastVote     *voteOutcome
+	requeueLoser bool
+
+	// Animation de l'Elo après un vote
+	animate      bool
+	eloAnimation eloAnimation
+
+	// Lecture des extraits
+	playback        config.PlaybackConfig
+	playbackStarted time.Time // Début de la dernière lecture, pour la pause automatique
+	playingURI      string    // Track lancé par l'application ("" si aucun ou déjà mis en pause)
+	listenStart     time.Time // Première lecture du duel en cours, pour pondérer le vote par l'écoute
+
+	// Options d'export de playlist
+	exportConfig config.ExportConfig
+
+	// Dimensions configurées de l'affichage (voir layout())
+	layoutConfig LayoutConfig
+
+	// Touches des actions du clavier (section keymap de la configuration)
+	keys Keymap
+
+	// Bilan de session affiché en quittant
+	session            sessionStats
+	sessionSummary     *SessionSummary
+	showSessionSummary bool
+
+	// Session rapide : bilan après un nombre fixé de duels (nil hors session rapide)
+	quickSession *quickSession
+	quickRounds  int // Duels d'une session rapide (ui.rounds ou -rounds, 0 = valeur par défaut)
+
+	// Spotify injoignable au lancement (client nil)
+	offline bool
+
+	// Opérations de fond en cours (export, import) et confirmation de sortie
+	inFlight    int
+	quitPending bool
+
+	// Elo masqué des tracks en calibration (touche H)
+	fairStart        bool
+	fairStartBattles int
+
+	// Introduction du premier lancement
+	showIntro  bool
+	onboarding onboarding
+
+	// Notation rapide des tracks jamais joués
+	quickRateQueue []models.TrackWithRating
+	quickRateIndex int
+	quickRateCount int
 }
 
 // NewModel crée une nouvelle instance du modèle
@@ -80,40 +259,133 @@ func NewModel(db *store.DB, clientID string) *Model {
 
 // NewModelWithOptions crée une nouvelle instance du modèle avec des options d'URI
 func NewModelWithOptions(db *store.DB, clientID, redirectURI string, useCustom, useHTTPS bool) *Model {
+	return NewModelWithConfig(db, clientID, redirectURI, useCustom, useHTTPS, config.Default())
+}
+
+// NewModelWithConfig crée une nouvelle instance du modèle avec des options d'URI et une configuration
+func NewModelWithConfig(db *store.DB, clientID, redirectURI string, useCustom, useHTTPS bool, cfg *config.Config) *Model {
+	spotifyAuth := auth.NewSpotifyAuthWithOptions(clientID, db, redirectURI, useCustom, useHTTPS)
+	spotifyAuth.ManualAuth = cfg.Auth.ManualAuth
+	if cfg.Export.Public {
+		spotifyAuth.AddScopes(auth.PublicPlaylistScope)
+	}
+
+	return newModelWithAuth(db, spotifyAuth, clientID, cfg)
+}
+
+// newModelWithAuth crée le modèle d'une base avec une authentification Spotify déjà configurée
+func newModelWithAuth(db *store.DB, spotifyAuth *auth.SpotifyAuth, clientID string, cfg *config.Config) *Model {
 	ctx := context.Background()
 
+	// Préférence de focus après un vote (keep-winner-side par défaut)
+	postVoteFocus := PostVoteFocusWinner
+	if value, err := db.GetMeta(models.MetaKeyPostVoteFocus); err == nil && isPostVoteFocus(value) {
+		postVoteFocus = PostVoteFocus(value)
+	}
+
+	// Introduction affichée une seule fois, sauf si désactivée
+	introDone, _ := db.GetMetaBool(models.MetaKeyOnboardingDone, false)
+
+	// Probabilités de victoire affichées sauf si masquées avec '%'
+	showWinProbability, _ := db.GetMetaBool(models.MetaKeyShowWinProbability, true)
+
+	// Classement complet sauf si limité aux tracks calibrés avec 'C'
+	calibratedOnly, _ := db.GetMetaBool(models.MetaKeyCalibratedOnly, false)
+
+	// Instantané des Elo pour le bilan de fin de session
+	tracks, _ := db.GetAllTracksWithRatings()
+
+	// Session rapide lancée d'office avec ui.rounds (ou -rounds)
+	var quick *quickSession
+	if cfg.UI.Rounds > 0 {
+		quick = &quickSession{rounds: cfg.UI.Rounds, stats: newSessionStats(tracks)}
+	}
+
+	// Touches configurées (validées au lancement), sinon touches par défaut
+	keys, err := NewKeymap(cfg.Keymap)
+	if err != nil {
+		logging.Printf("[ui] invalid keymap, using default keys: %v", err)
+	}
+
 	return &Model{
 		currentView:   ViewLoading,
 		focus:         FocusLeft,
+		postVoteFocus: postVoteFocus,
 		db:            db,
-		eloSystem:     elo.NewEloSystem(db),
-		matchmaker:    matchmaker.NewMatchmaker(db),
-		auth:          auth.NewSpotifyAuthWithOptions(clientID, db, redirectURI, useCustom, useHTTPS),
+
+		showWinProbability: showWinProbability,
+		calibratedOnly:     calibratedOnly,
+
+		eloSystem:     elo.NewEloSystemWithConfig(db, cfg.Elo),
+		matchmaker:    matchmaker.NewMatchmakerWithConfig(db, cfg.Matchmaking),
+		auth:          spotifyAuth,
 		clientID:      clientID,
 		ctx:           ctx,
+		cfg:           cfg,
 		statusMessage: "Initialisation...",
 		width:         100,
 		height:        30,
+
+		playback:           cfg.Playback,
+		exportConfig:       cfg.Export,
+		layoutConfig:       cfg.UI.Layout,
+		keys:               keys,
+		libraryStats:       loadLibraryStats(db, tracks),
+		dailyGoal:          loadDailyGoal(db, cfg.UI.DailyGoal),
+		animate:            cfg.UI.Animation,
+		session:            loadSessionStats(db, tracks, cfg.UI.AutosaveInterval > 0),
+		autosaveInterval:   cfg.UI.AutosaveInterval,
+		showSessionSummary: cfg.UI.SessionSummary,
+		showIntro:          !introDone && !cfg.UI.SkipIntro,
+		fairStart:          cfg.UI.FairStart,
+		fairStartBattles:   cfg.UI.FairStartBattles,
+		quickSession:       quick,
+		quickRounds:        cfg.UI.Rounds,
 	}
 }
 
 // Messages personnalisés pour Bubble Tea
 type InitCompleteMsg struct {
 	SpotifyClient *spotify.Client
+	Offline       bool // Spotify injoignable : duels et classement restent disponibles
 }
 type DuelSetupCompleteMsg struct {
-	Left  *models.TrackWithRating
-	Right *models.TrackWithRating
+	Left   *models.TrackWithRating
+	Right  *models.TrackWithRating
+	Queued bool // Duel tiré de la file de duels imposés
 }
 type ErrorMsg struct{ Err error }
+type ImportNeededMsg struct{}
 type PlayTrackMsg struct{ TrackURI string }
-type AudioFeaturesMsg struct{ Features map[string]float64 }
+
+// AudioFeaturesMsg porte les caractéristiques audio du track avec le focus
+// Features est vide quand ni l'API ni l'extrait du track ne permettent de les obtenir
+type AudioFeaturesMsg struct {
+	Features  map[string]float64
+	Estimated bool // Profil grossier estimé à partir de l'extrait de 30 secondes
+}
+
+// PlaybackStartedMsg signale le début de la lecture d'un track par l'application
+type PlaybackStartedMsg struct {
+	StartedAt time.Time
+	URI       string
+}
+
+// SnippetEndMsg signale la fin de l'extrait démarré à StartedAt
+type SnippetEndMsg struct{ StartedAt time.Time }
+
+// BrowserOpenedMsg signale le résultat de l'ouverture d'une URL dans le navigateur
+type BrowserOpenedMsg struct {
+	URL string
+	Err error
+}
 
 // Init initialise le modèle
 func (m Model) Init() tea.Cmd {
 	return tea.Batch(
 		m.initializeApp,
 		tea.EnterAltScreen,
+		m.scheduleAutosave(),
 	)
 }
 
@@ -130,14 +402,37 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case InitCompleteMsg:
 		m.spotifyClient = msg.SpotifyClient
+		m.offline = msg.Offline
 		m.currentView = ViewDuel
+		if m.showIntro {
+			m.currentView = ViewOnboarding
+		}
 		m.isLoading = false
-		return m, m.setupNextDuel
+		if m.offline {
+			m.statusMessage = "📴 Mode hors ligne : lecture et export de playlist désactivés"
+		}
+		// Reprendre le duel laissé en cours au dernier lancement
+		return m, m.resumeDuel
 
 	case DuelSetupCompleteMsg:
+		m.stopEloAnimation()
+		m.listenStart = time.Time{}
+		pause := m.pauseAppPlayback()
+		if m.requeueLoser {
+			model, cmd := m.handleRequeueDuel(msg)
+			return model, tea.Batch(pause, cmd, model.(Model).saveCurrentDuel())
+		}
+		m.lastVote = nil
 		m.leftTrack = msg.Left
 		m.rightTrack = msg.Right
+		m.updateWinProbability()
 		m.statusMessage = "Prêt pour le duel !"
+		return m, tea.Batch(pause, m.saveCurrentDuel())
+
+	case ImportNeededMsg:
+		m.currentView = ViewImportNeeded
+		m.leftTrack = nil
+		m.rightTrack = nil
 		return m, nil
 
 	case ErrorMsg:
@@ -146,11 +441,81 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.isLoading = false
 		return m, nil
 
+	case BrowserOpenedMsg:
+		if msg.Err != nil {
+			m.statusMessage = "⚠️  Navigateur introuvable, ouvrez : " + msg.URL
+		}
+		return m, nil
+
 	case AudioFeaturesMsg:
+		if len(msg.Features) == 0 {
+			m.statusMessage = audioFeaturesUnavailable
+			return m, nil
+		}
 		m.currentView = ViewAudioFeatures
 		m.currentAudioFeatures = msg.Features
+		m.audioFeaturesEstimated = msg.Estimated
+		m.loadAudioAverages()
+		return m, nil
+
+	case SearchResultsMsg:
+		m.searchResults = msg.Results
+		m.searchCursor = 0
+		m.searchEditing = len(msg.Results) == 0
+		m.statusMessage = fmt.Sprintf("%d résultats pour \"%s\"", len(msg.Results), msg.Query)
 		return m, nil
 
+	case TrackAddedMsg:
+		return m.handleTrackAdded(msg)
+
+	case RecommendationsImportedMsg:
+		return m.handleRecommendationsImported(msg)
+
+	case backgroundDoneMsg:
+		return m.handleBackgroundDone(msg)
+
+	case PlaylistExportedMsg:
+		return m.handlePlaylistExported(msg)
+
+	case QuickRatePreviewMsg:
+		return m.handleQuickRatePreview(msg)
+
+	case EloAnimationFrameMsg:
+		return m.handleEloAnimationFrame(msg)
+
+	case ReplayTickMsg:
+		return m.handleReplayTick(msg)
+
+	case SessionAutosaveMsg:
+		return m.handleSessionAutosave()
+
+	case QuickSessionCompleteMsg:
+		return m.handleQuickSessionComplete()
+
+	case TrackUnavailableMsg:
+		return m.handleTrackUnavailable(msg)
+
+	case PlaybackStartedMsg:
+		m.playbackStarted = msg.StartedAt
+		m.playingURI = msg.URI
+		if m.currentView == ViewDuel && m.listenStart.IsZero() {
+			m.listenStart = msg.StartedAt
+		}
+		if m.playback.SnippetLength <= 0 {
+			return m, nil
+		}
+		return m, tea.Tick(m.playback.SnippetLength, func(time.Time) tea.Msg {
+			return SnippetEndMsg{StartedAt: msg.StartedAt}
+		})
+
+	case SnippetEndMsg:
+		// Ignorer si une autre lecture a démarré entre-temps
+		if !msg.StartedAt.Equal(m.playbackStarted) {
+			return m, nil
+		}
+		m.playingURI = ""
+		return m, m.pausePlayback()
+
 	default:
 		return m, nil
 	}
@@ -167,6 +532,36 @@ func (m Model) View() string {
 		return m.renderAudioFeatures()
 	case ViewLeaderboard:
 		return m.renderLeaderboard()
+	case ViewGenres:
+		return m.renderGenres()
+	case ViewFlagged:
+		return m.renderFlagged()
+	case ViewSearch:
+		return m.renderSearch()
+	case ViewSessionSummary:
+		return m.renderSessionSummary()
+	case ViewStats:
+		return m.renderStats()
+	case ViewImportNeeded:
+		return m.renderImportNeeded()
+	case ViewOnboarding:
+		return m.renderOnboarding()
+	case ViewQuickRate:
+		return m.renderQuickRate()
+	case ViewHistory:
+		return m.renderHistory()
+	case ViewCompare:
+		return m.renderCompare()
+	case ViewLibraries:
+		return m.renderLibraries()
+	case ViewGroupVote:
+		return m.renderGroupVote()
+	case ViewYears:
+		return m.renderYears()
+	case ViewCrossGenres:
+		return m.renderCrossGenres()
+	case ViewReplay:
+		return m.renderReplay()
 	case ViewDuel:
 		return m.renderDuel()
 	default:
@@ -176,71 +571,336 @@ func (m Model) View() string {
 
 // handleKeyPress gère les événements clavier
 func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "q", "ctrl+c":
+	if m.currentView == ViewSearch {
+		return m.handleSearchKey(msg)
+	}
+
+	if m.currentView == ViewOnboarding {
+		return m.handleOnboardingKey(msg)
+	}
+
+	if m.currentView == ViewQuickRate {
+		return m.handleQuickRateKey(msg)
+	}
+
+	if m.currentView == ViewHistory {
+		return m.handleHistoryKey(msg)
+	}
+
+	if m.currentView == ViewCompare {
+		return m.handleCompareKey(msg)
+	}
+
+	if m.currentView == ViewLibraries {
+		return m.handleLibraryKey(msg)
+	}
+
+	if m.currentView == ViewGroupVote {
+		return m.handleGroupVoteKey(msg)
+	}
+
+	if m.currentView == ViewYears {
+		return m.handleYearsKey(msg)
+	}
+
+	if m.currentView == ViewCrossGenres {
+		return m.handleCrossGenreKey(msg)
+	}
+
+	if m.currentView == ViewReplay {
+		return m.handleReplayKey(msg)
+	}
+
+	// N'importe quelle touche quitte depuis le bilan de session (Entrée reprend les duels
+	// après le bilan d'une session rapide)
+	if m.currentView == ViewSessionSummary {
+		if m.sessionSummary != nil && m.sessionSummary.Rounds > 0 {
+			return m.handleQuickSummaryKey(msg)
+		}
+		return m, tea.Quit
+	}
+
+	// ctrl+c quitte toujours, quelle que soit la configuration du clavier
+	if msg.String() == "ctrl+c" {
+		return m, tea.Quit
+	}
+
+	action := m.keys.action(msg.String())
+
+	// Toute autre touche annule une demande de sortie en attente
+	if action != ActionQuit {
+		m.quitPending = false
+	}
+
+	switch action {
+	case ActionQuit:
 		// Si dans le leaderboard, 'q' retourne au duel (pas de quit)
-		if m.currentView == ViewLeaderboard {
+		if m.currentView == ViewLeaderboard && m.leaderboardGenre != "" {
+			return m.handleShowGenres()
+		}
+		if m.currentView == ViewLeaderboard && (m.leaderboardMode != leaderboardByElo || m.leaderboardSource != "" || m.leaderboardMood != "") {
+			return m.handleShowLeaderboard()
+		}
+		if m.currentView == ViewLeaderboard || m.currentView == ViewGenres || m.currentView == ViewFlagged || m.currentView == ViewStats {
 			m.currentView = ViewDuel
 			m.statusMessage = ""
 			return m, nil
 		}
-		return m, tea.Quit
+		return m.handleQuit()
 
-	case "left", "h":
+	case ActionFocusLeft:
 		m.focus = FocusLeft
 		return m, nil
 
-	case "right", "l":
+	case ActionFocusRight:
 		m.focus = FocusRight
 		return m, nil
 
-	case "enter":
+	case ActionVoteLeft, ActionVoteRight:
+		// Vote direct, sans passer par le focus
+		if m.currentView != ViewDuel {
+			return m, nil
+		}
+		m.focus = FocusLeft
+		if action == ActionVoteRight {
+			m.focus = FocusRight
+		}
+		return m.handleVote()
+
+	case ActionVote:
 		if m.currentView == ViewLeaderboard {
 			return m.handleLeaderboardSelect()
 		}
+		if m.currentView == ViewGenres {
+			return m.handleGenreSelect()
+		}
 		return m.handleVote()
 
-	case " ":
-		// Dans le leaderboard, jouer le track sélectionné
+	case ActionDraw:
+		if m.currentView != ViewDuel {
+			return m, nil
+		}
+		return m.handleDraw()
+
+	case ActionPlay:
+		// Dans le leaderboard, cocher le track en mode sélection, sinon le jouer
+		if m.currentView == ViewLeaderboard && m.leaderboardSelecting {
+			return m.handleToggleSelection()
+		}
 		if m.currentView == ViewLeaderboard {
 			return m.handlePlayLeaderboardTrack()
 		}
+		if m.currentView == ViewFlagged {
+			return m.handlePlayFlaggedTrack()
+		}
 		// Dans le duel, jouer le track avec le focus
 		return m.handlePlayTrack()
 
-	case "s":
+	case ActionSkip:
 		return m.handleSkip()
 
-	case "t":
-		// Audio features désactivé temporairement (API 403)
-		m.statusMessage = "⚠️  Audio features indisponible (permissions Spotify limitées)"
-		return m, nil
-		// return m.handleShowAudioFeatures()
+	case ActionAudioFeatures:
+		return m.handleShowAudioFeatures()
 
-	case "g":
+	case ActionOpenSpotify:
 		return m.handleOpenSpotify()
 
-	case "p":
+	case ActionExportPlaylist:
 		return m.handleExportPlaylist()
 
-	case "c":
+	case ActionLeaderboard:
 		return m.handleShowLeaderboard()
 
-	case "up", "k":
+	case ActionGenres:
+		return m.handleShowGenres()
+
+	case ActionFlag:
+		if m.currentView == ViewFlagged {
+			return m.handleUnflagTrack()
+		}
+		return m.handleToggleFlag()
+
+	case ActionFlagged:
+		return m.handleShowFlagged()
+
+	case ActionSearch:
+		return m.handleShowSearch()
+
+	case ActionStats:
+		return m.handleShowStats()
+
+	case ActionPostVoteFocus:
+		return m.handleCyclePostVoteFocus()
+
+	case ActionWinProbability:
+		if m.currentView == ViewDuel {
+			return m.handleToggleWinProbability()
+		}
+		return m, nil
+
+	case ActionFairStart:
+		if m.currentView == ViewDuel {
+			return m.handleToggleFairStart()
+		}
+		return m, nil
+
+	case ActionGroupVote:
+		if m.currentView == ViewDuel {
+			return m.handleShowGroupVote()
+		}
+		return m, nil
+
+	case ActionYears:
+		if m.currentView == ViewDuel {
+			return m.handleShowYears()
+		}
+		return m, nil
+
+	case ActionCrossGenres:
+		if m.currentView == ViewDuel {
+			return m.handleShowCrossGenres()
+		}
+		return m, nil
+
+	case ActionReplay:
+		if m.currentView == ViewDuel {
+			return m.handleShowReplay()
+		}
+		return m, nil
+
+	case ActionQuickSession:
+		if m.currentView == ViewDuel {
+			return m.handleToggleQuickSession()
+		}
+		return m, nil
+
+	case ActionContested:
+		if m.currentView == ViewLeaderboard {
+			return m.handleToggleContested()
+		}
+		return m, nil
+
+	case ActionConservative:
+		if m.currentView == ViewLeaderboard {
+			return m.handleToggleConservative()
+		}
+		return m, nil
+
+	case ActionSource:
+		if m.currentView == ViewLeaderboard {
+			return m.handleCycleSource()
+		}
+		return m, nil
+
+	case ActionCalibratedOnly:
+		if m.currentView == ViewLeaderboard {
+			return m.handleToggleCalibratedOnly()
+		}
+		return m, nil
+
+	case ActionMood:
+		if m.currentView == ViewLeaderboard {
+			return m.handleCycleMood()
+		}
+		if m.currentView == ViewDuel {
+			return m.handleCycleBattleMood()
+		}
+		return m, nil
+
+	case ActionSelectMode:
+		if m.currentView == ViewLeaderboard {
+			return m.handleToggleSelectMode()
+		}
+		return m, nil
+
+	case ActionQueueSelection:
+		if m.currentView == ViewLeaderboard {
+			return m.handleQueueSelection()
+		}
+		return m, nil
+
+	case ActionCompare:
+		if m.currentView == ViewLeaderboard {
+			return m.handleShowCompare()
+		}
+		return m, nil
+
+	case ActionExportSelection:
+		if m.currentView == ViewLeaderboard {
+			return m.handleExportSelection()
+		}
+		return m, nil
+
+	case ActionRequeueLoser:
+		if m.currentView == ViewDuel {
+			return m.handleRequeueLoser()
+		}
+		return m, nil
+
+	case ActionReshuffle:
+		if m.currentView == ViewDuel {
+			return m.handleReshuffle()
+		}
+		return m, nil
+
+	case ActionPin:
+		if m.currentView == ViewDuel {
+			return m.handleTogglePin()
+		}
+		return m, nil
+
+	case ActionUndo:
+		if m.currentView == ViewDuel {
+			return m.handleUndo()
+		}
+		return m, nil
+
+	case ActionRivalry:
+		if m.currentView == ViewDuel {
+			return m.handleToggleRivalry()
+		}
+		return m, nil
+
+	case ActionUp:
+		// Cards empilées : le haut et le bas remplacent la gauche et la droite
+		if m.currentView == ViewDuel && m.vertical() {
+			m.focus = FocusLeft
+		}
 		if m.currentView == ViewLeaderboard && m.leaderboardCursor > 0 {
 			m.leaderboardCursor--
 		}
+		if m.currentView == ViewGenres && m.genreCursor > 0 {
+			m.genreCursor--
+		}
+		if m.currentView == ViewFlagged && m.flaggedCursor > 0 {
+			m.flaggedCursor--
+		}
 		return m, nil
 
-	case "down", "j":
+	case ActionDown:
+		if m.currentView == ViewDuel && m.vertical() {
+			m.focus = FocusRight
+		}
 		if m.currentView == ViewLeaderboard && m.leaderboardCursor < len(m.leaderboard)-1 {
 			m.leaderboardCursor++
 		}
+		if m.currentView == ViewGenres && m.genreCursor < len(m.genres)-1 {
+			m.genreCursor++
+		}
+		if m.currentView == ViewFlagged && m.flaggedCursor < len(m.flaggedTracks)-1 {
+			m.flaggedCursor++
+		}
 		return m, nil
 
-	case "escape":
+	case ActionBack:
 		// Return to duel from audio features, error or leaderboard
-		if m.currentView == ViewLeaderboard {
+		if m.currentView == ViewLeaderboard && m.leaderboardGenre != "" {
+			return m.handleShowGenres()
+		}
+		if m.currentView == ViewLeaderboard && (m.leaderboardMode != leaderboardByElo || m.leaderboardSource != "" || m.leaderboardMood != "") {
+			return m.handleShowLeaderboard()
+		}
+		if m.currentView == ViewLeaderboard || m.currentView == ViewGenres || m.currentView == ViewFlagged || m.currentView == ViewStats {
 			m.currentView = ViewDuel
 			m.statusMessage = "Back to battles"
 			return m, nil
@@ -252,19 +912,39 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "r":
+	case ActionHistory:
+		if m.currentView == ViewDuel {
+			return m.handleShowHistory()
+		}
+		return m, nil
+
+	case ActionLibraries:
+		if m.currentView == ViewDuel {
+			return m.handleShowLibraries()
+		}
+		return m, nil
+
+	case ActionRecommendations:
+		if m.currentView == ViewDuel {
+			return m.handleImportRecommendations()
+		}
+		return m, nil
+
+	case ActionQuickRate:
 		// Réessayer (depuis erreur) ou retour
 		if m.currentView == ViewError {
 			m.currentView = ViewDuel
 			m.errorMessage = ""
+			return m, nil
+		}
+		if m.currentView == ViewDuel {
+			return m.handleShowQuickRate()
 		}
 		return m, nil
 
 	default:
 		return m, nil
 	}
-
-	return m, nil
 }
 
 // handleVote traite un vote pour le track avec le focus
@@ -275,29 +955,233 @@ func (m Model) handleVote() (tea.Model, tea.Cmd) {
 
 	var winner string
 	var winnerName string
+	var outcome voteOutcome
 
 	if m.focus == FocusLeft {
 		winner = models.WinnerLeft
 		winnerName = m.leftTrack.Track.Name
+		outcome = voteOutcome{winnerID: m.leftTrack.Track.ID, loserID: m.rightTrack.Track.ID}
 	} else {
 		winner = models.WinnerRight
 		winnerName = m.rightTrack.Track.Name
+		outcome = voteOutcome{winnerID: m.rightTrack.Track.ID, loserID: m.leftTrack.Track.ID}
+	}
+
+	changes, weight, err := m.processVote(winner)
+	if err != nil {
+		return m, m.sendError(fmt.Errorf("erreur traitement duel: %w", err))
+	}
+
+	return m.finishVote(changes, winner, &outcome, "🏆 "+winnerName+" remporte le duel !"+formatEloDeltas(changes, winner)+weight)
+}
+
+// handleDraw enregistre un match nul entre les deux tracks, quand aucun ne l'emporte
+func (m Model) handleDraw() (tea.Model, tea.Cmd) {
+	if m.leftTrack == nil || m.rightTrack == nil {
+		return m, nil
 	}
 
-	// Traiter le duel
-	if err := m.eloSystem.ProcessDuel(m.leftTrack.Track.ID, m.rightTrack.Track.ID, winner); err != nil {
+	changes, weight, err := m.processVote(models.WinnerDraw)
+	if err != nil {
 		return m, m.sendError(fmt.Errorf("erreur traitement duel: %w", err))
 	}
 
-	m.statusMessage = "🏆 " + winnerName + " remporte le duel !"
+	return m.finishVote(changes, models.WinnerDraw, nil, "🤝 Match nul !"+formatEloDeltas(changes, models.WinnerDraw)+weight)
+}
+
+// processVote enregistre le résultat du duel affiché, pondéré par l'écoute si l'option est activée
+// Retourne les changements d'Elo et la mention du poids du vote pour la barre de statut
+func (m Model) processVote(result string) ([]elo.EloChange, string, error) {
+	if !m.cfg.Elo.WeightByListenTime {
+		changes, err := m.eloSystem.ProcessDuel(m.leftTrack.Track.ID, m.rightTrack.Track.ID, result)
+		return changes, "", err
+	}
+
+	confidence := m.voteConfidence()
+	changes, err := m.eloSystem.ProcessDuelWithConfidence(m.leftTrack.Track.ID, m.rightTrack.Track.ID, result, confidence)
+	weight := ""
+	if confidence < 1 {
+		weight = fmt.Sprintf(" • vote à %.0f%% (écoute courte)", confidence*100)
+	}
+	return changes, weight, err
+}
+
+// voteConfidence retourne le poids du vote d'après l'écoute depuis la première lecture du duel
+// Sans lecture, le vote a le poids minimal ; l'écoute complète est ramenée à la durée des extraits
+func (m Model) voteConfidence() float64 {
+	full := m.cfg.Elo.FullListenTime
+	if m.playback.SnippetLength > 0 && m.playback.SnippetLength < full {
+		full = m.playback.SnippetLength
+	}
+
+	var listened time.Duration
+	if !m.listenStart.IsZero() {
+		listened = time.Since(m.listenStart)
+	}
+	return elo.ListenConfidence(listened, full)
+}
 
-	// Préparer le prochain duel après un court délai
-	return m, tea.Sequence(
+// finishVote met à jour la session après un duel joué, anime l'Elo des cards
+// puis prépare le prochain duel ; outcome est nil si le duel n'a pas de perdant
+func (m Model) finishVote(changes []elo.EloChange, winner string, outcome *voteOutcome, status string) (tea.Model, tea.Cmd) {
+	m.session.duels++
+	if m.quickSession != nil {
+		m.quickSession.stats.duels++
+	}
+	m.lastVote = outcome
+	m.requeueLoser = false
+	m.libraryStats = m.refreshLibraryStats()
+	m.statusMessage = status
+	if celebration := m.refreshDailyGoal(); celebration != "" {
+		m.statusMessage = celebration
+	}
+	m.focus = m.nextFocus(winner)
+
+	// Animer l'Elo des cards pendant le délai avant le prochain duel
+	m, animation := m.startEloAnimation(changes)
+
+	// Préparer le prochain duel (ou le bilan de la session rapide) après un court délai
+	return m, tea.Batch(animation, tea.Sequence(
 		tea.Tick(time.Second*2, func(time.Time) tea.Msg {
 			return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("next")}
 		}),
-		m.setupNextDuel,
-	)
+		m.nextDuel(),
+	))
+}
+
+// nextFocus calcule le focus du prochain duel selon la préférence post-vote
+func (m Model) nextFocus(winner string) FocusPosition {
+	switch m.postVoteFocus {
+	case PostVoteFocusLeft:
+		return FocusLeft
+	case PostVoteFocusAlternate:
+		if m.focus == FocusLeft {
+			return FocusRight
+		}
+		return FocusLeft
+	default:
+		if winner == models.WinnerRight {
+			return FocusRight
+		}
+		return FocusLeft
+	}
+}
+
+// handleCyclePostVoteFocus passe au mode de focus post-vote suivant et le sauvegarde
+func (m Model) handleCyclePostVoteFocus() (tea.Model, tea.Cmd) {
+	next := postVoteFocusModes[0]
+	for i, mode := range postVoteFocusModes {
+		if mode == m.postVoteFocus {
+			next = postVoteFocusModes[(i+1)%len(postVoteFocusModes)]
+			break
+		}
+	}
+
+	if err := m.db.SetMeta(models.MetaKeyPostVoteFocus, string(next)); err != nil {
+		m.statusMessage = "⚠️  Impossible de sauvegarder la préférence de focus"
+		return m, nil
+	}
+
+	m.postVoteFocus = next
+	m.statusMessage = "🎯 Focus après vote : " + string(next)
+	return m, nil
+}
+
+// isPostVoteFocus vérifie qu'une valeur sauvegardée correspond à un mode connu
+func isPostVoteFocus(value string) bool {
+	for _, mode := range postVoteFocusModes {
+		if string(mode) == value {
+			return true
+		}
+	}
+	return false
+}
+
+// formatEloDeltas formate les variations d'Elo du gagnant puis du perdant ("+14 / -14"),
+// de gauche puis de droite pour un nul
+func formatEloDeltas(changes []elo.EloChange, winner string) string {
+	if len(changes) != 2 {
+		return ""
+	}
+
+	winnerChange, loserChange := changes[0], changes[1]
+	if winner == models.WinnerRight {
+		winnerChange, loserChange = changes[1], changes[0]
+	}
+
+	return fmt.Sprintf(" %+d / %+d", winnerChange.Change, loserChange.Change)
+}
+
+// handleUndo annule le dernier duel et passe à un autre match
+func (m Model) handleUndo() (tea.Model, tea.Cmd) {
+	duel, changes, err := m.eloSystem.UndoLastDuel()
+	if errors.Is(err, elo.ErrNoUndoHistory) {
+		m.statusMessage = "⚠️  Ce duel date d'avant l'historique des Elo : impossible de l'annuler"
+		return m, nil
+	}
+	if err != nil {
+		return m, m.sendError(fmt.Errorf("erreur annulation duel: %w", err))
+	}
+	if duel == nil {
+		m.statusMessage = "Aucun duel à annuler"
+		return m, nil
+	}
+
+	// Ne pas reproposer tout de suite la paire annulée
+	m.matchmaker.MarkUndone(duel.LeftTrackID, duel.RightTrackID)
+
+	// Le bilan de session ne compte que les duels de cette session
+	skip := duel.Result == models.WinnerSkip
+	if duel.CreatedAt.After(m.session.startedAt) {
+		if !skip && m.session.duels > 0 {
+			m.session.duels--
+		} else if skip && m.session.skips > 0 {
+			m.session.skips--
+		}
+	}
+	if m.quickSession != nil {
+		m.quickSession.undo(duel.CreatedAt, skip)
+	}
+
+	m.libraryStats = m.refreshLibraryStats()
+	m.refreshDailyGoal()
+	m.lastVote = nil
+	m.requeueLoser = false
+	m.statusMessage = "↩️  " + m.undoneLabel(duel, changes)
+	return m, m.setupNextDuel
+}
+
+// undoneLabel décrit le duel annulé selon son résultat, avec l'Elo rendu à chaque track
+func (m Model) undoneLabel(duel *models.LoggedDuel, changes []elo.EloChange) string {
+	first, second := duel.LeftTrackID, duel.RightTrackID
+	if duel.Result == models.WinnerRight {
+		first, second = second, first // Le vainqueur d'abord
+	}
+
+	var label string
+	switch duel.Result {
+	case models.WinnerLeft, models.WinnerRight:
+		label = fmt.Sprintf("Victoire de %s sur %s annulée", m.trackName(first), m.trackName(second))
+	case models.WinnerDraw:
+		label = fmt.Sprintf("Nul %s / %s annulé", m.trackName(first), m.trackName(second))
+	default:
+		return fmt.Sprintf("Skip %s / %s annulé", m.trackName(first), m.trackName(second))
+	}
+
+	deltas := make(map[int64]int, len(changes))
+	for _, change := range changes {
+		deltas[change.TrackID] = change.Change
+	}
+	return label + fmt.Sprintf(" (%+d / %+d)", deltas[first], deltas[second])
+}
+
+// trackName retourne le nom court d'un track pour la barre de statut
+func (m Model) trackName(trackID int64) string {
+	track, err := m.db.GetTrackWithRating(trackID)
+	if err != nil {
+		return "?"
+	}
+	return Truncate(track.Track.Name, 24)
 }
 
 // handleSkip handles a duel skip
@@ -307,16 +1191,48 @@ func (m Model) handleSkip() (tea.Model, tea.Cmd) {
 	}
 
 	// Process skip
-	if err := m.eloSystem.ProcessDuel(m.leftTrack.Track.ID, m.rightTrack.Track.ID, models.WinnerSkip); err != nil {
+	if _, err := m.eloSystem.ProcessDuel(m.leftTrack.Track.ID, m.rightTrack.Track.ID, models.WinnerSkip); err != nil {
 		return m, m.sendError(fmt.Errorf("failed to skip duel: %w", err))
 	}
 
+	m.session.skips++
+	if m.quickSession != nil {
+		m.quickSession.stats.skips++
+	}
+	m.libraryStats = m.refreshLibraryStats()
 	m.statusMessage = "⏭️ Battle skipped!"
+	if celebration := m.refreshDailyGoal(); celebration != "" {
+		m.statusMessage = celebration
+	}
+	return m, m.nextDuel()
+}
+
+// handleReshuffle tire une nouvelle paire sans rien enregistrer
+// (contrairement au skip, qui crée un duel en base et met à jour LastSeenAt)
+func (m Model) handleReshuffle() (tea.Model, tea.Cmd) {
+	if m.leftTrack == nil || m.rightTrack == nil {
+		return m, nil
+	}
+
+	m.statusMessage = "🔀 Nouvelle paire (non enregistré)"
 	return m, m.setupNextDuel
 }
 
+// offlineBlocked signale qu'une action nécessitant Spotify est indisponible hors ligne
+func (m *Model) offlineBlocked() bool {
+	if !m.offline {
+		return false
+	}
+	m.statusMessage = "📴 Hors ligne : action indisponible sans Spotify"
+	return true
+}
+
 // handlePlayTrack traite la lecture d'un track
 func (m Model) handlePlayTrack() (tea.Model, tea.Cmd) {
+	if m.offlineBlocked() {
+		return m, nil
+	}
+
 	var track *models.Track
 	var side string
 	if m.focus == FocusLeft && m.leftTrack != nil {
@@ -333,7 +1249,7 @@ func (m Model) handlePlayTrack() (tea.Model, tea.Cmd) {
 	}
 
 	m.statusMessage = fmt.Sprintf("🎵 Lecture : %s (%s)", track.Name, side)
-	return m, m.playTrack(track.SpotifyURI)
+	return m, m.playTrack(*track)
 }
 
 // handleShowAudioFeatures affiche les caractéristiques audio
@@ -345,56 +1261,308 @@ func (m Model) handleShowAudioFeatures() (tea.Model, tea.Cmd) {
 		track = &m.rightTrack.Track
 	}
 
-	if track == nil {
+	if track == nil {
+		return m, nil
+	}
+
+	return m, m.getAudioFeatures(track.SpotifyID)
+}
+
+// handleOpenSpotify ouvre Spotify dans le navigateur
+func (m Model) handleOpenSpotify() (tea.Model, tea.Cmd) {
+	if m.offlineBlocked() {
+		return m, nil
+	}
+
+	var track *models.Track
+	if m.focus == FocusLeft && m.leftTrack != nil {
+		track = &m.leftTrack.Track
+	} else if m.focus == FocusRight && m.rightTrack != nil {
+		track = &m.rightTrack.Track
+	}
+
+	if track == nil {
+		return m, nil
+	}
+
+	url := "https://open.spotify.com/track/" + track.SpotifyID
+	if auth.IsHeadless() {
+		// Pas de navigateur (SSH) : afficher le lien à ouvrir ailleurs
+		m.statusMessage = "🔗 " + url
+		return m, nil
+	}
+
+	m.statusMessage = "🌐 Ouverture de Spotify dans le navigateur..."
+	return m, openInBrowser(url)
+}
+
+// openInBrowser ouvre une URL dans le navigateur et signale le résultat par BrowserOpenedMsg
+func openInBrowser(url string) tea.Cmd {
+	return func() tea.Msg {
+		return BrowserOpenedMsg{URL: url, Err: browser.OpenURL(url)}
+	}
+}
+
+// handleExportPlaylist exporte le top des tracks en playlist
+func (m Model) handleExportPlaylist() (tea.Model, tea.Cmd) {
+	if m.offlineBlocked() {
+		return m, nil
+	}
+
+	// Un top exporté après quelques duels ne veut encore rien dire
+	progress, err := m.matchmaker.CalibrationProgress()
+	if err == nil {
+		err = export.ValidateCalibration(progress, m.exportConfig.MinCalibration)
+	}
+	if err != nil {
+		m.statusMessage = "⚠️  " + err.Error() + ", continuez les duels (ou -force-export)"
+		return m, nil
+	}
+
+	if m.spotifyClient == nil {
+		m.statusMessage = "⚠️  Export indisponible (client Spotify non initialisé)"
+		return m, nil
+	}
+
+	m.statusMessage = "📝 Export de playlist en cours..."
+	return m.runInBackground(m.exportPlaylist())
+}
+
+// handleShowLeaderboard shows the leaderboard
+func (m Model) handleShowLeaderboard() (tea.Model, tea.Cmd) {
+	// Get all tracks sorted by Elo
+	tracks, err := m.db.GetAllTracksWithRatings()
+	if err != nil {
+		m.statusMessage = "⚠️  Failed to load leaderboard"
+		return m, nil
+	}
+
+	m.setLeaderboard(tracks)
+	m.leaderboardCursor = 0
+	m.leaderboardGenre = ""
+	m.leaderboardSource = ""
+	m.leaderboardMood = ""
+	m.leaderboardMode = leaderboardByElo
+	m.currentView = ViewLeaderboard
+	return m, nil
+}
+
+// handleToggleContested bascule entre le classement et les tracks les plus disputés
+func (m Model) handleToggleContested() (tea.Model, tea.Cmd) {
+	if m.leaderboardMode == leaderboardContested {
+		return m.handleShowLeaderboard()
+	}
+
+	tracks, err := m.db.GetMostContested(500, contestedMinBattles)
+	if err != nil {
+		m.statusMessage = "⚠️  Impossible de charger les tracks disputés"
+		return m, nil
+	}
+
+	m.setLeaderboard(tracks)
+	m.leaderboardCursor = 0
+	m.leaderboardGenre = ""
+	m.leaderboardSource = ""
+	m.leaderboardMood = ""
+	m.leaderboardMode = leaderboardContested
+	return m, nil
+}
+
+// handleShowGenres affiche le sélecteur de genres
+func (m Model) handleShowGenres() (tea.Model, tea.Cmd) {
+	genres, err := m.db.GetGenres()
+	if err != nil {
+		m.statusMessage = "⚠️  Impossible de charger les genres"
+		return m, nil
+	}
+
+	// Conserver la position du curseur en revenant d'un classement par genre
+	if m.genreCursor >= len(genres) {
+		m.genreCursor = 0
+	}
+
+	m.genres = genres
+	m.leaderboardGenre = ""
+	m.currentView = ViewGenres
+	return m, nil
+}
+
+// handleGenreSelect affiche le classement du genre sélectionné
+func (m Model) handleGenreSelect() (tea.Model, tea.Cmd) {
+	if len(m.genres) == 0 || m.genreCursor >= len(m.genres) {
+		return m, nil
+	}
+
+	genre := m.genres[m.genreCursor]
+	tracks, err := m.db.GetLeaderboardByGenre(genre, 500)
+	if err != nil {
+		m.statusMessage = "⚠️  Impossible de charger le classement " + genre
+		return m, nil
+	}
+
+	m.setLeaderboard(tracks)
+	m.leaderboardCursor = 0
+	m.leaderboardGenre = genre
+	m.leaderboardSource = ""
+	m.leaderboardMood = ""
+	m.leaderboardMode = leaderboardByElo
+	m.currentView = ViewLeaderboard
+	return m, nil
+}
+
+// handleToggleFlag marque (ou démarque) le track avec le focus pour réécoute
+func (m Model) handleToggleFlag() (tea.Model, tea.Cmd) {
+	var track *models.Track
+	if m.focus == FocusLeft && m.leftTrack != nil {
+		track = &m.leftTrack.Track
+	} else if m.focus == FocusRight && m.rightTrack != nil {
+		track = &m.rightTrack.Track
+	}
+
+	if track == nil {
+		return m, nil
+	}
+
+	flagged, err := m.db.IsTrackFlagged(track.ID)
+	if err != nil {
+		m.statusMessage = "⚠️  Impossible de lire la liste de réécoute"
+		return m, nil
+	}
+
+	if flagged {
+		if err := m.db.UnflagTrack(track.ID); err != nil {
+			m.statusMessage = "⚠️  Impossible de retirer le marque-page"
+			return m, nil
+		}
+		m.statusMessage = "🔖 " + track.Name + " retiré de la liste de réécoute"
+		return m, nil
+	}
+
+	if err := m.db.FlagTrack(track.ID); err != nil {
+		m.statusMessage = "⚠️  Impossible de marquer le track"
+		return m, nil
+	}
+	m.statusMessage = "🔖 " + track.Name + " ajouté à la liste de réécoute"
+	return m, nil
+}
+
+// handleTogglePin épingle (ou désépingle) le track avec le focus pour le calibrer
+func (m Model) handleTogglePin() (tea.Model, tea.Cmd) {
+	var track *models.TrackWithRating
+	if m.focus == FocusLeft && m.leftTrack != nil {
+		track = m.leftTrack
+	} else if m.focus == FocusRight && m.rightTrack != nil {
+		track = m.rightTrack
+	}
+
+	if track == nil {
+		return m, nil
+	}
+
+	pin, err := m.db.GetPinnedTrack()
+	if err != nil {
+		m.statusMessage = "⚠️  Impossible de lire le track épinglé"
+		return m, nil
+	}
+
+	if pin != nil && pin.TrackID == track.Track.ID {
+		if err := m.db.UnpinTrack(); err != nil {
+			m.statusMessage = "⚠️  Impossible de désépingler le track"
+			return m, nil
+		}
+		m.statusMessage = "📌 " + track.Track.Name + " désépinglé"
+		return m, nil
+	}
+
+	if err := m.db.PinTrack(track.Track.ID, track.Rating.GetTotalBattles()); err != nil {
+		m.statusMessage = "⚠️  Impossible d'épingler le track"
+		return m, nil
+	}
+	m.statusMessage = "📌 " + track.Track.Name + " épinglé pour les prochains duels"
+	return m, nil
+}
+
+// handleToggleRivalry définit (ou retire) une rivalité entre les deux tracks du duel
+func (m Model) handleToggleRivalry() (tea.Model, tea.Cmd) {
+	if m.leftTrack == nil || m.rightTrack == nil {
+		return m, nil
+	}
+
+	left, right := m.leftTrack.Track, m.rightTrack.Track
+	if m.matchmaker.IsRivalry(left.ID, right.ID) {
+		if err := m.matchmaker.RemoveRivalry(left.ID, right.ID); err != nil {
+			m.statusMessage = "⚠️  Impossible de retirer la rivalité"
+			return m, nil
+		}
+		m.statusMessage = "⚔️ Fin de la rivalité " + left.Name + " / " + right.Name
 		return m, nil
 	}
 
-	return m, m.getAudioFeatures(track.SpotifyID)
+	if err := m.matchmaker.AddRivalry(left.ID, right.ID); err != nil {
+		m.statusMessage = "⚠️  Impossible de définir la rivalité"
+		return m, nil
+	}
+	m.statusMessage = "⚔️ " + left.Name + " et " + right.Name + " s'affronteront régulièrement"
+	return m, nil
 }
 
-// handleOpenSpotify ouvre Spotify dans le navigateur
-func (m Model) handleOpenSpotify() (tea.Model, tea.Cmd) {
-	var track *models.Track
-	if m.focus == FocusLeft && m.leftTrack != nil {
-		track = &m.leftTrack.Track
-	} else if m.focus == FocusRight && m.rightTrack != nil {
-		track = &m.rightTrack.Track
+// handleShowFlagged affiche la liste des tracks marqués pour réécoute
+func (m Model) handleShowFlagged() (tea.Model, tea.Cmd) {
+	tracks, err := m.db.GetFlaggedTracks()
+	if err != nil {
+		m.statusMessage = "⚠️  Impossible de charger la liste de réécoute"
+		return m, nil
 	}
 
-	if track == nil {
+	m.flaggedTracks = tracks
+	m.flaggedCursor = 0
+	m.currentView = ViewFlagged
+	return m, nil
+}
+
+// handleUnflagTrack retire le track sélectionné de la liste de réécoute
+func (m Model) handleUnflagTrack() (tea.Model, tea.Cmd) {
+	if len(m.flaggedTracks) == 0 || m.flaggedCursor >= len(m.flaggedTracks) {
 		return m, nil
 	}
 
-	url := "https://open.spotify.com/track/" + track.SpotifyID
-	go browser.OpenURL(url)
+	selected := m.flaggedTracks[m.flaggedCursor]
+	if err := m.db.UnflagTrack(selected.Track.ID); err != nil {
+		m.statusMessage = "⚠️  Impossible de retirer le marque-page"
+		return m, nil
+	}
 
-	m.statusMessage = "🌐 Ouverture de Spotify dans le navigateur..."
+	m.flaggedTracks = append(m.flaggedTracks[:m.flaggedCursor:m.flaggedCursor], m.flaggedTracks[m.flaggedCursor+1:]...)
+	if m.flaggedCursor >= len(m.flaggedTracks) && m.flaggedCursor > 0 {
+		m.flaggedCursor--
+	}
+	m.statusMessage = "🔖 " + selected.Track.Name + " retiré de la liste de réécoute"
 	return m, nil
 }
 
-// handleExportPlaylist exporte le top des tracks en playlist
-func (m Model) handleExportPlaylist() (tea.Model, tea.Cmd) {
-	m.statusMessage = "📝 Export de playlist en cours..."
-	return m, m.exportPlaylist()
-}
+// handlePlayFlaggedTrack joue le track sélectionné dans la liste de réécoute
+func (m Model) handlePlayFlaggedTrack() (tea.Model, tea.Cmd) {
+	if m.offlineBlocked() {
+		return m, nil
+	}
 
-// handleShowLeaderboard shows the leaderboard
-func (m Model) handleShowLeaderboard() (tea.Model, tea.Cmd) {
-	// Get all tracks sorted by Elo
-	tracks, err := m.db.GetAllTracksWithRatings()
-	if err != nil {
-		m.statusMessage = "⚠️  Failed to load leaderboard"
+	if len(m.flaggedTracks) == 0 || m.flaggedCursor >= len(m.flaggedTracks) {
+		m.statusMessage = "⚠️  Aucun track sélectionné"
 		return m, nil
 	}
 
-	m.leaderboard = tracks
-	m.leaderboardCursor = 0
-	m.currentView = ViewLeaderboard
-	return m, nil
+	selected := &m.flaggedTracks[m.flaggedCursor]
+	m.statusMessage = fmt.Sprintf("🎵 Lecture : %s - %s", selected.Track.Name, selected.Track.Artist)
+
+	return m, m.playTrack(selected.Track)
 }
 
 // handlePlayLeaderboardTrack joue le track sélectionné dans le leaderboard
 func (m Model) handlePlayLeaderboardTrack() (tea.Model, tea.Cmd) {
+	if m.offlineBlocked() {
+		return m, nil
+	}
+
 	if len(m.leaderboard) == 0 || m.leaderboardCursor >= len(m.leaderboard) {
 		m.statusMessage = "⚠️  Aucun track sélectionné"
 		return m, nil
@@ -403,7 +1571,7 @@ func (m Model) handlePlayLeaderboardTrack() (tea.Model, tea.Cmd) {
 	selectedTrack := &m.leaderboard[m.leaderboardCursor]
 	m.statusMessage = fmt.Sprintf("🎵 Lecture : %s - %s", selectedTrack.Track.Name, selectedTrack.Track.Artist)
 
-	return m, m.playTrack(selectedTrack.Track.SpotifyURI)
+	return m, m.playTrack(selectedTrack.Track)
 }
 
 // handleLeaderboardSelect sélectionne un track du leaderboard pour un duel
@@ -432,6 +1600,7 @@ func (m Model) handleLeaderboardSelect() (tea.Model, tea.Cmd) {
 	// Configurer le duel
 	m.leftTrack = selectedTrack
 	m.rightTrack = opponent
+	m.updateWinProbability()
 	m.focus = FocusLeft
 	m.currentView = ViewDuel
 	m.statusMessage = "Battle from leaderboard!"
@@ -443,8 +1612,16 @@ func (m Model) handleLeaderboardSelect() (tea.Model, tea.Cmd) {
 
 // initializeApp initialise l'authentification et l'application
 func (m Model) initializeApp() tea.Msg {
+	// Sans connexion, continuer hors ligne plutôt que d'échouer
+	if !spotify.IsOnline(OnlineCheckTimeout) {
+		return InitCompleteMsg{Offline: true}
+	}
+
 	// Vérifier l'authentification
 	token, err := m.auth.GetValidToken(m.ctx)
+	if spotify.IsNetworkError(err) {
+		return InitCompleteMsg{Offline: true}
+	}
 	if err != nil {
 		return ErrorMsg{Err: fmt.Errorf("erreur authentification: %w", err)}
 	}
@@ -457,7 +1634,15 @@ func (m Model) initializeApp() tea.Msg {
 
 // setupNextDuel configure le prochain duel
 func (m Model) setupNextDuel() tea.Msg {
+	// Duels imposés par la file avant le matchmaking
+	if left, right, ok := m.matchmaker.NextQueuedMatch(); ok {
+		return DuelSetupCompleteMsg{Left: left, Right: right, Queued: true}
+	}
+
 	left, right, err := m.matchmaker.GetNextMatch()
+	if errors.Is(err, matchmaker.ErrNotEnoughTracks) {
+		return ImportNeededMsg{}
+	}
 	if err != nil {
 		return ErrorMsg{Err: fmt.Errorf("erreur matchmaking: %w", err)}
 	}
@@ -466,22 +1651,109 @@ func (m Model) setupNextDuel() tea.Msg {
 }
 
 // playTrack joue un track sur Spotify
-func (m Model) playTrack(trackURI string) tea.Cmd {
+func (m Model) playTrack(track models.Track) tea.Cmd {
 	return func() tea.Msg {
 		if m.spotifyClient == nil {
 			return ErrorMsg{Err: fmt.Errorf("client Spotify non initialisé")}
 		}
 
-		err := m.spotifyClient.PlayTrack(trackURI)
+		position := snippetStart(m.playback, track)
+		err := m.spotifyClient.PlayTrackAt(track.SpotifyURI, position)
+		if err != nil && spotify.IsNotFound(err) {
+			// L'ID a peut-être été relinké par Spotify : le retrouver via l'ISRC
+			if changed, relinkErr := m.spotifyClient.RelinkTrack(&track); relinkErr == nil && changed {
+				if dbErr := m.db.UpdateTrackSpotifyID(track.ID, track.SpotifyID, track.SpotifyURI); dbErr == nil {
+					err = m.spotifyClient.PlayTrackAt(track.SpotifyURI, position)
+				}
+			}
+		}
+
+		// Toujours introuvable : le track a peut-être été retiré du catalogue
+		if err != nil && spotify.IsNotFound(err) && m.markIfRemoved(track) {
+			return TrackUnavailableMsg{TrackID: track.ID, Name: track.Name}
+		}
+
 		if err != nil {
 			// Fallback: ouvrir dans le navigateur
-			url := "https://open.spotify.com/track/" + trackURI[14:] // Enlever "spotify:track:"
-			browser.OpenURL(url)
+			url := "https://open.spotify.com/track/" + track.SpotifyID
+			if auth.IsHeadless() {
+				return ErrorMsg{Err: fmt.Errorf("lecture Spotify échouée (%v), ouvrir : %s", err, url)}
+			}
+			if openErr := browser.OpenURL(url); openErr != nil {
+				return ErrorMsg{Err: fmt.Errorf("lecture Spotify échouée (%v) et navigateur introuvable (%v), ouvrir : %s", err, openErr, url)}
+			}
 			return ErrorMsg{Err: fmt.Errorf("lecture Spotify échouée, ouverture navigateur: %w", err)}
 		}
 
-		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("played")}
+		if track.Unavailable {
+			_ = m.db.MarkTrackAvailable(track.ID) // De nouveau lisible
+		}
+		return PlaybackStartedMsg{StartedAt: time.Now(), URI: track.SpotifyURI}
+	}
+}
+
+// markIfRemoved signale un track indisponible seulement si le catalogue confirme sa disparition :
+// le lecteur répond aussi 404 quand aucun appareil Spotify n'est actif
+func (m Model) markIfRemoved(track models.Track) bool {
+	missing, err := m.spotifyClient.MissingTrackIDs([]string{track.SpotifyID})
+	if err != nil || !missing[track.SpotifyID] {
+		return false
+	}
+	return m.db.MarkTrackUnavailable(track.ID) == nil
+}
+
+// pausePlayback met la lecture en pause à la fin d'un extrait
+func (m Model) pausePlayback() tea.Cmd {
+	return func() tea.Msg {
+		if m.spotifyClient == nil {
+			return nil
+		}
+		// Échec non bloquant : l'utilisateur a pu changer d'appareil ou de morceau
+		m.spotifyClient.Pause()
+		return nil
+	}
+}
+
+// pauseAppPlayback met en pause, au passage au duel suivant, le track lancé par l'application
+// Rien n'est fait si l'option est désactivée ou si un autre morceau a été lancé entre-temps
+func (m *Model) pauseAppPlayback() tea.Cmd {
+	uri := m.playingURI
+	if !m.playback.PauseOnNext || uri == "" || m.spotifyClient == nil {
+		return nil
+	}
+	m.playingURI = ""
+
+	client := m.spotifyClient
+	return func() tea.Msg {
+		// Échec non bloquant : la lecture continue simplement
+		if playing, err := client.IsPlaying(uri); err == nil && playing {
+			client.Pause()
+		}
+		return nil
+	}
+}
+
+// snippetStart calcule la position de départ, bornée à la durée du track si connue
+// L'extrait est décalé pour tenir avant la fin du morceau
+func snippetStart(playback config.PlaybackConfig, track models.Track) time.Duration {
+	start := playback.SnippetStart
+	if start <= 0 || track.DurationMs <= 0 {
+		return start
+	}
+
+	duration := time.Duration(track.DurationMs) * time.Millisecond
+	length := playback.SnippetLength
+	if length <= 0 || length > duration {
+		length = 0
+	}
+
+	if start+length >= duration {
+		start = duration - length
+		if length == 0 {
+			start = 0 // Pas d'extrait défini : repartir du début plutôt que de la fin
+		}
 	}
+	return start
 }
 
 // getAudioFeatures récupère les caractéristiques audio
@@ -492,10 +1764,22 @@ func (m Model) getAudioFeatures(trackID string) tea.Cmd {
 		}
 
 		features, err := m.spotifyClient.GetAudioFeatures(trackID)
+		if spotify.IsMissingScope(err) {
+			// API refusée et pas d'extrait à analyser
+			return AudioFeaturesMsg{}
+		}
 		if err != nil {
 			return ErrorMsg{Err: fmt.Errorf("erreur récupération audio features: %w", err)}
 		}
 
+		// Un profil estimé n'a que l'énergie et le tempo : ne pas afficher les autres à 0%
+		if features.Estimated {
+			return AudioFeaturesMsg{
+				Features:  map[string]float64{"energy": features.Energy, "tempo": features.Tempo},
+				Estimated: true,
+			}
+		}
+
 		// Convertir en map pour l'affichage
 		featuresMap := map[string]float64{
 			"danceability": features.Danceability,
@@ -512,20 +1796,13 @@ func (m Model) getAudioFeatures(trackID string) tea.Cmd {
 // exportPlaylist exporte une playlist des meilleurs tracks
 func (m Model) exportPlaylist() tea.Cmd {
 	return func() tea.Msg {
-		// Récupérer les top tracks
-		topTracks, err := m.eloSystem.GetEloRanking(50)
+		exporter := export.NewPlaylistExporterWithConfig(m.db, m.spotifyClient, m.ctx, m.exportConfig)
+		info, err := exporter.ExportTopTracks(playlistExportSize)
 		if err != nil {
-			return ErrorMsg{Err: fmt.Errorf("erreur récupération top tracks: %w", err)}
-		}
-
-		if len(topTracks) == 0 {
-			return ErrorMsg{Err: fmt.Errorf("aucun track trouvé pour l'export")}
+			return playlistExportError("erreur export playlist", err)
 		}
 
-		// Créer la playlist (simulation, nécessite l'utilisateur Spotify)
-		// TODO: Implémenter l'export réel avec l'API Spotify
-
-		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("exported")}
+		return PlaylistExportedMsg{Info: info}
 	}
 }
 
@@ -568,57 +1845,114 @@ func (m Model) renderError() string {
 		"",
 		errorStyle.Render("❌ "+m.errorMessage),
 		"",
-		helpStyle.Render("Press 'r' or Escape to return  •  'q' to quit"),
+		helpStyle.Render(fmt.Sprintf("Press '%s' or %s to return  •  '%s' to quit", m.keys.label(ActionQuickRate), m.keys.label(ActionBack), m.keys.label(ActionQuit))),
 	)
 
 	return content
 }
 
+// renderImportNeeded explique comment ajouter des tracks quand la bibliothèque est vide
+func (m Model) renderImportNeeded() string {
+	messageStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true).
+		Padding(1, 2)
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		Padding(1, 0)
+
+	return lipgloss.JoinVertical(
+		lipgloss.Center,
+		RenderHeader(),
+		"",
+		messageStyle.Render("📥 Import needed: at least 2 tracks are required to battle"),
+		helpStyle.Render("Restart with -import to fetch your Spotify top tracks"),
+		helpStyle.Render(fmt.Sprintf("Press '%s' to search and add tracks  •  '%s' to quit", m.keys.label(ActionSearch), m.keys.label(ActionQuit))),
+	)
+}
+
 // renderDuel affiche l'écran principal de duel
 func (m Model) renderDuel() string {
 	if m.leftTrack == nil || m.rightTrack == nil {
 		return m.renderLoading()
 	}
 
+	layout := m.layout()
+
 	// Cards des tracks
 	leftCard := RenderTrackCard(
-		m.leftTrack.Track.Name,
+		layout,
+		trackTitle(m.leftTrack.Track),
 		m.leftTrack.Track.Artist,
 		m.leftTrack.Track.Album,
 		m.leftTrack.Track.Year,
-		m.leftTrack.Rating.Elo,
+		m.displayElo(m.leftTrack),
+		currentDeviation(m.leftTrack.Rating),
+		m.libraryStats.tiers.Tier(m.leftTrack.Rating.Elo),
 		m.leftTrack.Rating.Wins,
 		m.leftTrack.Rating.Losses,
 		m.focus == FocusLeft,
+		m.hidesStats(m.leftTrack),
 	)
 
 	rightCard := RenderTrackCard(
-		m.rightTrack.Track.Name,
+		layout,
+		trackTitle(m.rightTrack.Track),
 		m.rightTrack.Track.Artist,
 		m.rightTrack.Track.Album,
 		m.rightTrack.Track.Year,
-		m.rightTrack.Rating.Elo,
+		m.displayElo(m.rightTrack),
+		currentDeviation(m.rightTrack.Rating),
+		m.libraryStats.tiers.Tier(m.rightTrack.Rating.Elo),
 		m.rightTrack.Rating.Wins,
 		m.rightTrack.Rating.Losses,
 		m.focus == FocusRight,
+		m.hidesStats(m.rightTrack),
 	)
 
-	// Assemblage de la vue - placer les cartes côte à côte avec VS au milieu
-	duelArea := lipgloss.JoinHorizontal(
-		lipgloss.Center,
-		leftCard,
-		RenderVersus(),
-		rightCard,
-	)
+	// Assemblage de la vue - placer les cartes côte à côte (ou empilées) avec VS au milieu
+	duelArea := m.joinCards(layout, leftCard, rightCard)
+
+	// Probabilités de victoire sous les cards (elles trahiraient les Elo masqués)
+	if m.showWinProbability && !m.hidesStats(m.leftTrack) && !m.hidesStats(m.rightTrack) {
+		duelArea = lipgloss.JoinVertical(lipgloss.Left, duelArea, m.renderWinProbability(lipgloss.Width(leftCard)))
+	}
 
-	// Calculer la largeur totale de la zone de duel
-	// 40 (carte gauche) + 6 (VS) + 40 (carte droite) = 86
-	totalWidth := 86
+	// Calculer la largeur totale de la zone de duel (carte gauche + VS + carte droite)
+	totalWidth := m.duelWidth(layout)
 
 	// Centrer le header et les contrôles sur la même largeur
-	centeredHeader := lipgloss.NewStyle().Width(totalWidth).Align(lipgloss.Center).Render(RenderHeader())
-	centeredControls := lipgloss.NewStyle().Width(totalWidth).Align(lipgloss.Center).Render(RenderControls())
-	centeredFooter := lipgloss.NewStyle().Width(totalWidth).Align(lipgloss.Center).Render(RenderFooter(m.statusMessage))
+	centeredHeader := lipgloss.NewStyle().Width(totalWidth).Align(lipgloss.Center).Render(
+		lipgloss.JoinVertical(lipgloss.Center, RenderHeaderWidth(totalWidth), m.libraryStats.render()),
+	)
+	centeredControls := lipgloss.NewStyle().Width(totalWidth).Align(lipgloss.Center).Render(RenderControls(m.keys, m.vertical()))
+	footer := m.statusMessage
+	if footer == "" {
+		footer = "Ready to battle!"
+	}
+	if goal := m.dailyGoal.render(); goal != "" {
+		footer += "  •  " + goal
+	}
+	if m.quickSession != nil {
+		footer += "  •  " + m.quickSession.progress()
+	}
+	if m.matchmaker.IsRivalry(m.leftTrack.Track.ID, m.rightTrack.Track.ID) {
+		footer += "  •  ⚔️ Rivalry"
+	}
+	if mood := m.matchmaker.Mood(); mood != "" {
+		footer += "  •  🎭 " + mood
+	}
+	if genreA, genreB := m.matchmaker.CrossGenres(); genreA != "" {
+		footer += "  •  🎸 " + genreA + " vs " + genreB
+	}
+	if queued := m.matchmaker.QueueLength(); queued > 0 {
+		footer += fmt.Sprintf("  •  📋 %d queued", queued)
+	}
+	if m.offline {
+		footer += "  •  📴 offline"
+	}
+	centeredFooter := lipgloss.NewStyle().Width(totalWidth).Align(lipgloss.Center).Render(RenderFooter(footer))
 
 	// Assembler le contenu verticalement de manière compacte
 	content := lipgloss.JoinVertical(
@@ -636,9 +1970,23 @@ func (m Model) renderDuel() string {
 
 // renderAudioFeatures affiche les caractéristiques audio
 func (m Model) renderAudioFeatures() string {
+	var average *models.AudioFeatures
+	note := ""
+	if m.audioAverages != nil {
+		average = &m.audioAverages.features
+		note = m.audioAverages.note()
+	}
+	if m.audioFeaturesEstimated {
+		if note != "" {
+			note += "\n"
+		}
+		note += estimatedNote()
+	}
+
 	content := fmt.Sprintf(`
 %s
 
+%s
 %s
 
 %s
@@ -646,7 +1994,8 @@ func (m Model) renderAudioFeatures() string {
 Press 'Escape' to return to battle.
 `,
 		RenderHeader(),
-		RenderAudioFeatures(m.currentAudioFeatures),
+		RenderAudioFeatures(m.currentAudioFeatures, average),
+		note,
 		RenderFooter("Audio features details"),
 	)
 
@@ -660,7 +2009,7 @@ func (m Model) renderLeaderboard() string {
 			lipgloss.Center,
 			RenderHeader(),
 			"",
-			"No tracks in leaderboard",
+			m.emptyLeaderboardMessage(),
 			"",
 			"Press Escape to return",
 		)
@@ -672,6 +2021,10 @@ func (m Model) renderLeaderboard() string {
 		Width(4).
 		Align(lipgloss.Right)
 
+	tierStyle := lipgloss.NewStyle().
+		Width(5).
+		Align(lipgloss.Center)
+
 	nameStyle := lipgloss.NewStyle().
 		Foreground(ColorPrimary).
 		Width(40)
@@ -700,47 +2053,51 @@ func (m Model) renderLeaderboard() string {
 	header := lipgloss.JoinHorizontal(
 		lipgloss.Top,
 		rankStyle.Render("#"),
+		tierStyle.Render("Tier"),
 		nameStyle.Bold(true).Render("Titre"),
 		artistStyle.Bold(true).Render("Artiste"),
 		eloStyle.Render("Elo"),
 		statsStyle.Render("W/L"),
 	)
 
-	// Lignes du classement (afficher 15 max)
+	// Lignes du classement (fenêtre de LeaderboardRows lignes)
+	rows := m.layout().LeaderboardRows
 	var lines []string
 	lines = append(lines, header)
-	lines = append(lines, lipgloss.NewStyle().Foreground(ColorBorder).Render("─────────────────────────────────────────────────────────────────────────────────────────────"))
+	lines = append(lines, lipgloss.NewStyle().Foreground(ColorBorder).Render("──────────────────────────────────────────────────────────────────────────────────────────────────"))
 
-	start := 0
-	end := len(m.leaderboard)
-	if end > 15 {
-		// Centrer sur le curseur
-		start = m.leaderboardCursor - 7
-		if start < 0 {
-			start = 0
-		}
-		end = start + 15
-		if end > len(m.leaderboard) {
-			end = len(m.leaderboard)
-			start = end - 15
-			if start < 0 {
-				start = 0
-			}
-		}
-	}
+	start, end := scrollWindow(m.leaderboardCursor, len(m.leaderboard), rows)
 
 	for i := start; i < end; i++ {
 		track := m.leaderboard[i]
 
 		rankStr := rankStyle.Render(fmt.Sprintf("%d", i+1))
-		nameStr := nameStyle.Render(truncate(track.Track.Name, 38))
-		artistStr := artistStyle.Render(truncate(track.Track.Artist, 28))
-		eloStr := eloStyle.Render(fmt.Sprintf("%d", track.Rating.Elo))
-		statsStr := statsStyle.Render(fmt.Sprintf("%d/%d", track.Rating.Wins, track.Rating.Losses))
+		tierStr := tierStyle.Render(RenderTierBadge(m.libraryStats.tiers.Tier(track.Rating.Elo)))
+		nameStr := nameStyle.Render(Truncate(trackTitle(track.Track), 38))
+		artistStr := artistStyle.Render(Truncate(track.Track.Artist, 28))
+		eloStr := eloStyle.Render(fmt.Sprintf("%d", track.Rating.Elo) + deviationSuffix(currentDeviation(track.Rating)))
+		stats := fmt.Sprintf("%d/%d", track.Rating.Wins, track.Rating.Losses)
+		if m.leaderboardMode == leaderboardContested {
+			stats += fmt.Sprintf(" (%.0f%%)", track.Rating.GetWinRate())
+		}
+		if m.leaderboardMode == leaderboardConservative {
+			stats += fmt.Sprintf(" ≥%d", elo.ConservativeElo(track.Rating, time.Now()))
+		}
+		statsStr := statsStyle.Render(stats)
+
+		checkbox := ""
+		if m.leaderboardSelecting || len(m.leaderboardSelected) > 0 {
+			checkbox = "[ ] "
+			if m.leaderboardSelected[track.Track.ID] {
+				checkbox = "[x] "
+			}
+		}
 
 		line := lipgloss.JoinHorizontal(
 			lipgloss.Top,
+			checkbox,
 			rankStr,
+			tierStr,
 			nameStr,
 			artistStr,
 			eloStr,
@@ -755,10 +2112,35 @@ func (m Model) renderLeaderboard() string {
 	}
 
 	// Contrôles
+	help := joinHints(m.keys.navigationHint(ActionUp, ActionDown, "navigate"), m.keys.hints(
+		ActionPlay, "play", ActionVote, "battle", ActionGenres, "genres", ActionContested, "contested",
+		ActionConservative, "lower bound", ActionSource, "source", ActionMood, "mood", ActionCalibratedOnly, "calibrated", ActionSelectMode, "select", ActionQuit, "back"))
+	if m.leaderboardSelecting {
+		help = joinHints(m.keys.navigationHint(ActionUp, ActionDown, "navigate"), m.keys.hints(
+			ActionPlay, "toggle", ActionExportSelection, "export", ActionQueueSelection, "queue battle",
+			ActionCompare, "compare (2 tracks)", ActionSelectMode, "done", ActionQuit, "back"))
+	}
 	controls := lipgloss.NewStyle().
 		Foreground(ColorMuted).
 		Padding(1, 0).
-		Render("↑↓ navigate  ␣ play  ↵ battle  q back")
+		Render(help)
+
+	title := "Leaderboard"
+	if m.leaderboardGenre != "" {
+		title = "Leaderboard " + m.leaderboardGenre
+	}
+	if m.leaderboardSource != "" {
+		title = "Leaderboard from " + m.leaderboardSource
+	}
+	if m.leaderboardMood != "" {
+		title = "Leaderboard " + m.leaderboardMood
+	}
+	if m.leaderboardMode == leaderboardContested {
+		title = "Most contested"
+	}
+	if m.leaderboardMode == leaderboardConservative {
+		title = "Leaderboard by lower bound (Elo - 2×RD)"
+	}
 
 	content := lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -767,8 +2149,163 @@ func (m Model) renderLeaderboard() string {
 		lipgloss.JoinVertical(lipgloss.Left, lines...),
 		"",
 		controls,
-		RenderFooter(fmt.Sprintf("Leaderboard - %d tracks", len(m.leaderboard))),
+		RenderFooter(m.leaderboardFooter(title)),
 	)
 
 	return content
 }
+
+// leaderboardFooter résume le classement affiché et la sélection en cours
+func (m Model) leaderboardFooter(title string) string {
+	footer := fmt.Sprintf("%s - %d tracks", title, len(m.leaderboard))
+	if m.leaderboardCalibrating > 0 {
+		footer += fmt.Sprintf(" - %d tracks still calibrating", m.leaderboardCalibrating)
+	}
+	if m.leaderboardCursor < len(m.leaderboard) {
+		track := m.leaderboard[m.leaderboardCursor].Track
+		footer += " - source: " + sourceLabel(track.Source) + " - mood: " + moodLabel(track.Mood) +
+			" - " + appearanceLabel(m.leaderboard[m.leaderboardCursor].Rating)
+	}
+	if m.offline {
+		footer += " - 📴 offline"
+	}
+	if m.leaderboardSelecting || len(m.leaderboardSelected) > 0 {
+		footer += fmt.Sprintf(" - %d selected", len(m.leaderboardSelected))
+	}
+	return footer
+}
+
+// emptyLeaderboardMessage explique pourquoi le classement affiché est vide
+func (m Model) emptyLeaderboardMessage() string {
+	if m.leaderboardMode == leaderboardContested {
+		return fmt.Sprintf("No contested tracks yet (needs %d+ battles and a win rate near 50%%)", contestedMinBattles)
+	}
+	if m.leaderboardCalibrating > 0 {
+		return fmt.Sprintf("All %d tracks still calibrating (fewer than %d battles) • C to show them", m.leaderboardCalibrating, m.fairStartBattles)
+	}
+	return "No tracks in leaderboard"
+}
+
+// renderGenres affiche le sélecteur de genres
+func (m Model) renderGenres() string {
+	if len(m.genres) == 0 {
+		return lipgloss.JoinVertical(
+			lipgloss.Center,
+			RenderHeader(),
+			"",
+			"No genres available yet",
+			"",
+			"Press Escape to return",
+		)
+	}
+
+	genreStyle := lipgloss.NewStyle().
+		Foreground(ColorSecondary).
+		Width(40)
+
+	selectedStyle := lipgloss.NewStyle().
+		Background(ColorPrimary).
+		Foreground(lipgloss.Color("#000000")).
+		Bold(true)
+
+	// Fenêtre de 15 genres centrée sur le curseur
+	start := 0
+	end := len(m.genres)
+	if end > 15 {
+		start = m.genreCursor - 7
+		if start < 0 {
+			start = 0
+		}
+		end = start + 15
+		if end > len(m.genres) {
+			end = len(m.genres)
+			start = end - 15
+		}
+	}
+
+	var lines []string
+	for i := start; i < end; i++ {
+		line := genreStyle.Render(Truncate(m.genres[i], 38))
+		if i == m.genreCursor {
+			line = selectedStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	controls := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		Padding(1, 0).
+		Render(joinHints(m.keys.navigationHint(ActionUp, ActionDown, "navigate"), m.keys.hints(ActionVote, "leaderboard", ActionQuit, "back")))
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		RenderHeader(),
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, lines...),
+		"",
+		controls,
+		RenderFooter(fmt.Sprintf("Genres - %d", len(m.genres))),
+	)
+}
+
+// renderFlagged affiche la liste des tracks marqués pour réécoute
+func (m Model) renderFlagged() string {
+	if len(m.flaggedTracks) == 0 {
+		return lipgloss.JoinVertical(
+			lipgloss.Center,
+			RenderHeader(),
+			"",
+			"No flagged tracks - press 'b' during a battle to flag one",
+			"",
+			"Press Escape to return",
+		)
+	}
+
+	nameStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Width(40)
+
+	artistStyle := lipgloss.NewStyle().
+		Foreground(ColorSecondary).
+		Width(30)
+
+	eloStyle := lipgloss.NewStyle().
+		Foreground(ColorSuccess).
+		Bold(true).
+		Width(10).
+		Align(lipgloss.Right)
+
+	selectedStyle := lipgloss.NewStyle().
+		Background(ColorPrimary).
+		Foreground(lipgloss.Color("#000000")).
+		Bold(true)
+
+	var lines []string
+	for i, track := range m.flaggedTracks {
+		line := lipgloss.JoinHorizontal(
+			lipgloss.Top,
+			nameStyle.Render("🔖 "+Truncate(track.Track.Name, 35)),
+			artistStyle.Render(Truncate(track.Track.Artist, 28)),
+			eloStyle.Render(fmt.Sprintf("%d", track.Rating.Elo)),
+		)
+		if i == m.flaggedCursor {
+			line = selectedStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	controls := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		Padding(1, 0).
+		Render(joinHints(m.keys.navigationHint(ActionUp, ActionDown, "navigate"), m.keys.hints(ActionPlay, "play", ActionFlag, "unflag", ActionQuit, "back")))
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		RenderHeader(),
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, lines...),
+		"",
+		controls,
+		RenderFooter(m.statusMessage),
+	)
+}
@@ -0,0 +1,92 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// moodLabel retourne l'humeur d'un track telle qu'affichée
+func moodLabel(mood string) string {
+	if mood == "" {
+		return "unknown"
+	}
+	return mood
+}
+
+// handleCycleMood filtre le classement par humeur, en passant à l'humeur suivante
+// Après la dernière humeur, le classement complet est de nouveau affiché
+func (m Model) handleCycleMood() (tea.Model, tea.Cmd) {
+	moods, err := m.db.GetMoods()
+	if err != nil {
+		m.statusMessage = "⚠️  Impossible de charger les humeurs"
+		return m, nil
+	}
+
+	if len(moods) == 0 {
+		m.statusMessage = "Aucune humeur calculée (audio features manquantes, essayez -recluster)"
+		return m, nil
+	}
+
+	next := nextMood(moods, m.leaderboardMood)
+	if next == "" {
+		return m.handleShowLeaderboard()
+	}
+
+	tracks, err := m.db.GetLeaderboardByMood(next, 500)
+	if err != nil {
+		m.statusMessage = "⚠️  Impossible de charger le classement " + next
+		return m, nil
+	}
+
+	m.setLeaderboard(tracks)
+	m.leaderboardCursor = 0
+	m.leaderboardGenre = ""
+	m.leaderboardSource = ""
+	m.leaderboardMood = next
+	m.leaderboardMode = leaderboardByElo
+	return m, nil
+}
+
+// handleCycleBattleMood limite les duels à une humeur, en passant à l'humeur suivante
+// Les humeurs de moins de 2 tracks sont sautées ; après la dernière, toute la bibliothèque est rejouée
+func (m Model) handleCycleBattleMood() (tea.Model, tea.Cmd) {
+	moods, err := m.db.GetMoods()
+	if err != nil {
+		m.statusMessage = "⚠️  Impossible de charger les humeurs"
+		return m, nil
+	}
+
+	if len(moods) == 0 {
+		m.statusMessage = "Aucune humeur calculée (audio features manquantes, essayez -recluster)"
+		return m, nil
+	}
+
+	next := nextMood(moods, m.matchmaker.Mood())
+	for next != "" {
+		tracks, err := m.db.GetLeaderboardByMood(next, 2)
+		if err == nil && len(tracks) >= 2 {
+			break
+		}
+		next = nextMood(moods, next)
+	}
+
+	m.matchmaker.SetMood(next)
+	if next == "" {
+		m.statusMessage = "🎭 Duels sur toute la bibliothèque"
+	} else {
+		m.statusMessage = "🎭 Duels limités à l'humeur " + next
+	}
+	return m, m.setupNextDuel
+}
+
+// nextMood retourne l'humeur qui suit current dans moods ("" après la dernière)
+func nextMood(moods []string, current string) string {
+	if current == "" {
+		return moods[0]
+	}
+	for i, mood := range moods {
+		if mood == current && i+1 < len(moods) {
+			return moods[i+1]
+		}
+	}
+	return ""
+}
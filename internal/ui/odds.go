@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"fmt"
+	"songbattle/internal/elo"
+	"songbattle/internal/models"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// updateWinProbability calcule la probabilité de victoire du track de gauche
+// d'après les Elo au début du duel (le score attendu du système Elo)
+func (m *Model) updateWinProbability() {
+	if m.leftTrack == nil || m.rightTrack == nil {
+		m.leftWinProbability = 0
+		return
+	}
+	m.leftWinProbability = elo.CalculateExpectedScore(m.leftTrack.Rating.Elo, m.rightTrack.Rating.Elo)
+}
+
+// handleToggleWinProbability affiche ou masque les probabilités de victoire sous les cards
+// La préférence est conservée entre les sessions
+func (m Model) handleToggleWinProbability() (tea.Model, tea.Cmd) {
+	show := !m.showWinProbability
+	if err := m.db.SetMetaBool(models.MetaKeyShowWinProbability, show); err != nil {
+		m.statusMessage = "⚠️  Impossible de sauvegarder la préférence d'affichage"
+		return m, nil
+	}
+
+	m.showWinProbability = show
+	if show {
+		m.statusMessage = "🎲 Probabilités de victoire affichées"
+	} else {
+		m.statusMessage = "🎲 Probabilités de victoire masquées"
+	}
+	return m, nil
+}
+
+// renderWinProbability affiche la probabilité de victoire de chaque côté, sous sa card
+// cardWidth est la largeur rendue d'une card, bordure comprise ; le favori est mis en avant
+// Cards empilées, les deux probabilités se partagent la largeur d'une card, flèche vers leur track
+func (m Model) renderWinProbability(cardWidth int) string {
+	left := int(m.leftWinProbability*100 + 0.5)
+	right := 100 - left
+
+	leftLabel := fmt.Sprintf("%d%% to win", left)
+	rightLabel := fmt.Sprintf("%d%% to win", right)
+	if m.vertical() {
+		cardWidth = (cardWidth - versusWidth) / 2
+		leftLabel = "▲ " + leftLabel
+		rightLabel += " ▼"
+	}
+
+	cellStyle := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		Width(cardWidth).
+		Align(lipgloss.Center)
+
+	favoriteStyle := cellStyle.
+		Foreground(ColorSuccess).
+		Bold(true)
+
+	leftStyle, rightStyle := cellStyle, cellStyle
+	if left > right {
+		leftStyle = favoriteStyle
+	} else if right > left {
+		rightStyle = favoriteStyle
+	}
+
+	return lipgloss.JoinHorizontal(
+		lipgloss.Top,
+		leftStyle.Render(leftLabel),
+		lipgloss.NewStyle().Foreground(ColorMuted).Width(versusWidth).Align(lipgloss.Center).Render("vs"),
+		rightStyle.Render(rightLabel),
+	)
+}
@@ -0,0 +1,250 @@
+package ui
+
+import (
+	"fmt"
+	"songbattle/internal/elo"
+	"songbattle/internal/models"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Étapes de l'introduction affichée au premier lancement
+const (
+	onboardingWelcome = iota
+	onboardingFirstDuel
+	onboardingSecondDuel
+	onboardingControls
+	onboardingReady
+	onboardingStepCount
+)
+
+// onboardingSampleK est le facteur K utilisé pour les duels d'exemple (celui d'un nouveau track)
+const onboardingSampleK = 32
+
+// onboardingSample est un duel fictif de l'introduction (jamais enregistré)
+type onboardingSample struct {
+	left, right models.TrackWithRating
+	tip         string
+}
+
+// onboardingSamples contient un duel équilibré puis un duel déséquilibré,
+// pour montrer qu'une victoire surprise rapporte plus d'Elo
+var onboardingSamples = []onboardingSample{
+	{
+		left:  sampleTrack("Midnight Drive", "The Examples", "Demo Tape", 2019, 1200, 0, 0),
+		right: sampleTrack("Paper Lanterns", "Sample Street", "First Light", 2021, 1200, 0, 0),
+		tip:   "Both songs start at 1200 Elo: the winner takes exactly what the loser gives up.",
+	},
+	{
+		left:  sampleTrack("Golden Hour", "Placeholder", "Favorites", 2015, 1450, 14, 3),
+		right: sampleTrack("Static Bloom", "Dummy Data", "B-Sides", 2023, 1150, 2, 6),
+		tip:   "Upsets pay more: picking the lower-rated song moves both ratings a lot.",
+	},
+}
+
+// sampleTrack construit un track fictif pour les duels d'exemple
+func sampleTrack(name, artist, album string, year, eloValue, wins, losses int) models.TrackWithRating {
+	return models.TrackWithRating{
+		Track:  models.Track{Name: name, Artist: artist, Album: album, Year: year},
+		Rating: models.Rating{Elo: eloValue, Wins: wins, Losses: losses},
+	}
+}
+
+// onboarding conserve la progression dans l'introduction
+type onboarding struct {
+	step   int
+	focus  FocusPosition
+	result string // Résultat du vote sur le duel d'exemple en cours
+}
+
+// sample retourne le duel d'exemple de l'étape en cours (nil pour les étapes de texte)
+func (o onboarding) sample() *onboardingSample {
+	switch o.step {
+	case onboardingFirstDuel:
+		return &onboardingSamples[0]
+	case onboardingSecondDuel:
+		return &onboardingSamples[1]
+	}
+	return nil
+}
+
+// handleOnboardingKey gère le clavier pendant l'introduction
+// Entrée avance, les flèches jouent les duels d'exemple, toute autre touche passe l'introduction
+func (m Model) handleOnboardingKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Ignorer les pseudo-touches internes ("next", "played"...)
+	if msg.Type == tea.KeyRunes && len(msg.Runes) > 1 && !msg.Paste {
+		return m, nil
+	}
+
+	sample := m.onboarding.sample()
+
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "left", "h", "right", "l", "up", "k", "down", "j":
+		if sample == nil || m.onboarding.result != "" {
+			return m, nil
+		}
+		switch msg.String() {
+		case "left", "h", "up", "k":
+			m.onboarding.focus = FocusLeft
+		default:
+			m.onboarding.focus = FocusRight
+		}
+		return m, nil
+
+	case "enter":
+		if sample != nil && m.onboarding.result == "" {
+			m.onboarding.result = sampleVoteResult(sample, m.onboarding.focus)
+			return m, nil
+		}
+
+		m.onboarding.step++
+		m.onboarding.focus = FocusLeft
+		m.onboarding.result = ""
+		if m.onboarding.step < onboardingStepCount {
+			return m, nil
+		}
+		return m.finishOnboarding("Prêt pour le duel !")
+	}
+
+	return m.finishOnboarding("Introduction passée (relancez avec -skip-intro pour ne jamais la voir)")
+}
+
+// finishOnboarding mémorise que l'introduction a été vue et passe aux vrais duels
+func (m Model) finishOnboarding(status string) (tea.Model, tea.Cmd) {
+	_ = m.db.SetMetaBool(models.MetaKeyOnboardingDone, true)
+	m.currentView = ViewDuel
+	m.statusMessage = status
+	return m, nil
+}
+
+// sampleVoteResult calcule les variations d'Elo d'un duel d'exemple, sans rien enregistrer
+func sampleVoteResult(sample *onboardingSample, focus FocusPosition) string {
+	winner, loser := sample.left, sample.right
+	if focus == FocusRight {
+		winner, loser = sample.right, sample.left
+	}
+
+	expected := elo.CalculateExpectedScore(winner.Rating.Elo, loser.Rating.Elo)
+	gain := elo.CalculateNewElo(winner.Rating.Elo, 1, expected, onboardingSampleK) - winner.Rating.Elo
+	loss := elo.CalculateNewElo(loser.Rating.Elo, 0, 1-expected, onboardingSampleK) - loser.Rating.Elo
+
+	return fmt.Sprintf("%s %+d Elo  •  %s %+d Elo", winner.Track.Name, gain, loser.Track.Name, loss)
+}
+
+// renderOnboarding affiche l'étape en cours de l'introduction
+func (m Model) renderOnboarding() string {
+	layout := m.layout()
+	totalWidth := m.duelWidth(layout)
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true)
+
+	tooltipStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorPrimary).
+		Padding(0, 1).
+		Width(totalWidth + 2) // Même largeur que les deux cards et le VS (bordures comprises)
+
+	keyStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true)
+
+	var title string
+	var body []string
+	var duelArea string
+
+	switch m.onboarding.step {
+	case onboardingWelcome:
+		title = "Welcome to Song Battle!"
+		body = []string{
+			"Ranking hundreds of songs is hard. Picking the better of two is easy.",
+			"Song Battle shows you two songs at a time and builds your ranking",
+			"from your picks with the Elo system used for chess players.",
+			"",
+			"Let's try a couple of practice battles. Nothing is recorded.",
+		}
+
+	case onboardingFirstDuel, onboardingSecondDuel:
+		sample := m.onboarding.sample()
+		title = fmt.Sprintf("Practice battle %d of %d", m.onboarding.step-onboardingFirstDuel+1, len(onboardingSamples))
+
+		leftCard := RenderTrackCard(layout, sample.left.Track.Name, sample.left.Track.Artist, sample.left.Track.Album,
+			sample.left.Track.Year, sample.left.Rating.Elo, sample.left.Rating.RD, "", sample.left.Rating.Wins, sample.left.Rating.Losses,
+			m.onboarding.focus == FocusLeft, false)
+		rightCard := RenderTrackCard(layout, sample.right.Track.Name, sample.right.Track.Artist, sample.right.Track.Album,
+			sample.right.Track.Year, sample.right.Rating.Elo, sample.right.Rating.RD, "", sample.right.Rating.Wins, sample.right.Rating.Losses,
+			m.onboarding.focus == FocusRight, false)
+		duelArea = m.joinCards(layout, leftCard, rightCard)
+
+		navigate := "← →"
+		if m.vertical() {
+			navigate = "↑ ↓"
+		}
+
+		if m.onboarding.result == "" {
+			body = []string{
+				keyStyle.Render(navigate) + " select a song   " + keyStyle.Render("␣") + " listen on Spotify (in real battles)   " + keyStyle.Render("↵") + " vote",
+			}
+		} else {
+			body = []string{
+				lipgloss.NewStyle().Foreground(ColorSuccess).Bold(true).Render(m.onboarding.result),
+				sample.tip,
+			}
+		}
+
+	case onboardingControls:
+		title = "A few more keys"
+		// Touches des vrais duels, telles que remappées dans la configuration
+		controls := [][2]string{
+			{ActionDraw, "call a draw when both songs are equally good"},
+			{ActionSkip, "skip a battle you can't decide (recorded, no Elo change)"},
+			{ActionReshuffle, "draw a different pair (not recorded)"},
+			{ActionUndo, "undo your last vote or skip"},
+			{ActionLeaderboard, "see the leaderboard"},
+			{ActionSearch, "search Spotify and add a song"},
+			{ActionQuit, "quit with a summary of your session"},
+		}
+		for _, control := range controls {
+			if key := m.keys.label(control[0]); key != "" {
+				body = append(body, keyStyle.Width(4).Render(key)+control[1])
+			}
+		}
+
+	case onboardingReady:
+		title = "You're ready!"
+		body = []string{
+			"Your first real battle is waiting. Trust your gut: quick picks make good rankings.",
+			"The more you battle, the more accurate your leaderboard gets.",
+		}
+	}
+
+	help := "↵ continue  •  any other key skips the intro"
+	if m.onboarding.step == onboardingReady {
+		help = "↵ start battling"
+	}
+
+	// Centrer chaque bloc sur la largeur de la zone de duel, comme renderDuel
+	centered := lipgloss.NewStyle().Width(totalWidth).Align(lipgloss.Center)
+
+	sections := []string{
+		centered.Render(RenderHeader()),
+		"",
+		centered.Render(titleStyle.Render(title)),
+		"",
+	}
+	if duelArea != "" {
+		sections = append(sections, duelArea, "")
+	}
+	sections = append(sections,
+		tooltipStyle.Render(lipgloss.JoinVertical(lipgloss.Left, body...)),
+		centered.Foreground(ColorMuted).Padding(1, 0).Render(
+			fmt.Sprintf("%s  (%d/%d)", help, m.onboarding.step+1, onboardingStepCount)),
+	)
+
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
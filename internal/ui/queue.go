@@ -0,0 +1,28 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// SetBattleQueue impose une suite de duels (paires d'IDs gauche/droite),
+// joués dans l'ordre avant le matchmaking habituel
+func (m *Model) SetBattleQueue(pairs [][2]int64) {
+	m.matchmaker.SetQueue(pairs)
+}
+
+// handleQueueSelection ajoute à la file un duel entre les deux tracks sélectionnés
+// La sélection est vidée pour enchaîner la construction du duel suivant
+func (m Model) handleQueueSelection() (tea.Model, tea.Cmd) {
+	ids := m.selectedTrackIDs()
+	if len(ids) != 2 {
+		m.statusMessage = "⚠️  Sélectionnez exactement 2 tracks (m puis ␣) pour programmer un duel"
+		return m, nil
+	}
+
+	m.matchmaker.EnqueueMatch(ids[0], ids[1])
+	m.leaderboardSelected = nil
+	m.statusMessage = fmt.Sprintf("📋 Duel ajouté à la file (%d en attente)", m.matchmaker.QueueLength())
+	return m, nil
+}
@@ -0,0 +1,212 @@
+package ui
+
+import (
+	"fmt"
+	"songbattle/internal/models"
+	"songbattle/internal/spotify"
+	"songbattle/internal/store"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// quickRatePreviewLength est la durée de l'extrait joué pour chaque track à noter
+const quickRatePreviewLength = 30 * time.Second
+
+// quickRateLabels décrit chaque note de la notation rapide
+var quickRateLabels = map[int]string{
+	1: "not for me",
+	2: "meh",
+	3: "fine",
+	4: "like it",
+	5: "love it",
+}
+
+// QuickRatePreviewMsg signale le résultat de la lecture de l'extrait d'un track à noter
+type QuickRatePreviewMsg struct {
+	TrackID   int64
+	StartedAt time.Time
+	Err       error
+}
+
+// handleShowQuickRate ouvre la notation rapide des tracks jamais joués
+func (m Model) handleShowQuickRate() (tea.Model, tea.Cmd) {
+	if m.offlineBlocked() {
+		return m, nil
+	}
+
+	if m.spotifyClient == nil {
+		m.statusMessage = "⚠️  Notation rapide indisponible (client Spotify non initialisé)"
+		return m, nil
+	}
+
+	queue, err := m.db.GetQuickRateQueue()
+	if err != nil {
+		m.statusMessage = "⚠️  Impossible de charger les tracks à noter"
+		return m, nil
+	}
+
+	if len(queue) == 0 {
+		m.statusMessage = "Aucun nouveau track à noter"
+		return m, nil
+	}
+
+	m.quickRateQueue = queue
+	m.quickRateIndex = 0
+	m.quickRateCount = 0
+	m.currentView = ViewQuickRate
+	m.statusMessage = "🎧 Écoutez l'extrait puis notez de 1 à 5"
+	return m, m.playQuickRatePreview(queue[0].Track)
+}
+
+// handleQuickRateKey gère le clavier dans la vue de notation rapide
+func (m Model) handleQuickRateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Ignorer les pseudo-touches internes ("next", "played"...)
+	if msg.Type == tea.KeyRunes && len(msg.Runes) > 1 && !msg.Paste {
+		return m, nil
+	}
+
+	current := m.quickRateQueue[m.quickRateIndex]
+
+	switch key := msg.String(); key {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "1", "2", "3", "4", "5":
+		score := int(key[0] - '0')
+		if err := m.db.SetInitialEloFromScore(current.Track.ID, score); err != nil {
+			m.statusMessage = "⚠️  " + err.Error()
+			return m.nextQuickRate()
+		}
+		m.quickRateCount++
+		m.statusMessage = fmt.Sprintf("⭐ %s : %d/5 (Elo de départ %d)",
+			current.Track.Name, score, current.Rating.Elo+store.QuickRateOffsets[score])
+		return m.nextQuickRate()
+
+	case "s", "right", "l":
+		m.statusMessage = "⏭️ " + current.Track.Name + " laissé aux duels"
+		return m.nextQuickRate()
+
+	case " ":
+		return m, m.playQuickRatePreview(current.Track)
+
+	case "q", "esc", "escape":
+		return m.finishQuickRate()
+	}
+
+	return m, nil
+}
+
+// handleQuickRatePreview suit la lecture de l'extrait : un track impossible
+// à lire est laissé aux duels, sinon la lecture s'arrête après l'extrait
+func (m Model) handleQuickRatePreview(msg QuickRatePreviewMsg) (tea.Model, tea.Cmd) {
+	if m.currentView != ViewQuickRate || m.quickRateIndex >= len(m.quickRateQueue) ||
+		m.quickRateQueue[m.quickRateIndex].Track.ID != msg.TrackID {
+		return m, nil // Track déjà noté ou passé entre-temps
+	}
+
+	if msg.Err != nil {
+		m.statusMessage = "⏭️ Extrait indisponible pour " + m.quickRateQueue[m.quickRateIndex].Track.Name + ", laissé aux duels"
+		return m.nextQuickRate()
+	}
+
+	m.playbackStarted = msg.StartedAt
+	return m, tea.Tick(quickRatePreviewLength, func(time.Time) tea.Msg {
+		return SnippetEndMsg{StartedAt: msg.StartedAt}
+	})
+}
+
+// nextQuickRate passe au track suivant, ou termine la notation en fin de file
+func (m Model) nextQuickRate() (tea.Model, tea.Cmd) {
+	m.quickRateIndex++
+	if m.quickRateIndex >= len(m.quickRateQueue) {
+		return m.finishQuickRate()
+	}
+	return m, m.playQuickRatePreview(m.quickRateQueue[m.quickRateIndex].Track)
+}
+
+// finishQuickRate revient aux duels avec les Elo de départ à jour
+func (m Model) finishQuickRate() (tea.Model, tea.Cmd) {
+	m.quickRateQueue = nil
+	m.currentView = ViewDuel
+	m.libraryStats = m.refreshLibraryStats()
+	if m.quickRateCount > 0 {
+		m.statusMessage = fmt.Sprintf("✅ %d tracks notés, place aux duels !", m.quickRateCount)
+	}
+	return m, tea.Batch(m.pausePlayback(), m.setupNextDuel)
+}
+
+// playQuickRatePreview joue l'extrait d'un track à noter sur Spotify
+func (m Model) playQuickRatePreview(track models.Track) tea.Cmd {
+	return func() tea.Msg {
+		err := m.spotifyClient.PlayTrackAt(track.SpotifyURI, snippetStart(m.playback, track))
+		if spotify.IsNotFound(err) {
+			m.markIfRemoved(track) // Retiré du catalogue : écarté des exports
+		}
+		return QuickRatePreviewMsg{TrackID: track.ID, StartedAt: time.Now(), Err: err}
+	}
+}
+
+// renderQuickRate affiche le track à noter et l'échelle de notes
+func (m Model) renderQuickRate() string {
+	if m.quickRateIndex >= len(m.quickRateQueue) {
+		return m.renderLoading()
+	}
+
+	layout := m.layout()
+	current := m.quickRateQueue[m.quickRateIndex]
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true)
+
+	keyStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true).
+		Width(3)
+
+	labelStyle := lipgloss.NewStyle().
+		Width(14)
+
+	eloStyle := lipgloss.NewStyle().
+		Foreground(ColorMuted)
+
+	card := RenderTrackCard(
+		layout,
+		current.Track.Name,
+		current.Track.Artist,
+		current.Track.Album,
+		current.Track.Year,
+		current.Rating.Elo,
+		currentDeviation(current.Rating),
+		"", // Pas encore de duel : pas de tier
+		current.Rating.Wins,
+		current.Rating.Losses,
+		true,
+		false, // L'Elo de départ est justement ce qui est choisi ici
+	)
+
+	var scale []string
+	for score := 5; score >= 1; score-- {
+		scale = append(scale, keyStyle.Render(fmt.Sprint(score))+
+			labelStyle.Render(quickRateLabels[score])+
+			eloStyle.Render(fmt.Sprintf("starts at %d Elo", current.Rating.Elo+store.QuickRateOffsets[score])))
+	}
+
+	controls := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		Padding(1, 0).
+		Render("1-5 rate  ␣ replay  s skip (battle it instead)  q back")
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		RenderHeader(),
+		"",
+		titleStyle.Render(fmt.Sprintf("🎧 Quick rate %d/%d", m.quickRateIndex+1, len(m.quickRateQueue))),
+		"",
+		lipgloss.JoinHorizontal(lipgloss.Center, card, "   ", lipgloss.JoinVertical(lipgloss.Left, scale...)),
+		controls,
+		RenderFooter(m.statusMessage),
+	)
+}
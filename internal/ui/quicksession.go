@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultQuickRounds est le nombre de duels d'une session rapide lancée depuis l'interface
+// quand ui.rounds (ou -rounds) n'est pas défini
+const defaultQuickRounds = 10
+
+// quickSession compte les duels d'une session rapide : le bilan s'affiche au dernier
+type quickSession struct {
+	rounds int          // Duels prévus, skips compris
+	stats  sessionStats // Compteurs et Elo depuis le début de la session rapide
+}
+
+// QuickSessionCompleteMsg signale que le dernier duel de la session rapide a été joué
+type QuickSessionCompleteMsg struct{}
+
+// newQuickSession démarre une session rapide de rounds duels à partir de l'état actuel
+func (m Model) newQuickSession(rounds int) *quickSession {
+	tracks, _ := m.db.GetAllTracksWithRatings()
+	return &quickSession{rounds: rounds, stats: newSessionStats(tracks)}
+}
+
+// played retourne le nombre de duels joués ou passés depuis le début de la session rapide
+func (q *quickSession) played() int {
+	return q.stats.duels + q.stats.skips
+}
+
+// done indique si tous les duels de la session rapide ont été joués
+func (q *quickSession) done() bool {
+	return q.played() >= q.rounds
+}
+
+// progress retourne l'avancement affiché dans le footer du duel (ex: "☕ 3/10")
+func (q *quickSession) progress() string {
+	return fmt.Sprintf("☕ %d/%d", q.played(), q.rounds)
+}
+
+// nextDuel prépare le duel suivant, ou termine la session rapide une fois tous ses duels joués
+func (m Model) nextDuel() tea.Cmd {
+	if m.quickSession != nil && m.quickSession.done() {
+		return func() tea.Msg { return QuickSessionCompleteMsg{} }
+	}
+	return m.setupNextDuel
+}
+
+// handleToggleQuickSession lance une session rapide, ou l'abandonne si elle est en cours
+func (m Model) handleToggleQuickSession() (tea.Model, tea.Cmd) {
+	if m.quickSession != nil {
+		m.statusMessage = fmt.Sprintf("☕ Session rapide abandonnée après %d duels", m.quickSession.played())
+		m.quickSession = nil
+		return m, nil
+	}
+
+	rounds := m.quickRounds
+	if rounds <= 0 {
+		rounds = defaultQuickRounds
+	}
+	m.quickSession = m.newQuickSession(rounds)
+	m.statusMessage = fmt.Sprintf("☕ Session rapide : bilan dans %d duels", rounds)
+	return m, nil
+}
+
+// handleQuickSessionComplete affiche le bilan de la session rapide à la place du duel suivant
+func (m Model) handleQuickSessionComplete() (tea.Model, tea.Cmd) {
+	if m.quickSession == nil {
+		return m, m.setupNextDuel
+	}
+
+	tracks, err := m.db.GetAllTracksWithRatings()
+	if err != nil {
+		// Le bilan est facultatif : continuer les duels
+		m.quickSession = nil
+		return m, m.setupNextDuel
+	}
+
+	summary := m.quickSession.stats.summarize(tracks)
+	summary.Rounds = m.quickSession.rounds
+	m.sessionSummary = &summary
+	m.quickSession = nil
+	m.leftTrack = nil
+	m.rightTrack = nil
+	m.currentView = ViewSessionSummary
+	return m, m.pauseAppPlayback()
+}
+
+// handleQuickSummaryKey reprend les duels avec Entrée depuis le bilan d'une session rapide ;
+// toute autre touche quitte, comme depuis le bilan de fin de session
+func (m Model) handleQuickSummaryKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.keys.action(msg.String()) != ActionVote {
+		return m, tea.Quit
+	}
+
+	m.sessionSummary = nil
+	m.currentView = ViewDuel
+	m.statusMessage = "Back to battles"
+	return m, m.setupNextDuel
+}
+
+// undo retire de la session rapide un duel annulé joué pendant celle-ci
+func (q *quickSession) undo(createdAt time.Time, skip bool) {
+	if !createdAt.After(q.stats.startedAt) {
+		return
+	}
+	if !skip && q.stats.duels > 0 {
+		q.stats.duels--
+	} else if skip && q.stats.skips > 0 {
+		q.stats.skips--
+	}
+}
@@ -0,0 +1,133 @@
+package ui
+
+import (
+	"fmt"
+	"songbattle/internal/logging"
+	"songbattle/internal/models"
+	"songbattle/internal/spotify"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// recommendationSeeds est le nombre de tracks du haut du classement servant de graines
+const recommendationSeeds = 2
+
+// RecommendationsImportedMsg signale la fin d'un import de recommandations
+type RecommendationsImportedMsg struct {
+	Added    int   // Nouveaux tracks ajoutés à la bibliothèque
+	Deferred int   // Tracks reportés à la file d'import (rate limit)
+	Err      error // Recommandations indisponibles
+}
+
+// handleImportRecommendations importe des recommandations tirées des meilleurs tracks actuels
+func (m Model) handleImportRecommendations() (tea.Model, tea.Cmd) {
+	if m.offlineBlocked() {
+		return m, nil
+	}
+
+	if m.spotifyClient == nil {
+		m.statusMessage = "⚠️  Recommandations indisponibles (client Spotify non initialisé)"
+		return m, nil
+	}
+
+	m.statusMessage = "🎲 Recherche de recommandations..."
+	return m.runInBackground(m.importRecommendations())
+}
+
+// handleRecommendationsImported affiche le nombre de tracks ajoutés
+func (m Model) handleRecommendationsImported(msg RecommendationsImportedMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.statusMessage = "⚠️  Recommandations indisponibles : " + msg.Err.Error()
+		return m, nil
+	}
+
+	m.libraryStats = m.refreshLibraryStats()
+	m.audioAverages = nil // Moyenne à recalculer avec les tracks ajoutés
+	switch {
+	case msg.Added == 0 && msg.Deferred == 0:
+		m.statusMessage = "🎲 Aucune nouvelle recommandation (déjà dans la bibliothèque)"
+	case msg.Deferred > 0:
+		m.statusMessage = fmt.Sprintf("🎲 %d recommandations ajoutées, %d reportées (rate limit, -finish-import)", msg.Added, msg.Deferred)
+	default:
+		m.statusMessage = fmt.Sprintf("🎲 %d recommandations ajoutées à la bibliothèque", msg.Added)
+	}
+	return m, nil
+}
+
+// importRecommendations récupère des recommandations à partir du haut du classement
+// et sauvegarde celles absentes de la bibliothèque, comme l'import du démarrage
+func (m Model) importRecommendations() tea.Cmd {
+	return func() tea.Msg {
+		top, err := m.db.GetTopTracks(5)
+		if err != nil {
+			return RecommendationsImportedMsg{Err: fmt.Errorf("lecture du classement: %w", err)}
+		}
+		if len(top) == 0 {
+			return RecommendationsImportedMsg{Err: fmt.Errorf("aucun track pour amorcer les recommandations")}
+		}
+
+		seeds := make([]string, 0, len(top))
+		for _, track := range top {
+			seeds = append(seeds, track.Track.SpotifyID)
+		}
+
+		recommendations, err := m.spotifyClient.GetRecommendations(seeds[:min(recommendationSeeds, len(seeds))], nil, nil, 20)
+		if err != nil {
+			logging.Printf("[recommendations] %v", err)
+			return RecommendationsImportedMsg{Err: err}
+		}
+
+		return m.saveRecommendations(recommendations)
+	}
+}
+
+// saveRecommendations sauvegarde les recommandations absentes de la bibliothèque
+// Les tracks bloqués par le rate limit rejoignent la file d'import avec leur source
+func (m Model) saveRecommendations(tracks []*models.Track) RecommendationsImportedMsg {
+	var result RecommendationsImportedMsg
+	var deferred []string
+	deferredSources := map[string]string{}
+
+	for _, track := range tracks {
+		if reason := track.NotSongReason(); reason != "" {
+			logging.Printf("[recommendations] skipped %q (%s): %s", track.Name, track.SpotifyID, reason)
+			continue
+		}
+
+		// Déjà présent, éventuellement sous un autre ID Spotify
+		existing, err := m.db.FindExistingTrack(track)
+		if err != nil {
+			logging.Printf("[recommendations] looking up %q: %v", track.Name, err)
+			continue
+		}
+		if existing != nil {
+			continue
+		}
+
+		track.Source = models.SourceRecommendation
+		if err := m.spotifyClient.EnrichTrackWithAudioFeatures(track); err != nil && spotify.IsRateLimited(err) {
+			deferred = append(deferred, track.SpotifyID)
+			deferredSources[track.SpotifyID] = track.Source
+			continue
+		}
+
+		if err := m.db.CreateTrack(track); err != nil {
+			logging.Printf("[recommendations] saving %q: %v", track.Name, err)
+			continue
+		}
+		result.Added++
+	}
+
+	if len(deferred) > 0 {
+		if err := m.db.AddToImportQueue(deferred...); err != nil {
+			logging.Printf("[recommendations] queueing deferred tracks: %v", err)
+			return result
+		}
+		if err := m.db.AddImportSources(deferredSources); err != nil {
+			logging.Printf("[recommendations] recording deferred sources: %v", err)
+		}
+		result.Deferred = len(deferred)
+	}
+
+	return result
+}
@@ -0,0 +1,268 @@
+package ui
+
+import (
+	"fmt"
+	"songbattle/internal/models"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// replayLimit est le nombre maximal de duels rejoués, les plus récents
+const replayLimit = 1000
+
+// replaySpeeds sont les durées d'affichage d'un duel, de la plus lente à la plus rapide
+var replaySpeeds = []time.Duration{3 * time.Second, 2 * time.Second, time.Second, 500 * time.Millisecond, 250 * time.Millisecond}
+
+// defaultReplaySpeed est l'indice de la vitesse de départ dans replaySpeeds
+const defaultReplaySpeed = 2
+
+// replay rejoue l'historique des duels, du plus ancien au plus récent
+type replay struct {
+	id      int // Identifie le défilement en cours, pour ignorer les ticks périmés
+	entries []models.DuelHistoryEntry
+	index   int
+	speed   int
+	paused  bool
+	allTime bool // Tout l'historique plutôt que la session
+}
+
+// ReplayTickMsg fait passer le rejeu au duel suivant
+type ReplayTickMsg struct{ ID int }
+
+// handleShowReplay lance le rejeu des duels de la session, ou de tout l'historique
+// si aucun duel n'a encore été joué dans la session
+func (m Model) handleShowReplay() (tea.Model, tea.Cmd) {
+	entries, err := m.replayEntries(false)
+	if err != nil {
+		m.statusMessage = "⚠️  Impossible de charger l'historique"
+		return m, nil
+	}
+
+	allTime := false
+	if len(entries) == 0 {
+		if entries, err = m.replayEntries(true); err != nil {
+			m.statusMessage = "⚠️  Impossible de charger l'historique"
+			return m, nil
+		}
+		allTime = true
+	}
+	if len(entries) == 0 {
+		m.statusMessage = "Aucun duel à rejouer"
+		return m, nil
+	}
+
+	m.replay = replay{id: m.replay.id, entries: entries, speed: defaultReplaySpeed, allTime: allTime}
+	m.currentView = ViewReplay
+	m.statusMessage = ""
+	if allTime {
+		m.statusMessage = "Aucun duel dans cette session : rejeu de tout l'historique"
+	}
+	return m, m.scheduleReplayTick()
+}
+
+// replayEntries charge les duels à rejouer dans l'ordre chronologique
+func (m Model) replayEntries(allTime bool) ([]models.DuelHistoryEntry, error) {
+	history, err := m.db.GetDuelHistoryDetailed(replayLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]models.DuelHistoryEntry, 0, len(history))
+	for i := len(history) - 1; i >= 0; i-- {
+		if allTime || history[i].CreatedAt.After(m.session.startedAt) {
+			entries = append(entries, history[i])
+		}
+	}
+	return entries, nil
+}
+
+// scheduleReplayTick programme le passage au duel suivant ; les ticks déjà programmés sont périmés
+func (m *Model) scheduleReplayTick() tea.Cmd {
+	m.replay.id++
+	id := m.replay.id
+	return tea.Tick(replaySpeeds[m.replay.speed], func(time.Time) tea.Msg {
+		return ReplayTickMsg{ID: id}
+	})
+}
+
+// handleReplayTick avance d'un duel, puis s'arrête sur le dernier
+func (m Model) handleReplayTick(msg ReplayTickMsg) (tea.Model, tea.Cmd) {
+	if msg.ID != m.replay.id || m.currentView != ViewReplay || m.replay.paused {
+		return m, nil
+	}
+
+	if m.replay.index >= len(m.replay.entries)-1 {
+		m.replay.paused = true
+		m.statusMessage = "🏁 Fin du rejeu"
+		return m, nil
+	}
+
+	m.replay.index++
+	return m, m.scheduleReplayTick()
+}
+
+// handleReplayKey gère le clavier du rejeu : pause, pas à pas, vitesse et portée
+func (m Model) handleReplayKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case " ":
+		if !m.replay.paused {
+			m.replay.paused = true
+			return m, nil
+		}
+		// Reprendre depuis le début une fois le rejeu terminé
+		if m.replay.index >= len(m.replay.entries)-1 {
+			m.replay.index = 0
+		}
+		m.replay.paused = false
+		m.statusMessage = ""
+		return m, m.scheduleReplayTick()
+
+	case "left", "h":
+		m.replay.paused = true
+		if m.replay.index > 0 {
+			m.replay.index--
+		}
+
+	case "right", "l":
+		m.replay.paused = true
+		if m.replay.index < len(m.replay.entries)-1 {
+			m.replay.index++
+		}
+
+	case "+", "=":
+		if m.replay.speed < len(replaySpeeds)-1 {
+			m.replay.speed++
+		}
+		if !m.replay.paused {
+			return m, m.scheduleReplayTick()
+		}
+
+	case "-":
+		if m.replay.speed > 0 {
+			m.replay.speed--
+		}
+		if !m.replay.paused {
+			return m, m.scheduleReplayTick()
+		}
+
+	case "a":
+		entries, err := m.replayEntries(!m.replay.allTime)
+		if err != nil {
+			m.statusMessage = "⚠️  Impossible de charger l'historique"
+			return m, nil
+		}
+		if len(entries) == 0 {
+			m.statusMessage = "Aucun duel dans cette session"
+			return m, nil
+		}
+		m.replay.entries = entries
+		m.replay.allTime = !m.replay.allTime
+		m.replay.index = 0
+		m.replay.paused = false
+		m.statusMessage = ""
+		return m, m.scheduleReplayTick()
+
+	case "q", "esc", "escape", "P":
+		m.replay = replay{id: m.replay.id}
+		m.currentView = ViewDuel
+		m.statusMessage = "Back to battles"
+	}
+
+	return m, nil
+}
+
+// renderReplay affiche le duel en cours du rejeu, avec la variation d'Elo de chaque côté
+func (m Model) renderReplay() string {
+	if len(m.replay.entries) == 0 {
+		return m.renderLoading()
+	}
+
+	entry := &m.replay.entries[m.replay.index]
+	layout := m.layout()
+
+	scope := "this session"
+	if m.replay.allTime {
+		scope = "all time"
+	}
+	title := lipgloss.NewStyle().Foreground(ColorPrimary).Bold(true).Render(
+		fmt.Sprintf("🎬 Replay (%s)  •  battle %d/%d  •  %s", scope, m.replay.index+1, len(m.replay.entries), historyTime(entry.CreatedAt)))
+
+	cards := m.joinCards(layout,
+		replayCard(layout, entry, entry.Left, entry.LeftEloBefore, entry.LeftEloAfter),
+		replayCard(layout, entry, entry.Right, entry.RightEloBefore, entry.RightEloAfter),
+	)
+
+	state := "▶"
+	if m.replay.paused {
+		state = "⏸"
+	}
+	progress := lipgloss.NewStyle().Foreground(ColorMuted).Render(
+		fmt.Sprintf("%s %s  %v per battle", state, replayProgress(m.replay.index+1, len(m.replay.entries), 30), replaySpeeds[m.replay.speed]))
+
+	controls := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		Padding(1, 0).
+		Render("␣ pause/resume  ←→ step  +/- speed  a session/all time  q back")
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		RenderHeader(),
+		"",
+		title,
+		"",
+		cards,
+		"",
+		progress,
+		controls,
+		RenderFooter(m.statusMessage),
+	)
+}
+
+// replayCard affiche un côté d'un duel rejoué : le vainqueur est encadré, l'Elo passe d'avant à après
+func replayCard(layout LayoutConfig, entry *models.DuelHistoryEntry, track models.Track, before, after int) string {
+	won := entry.WinnerTrackID != nil && *entry.WinnerTrackID == track.ID
+
+	style := TrackCardStyle
+	if won {
+		style = TrackCardActiveStyle.BorderForeground(ColorSuccess)
+	}
+	style = style.Width(layout.CardWidth)
+	inner := layout.CardWidth - 4
+
+	outcome := lipgloss.NewStyle().Foreground(ColorMuted).Render("skip")
+	if entry.Rated {
+		delta := after - before
+		deltaStyle := lipgloss.NewStyle().Foreground(ColorMuted)
+		switch {
+		case delta > 0:
+			deltaStyle = deltaStyle.Foreground(ColorSuccess)
+		case delta < 0:
+			deltaStyle = deltaStyle.Foreground(ColorError)
+		}
+		outcome = fmt.Sprintf("Elo %d → %d ", before, after) + deltaStyle.Render(fmt.Sprintf("(%+d)", delta))
+	}
+
+	name := Truncate(trackTitle(track), inner-2)
+	if won {
+		name = "🏆 " + Truncate(trackTitle(track), inner-5)
+	}
+
+	return style.Render(lipgloss.JoinVertical(lipgloss.Center,
+		TrackNameStyle.Width(inner).Render(name),
+		ArtistStyle.Width(inner).Render(Truncate(track.Artist, inner-2)),
+		"",
+		lipgloss.NewStyle().Width(inner).Align(lipgloss.Center).Render(outcome),
+	))
+}
+
+// replayProgress dessine une barre de progression de width caractères
+func replayProgress(done, total, width int) string {
+	filled := done * width / total
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
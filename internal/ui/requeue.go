@@ -0,0 +1,68 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// voteOutcome retient les tracks du dernier vote
+type voteOutcome struct {
+	winnerID int64
+	loserID  int64
+}
+
+// handleRequeueLoser redonne une chance au perdant du dernier vote :
+// il sera le track de gauche du prochain duel, face à un nouvel adversaire
+func (m Model) handleRequeueLoser() (tea.Model, tea.Cmd) {
+	if m.lastVote == nil {
+		m.statusMessage = "⚠️  Aucun perdant à rejouer (après un vote seulement)"
+		return m, nil
+	}
+
+	m.requeueLoser = !m.requeueLoser
+	if m.requeueLoser {
+		m.statusMessage = "🔁 Le perdant aura une nouvelle chance au prochain duel"
+	} else {
+		m.statusMessage = "Rejeu du perdant annulé"
+	}
+	return m, nil
+}
+
+// handleRequeueDuel remplace le duel proposé par le matchmaker par le perdant
+// du dernier vote face à un adversaire frais (hors vainqueur)
+// En cas d'échec, le duel proposé est conservé
+func (m Model) handleRequeueDuel(msg DuelSetupCompleteMsg) (tea.Model, tea.Cmd) {
+	outcome := m.lastVote
+	m.requeueLoser = false
+	m.lastVote = nil
+	m.leftTrack = msg.Left
+	m.rightTrack = msg.Right
+	m.updateWinProbability()
+	m.statusMessage = "Prêt pour le duel !"
+
+	if outcome == nil {
+		return m, nil
+	}
+
+	loser, err := m.db.GetTrackWithRating(outcome.loserID)
+	if err != nil {
+		return m, nil
+	}
+
+	opponent, err := m.matchmaker.FindOpponentFor(loser, outcome.winnerID)
+	if err != nil {
+		m.statusMessage = "⚠️  Pas de nouvel adversaire pour " + loser.Track.Name
+		return m, nil
+	}
+
+	// Le duel imposé écarté sera proposé au duel suivant
+	if msg.Queued {
+		m.matchmaker.ReturnQueuedMatch(msg.Left.Track.ID, msg.Right.Track.ID)
+	}
+
+	m.leftTrack = loser
+	m.rightTrack = opponent
+	m.updateWinProbability()
+	m.focus = FocusLeft
+	m.statusMessage = "🔁 " + loser.Track.Name + " retente sa chance !"
+	return m, nil
+}
@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"songbattle/internal/logging"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// saveCurrentDuel mémorise le duel affiché pour le reprendre au prochain lancement ;
+// l'écriture se fait hors de la boucle de rendu
+func (m Model) saveCurrentDuel() tea.Cmd {
+	if m.leftTrack == nil || m.rightTrack == nil {
+		return nil
+	}
+
+	db, left, right := m.db, m.leftTrack.Track.ID, m.rightTrack.Track.ID
+	return func() tea.Msg {
+		if err := db.SetCurrentDuel(left, right); err != nil {
+			logging.Printf("[duel] saving current duel failed: %v", err)
+		}
+		return nil
+	}
+}
+
+// resumeDuel reprend le duel affiché lors du dernier lancement, ou en tire un nouveau
+// s'il n'y en a pas ou si l'un de ses tracks a été supprimé depuis
+func (m Model) resumeDuel() tea.Msg {
+	leftID, rightID, err := m.db.GetCurrentDuel()
+	if err != nil || leftID == 0 || rightID == 0 || leftID == rightID {
+		return m.setupNextDuel()
+	}
+
+	left, err := m.db.GetTrackWithRating(leftID)
+	if err != nil {
+		logging.Printf("[duel] saved duel not resumed, track %d: %v", leftID, err)
+		return m.setupNextDuel()
+	}
+	right, err := m.db.GetTrackWithRating(rightID)
+	if err != nil {
+		logging.Printf("[duel] saved duel not resumed, track %d: %v", rightID, err)
+		return m.setupNextDuel()
+	}
+
+	return DuelSetupCompleteMsg{Left: left, Right: right}
+}
@@ -0,0 +1,263 @@
+package ui
+
+import (
+	"fmt"
+	"songbattle/internal/models"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// SearchResultsMsg contient les résultats d'une recherche Spotify
+type SearchResultsMsg struct {
+	Query   string
+	Results []*models.Track
+}
+
+// TrackAddedMsg signale qu'un track issu de la recherche a été ajouté
+type TrackAddedMsg struct {
+	Track  *models.TrackWithRating
+	Battle bool // Lancer directement un duel avec ce track
+}
+
+// handleShowSearch ouvre la recherche de tracks
+func (m Model) handleShowSearch() (tea.Model, tea.Cmd) {
+	if m.offlineBlocked() {
+		return m, nil
+	}
+
+	if m.spotifyClient == nil {
+		m.statusMessage = "⚠️  Recherche indisponible (client Spotify non initialisé)"
+		return m, nil
+	}
+
+	m.searchQuery = ""
+	m.searchResults = nil
+	m.searchCursor = 0
+	m.searchEditing = true
+	m.currentView = ViewSearch
+	m.statusMessage = "Tapez un titre ou un artiste puis Entrée"
+	return m, nil
+}
+
+// handleSearchKey gère le clavier dans la vue de recherche
+// Les raccourcis globaux sont désactivés pour pouvoir saisir librement la requête
+func (m Model) handleSearchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc", "escape":
+		m.currentView = ViewDuel
+		m.statusMessage = "Back to battles"
+		if m.leftTrack == nil || m.rightTrack == nil {
+			// Pas encore de duel (bibliothèque vide) : retenter le matchmaking
+			return m, m.setupNextDuel
+		}
+		return m, nil
+	}
+
+	if m.searchEditing {
+		switch msg.Type {
+		case tea.KeyEnter:
+			if m.searchQuery == "" {
+				return m, nil
+			}
+			m.statusMessage = "🔍 Recherche de \"" + m.searchQuery + "\"..."
+			return m, m.searchTracks(m.searchQuery)
+
+		case tea.KeyBackspace:
+			if runes := []rune(m.searchQuery); len(runes) > 0 {
+				m.searchQuery = string(runes[:len(runes)-1])
+			}
+			return m, nil
+
+		case tea.KeySpace:
+			m.searchQuery += " "
+			return m, nil
+
+		case tea.KeyRunes:
+			// Ignorer les pseudo-touches internes ("next", "played"...) hors collage
+			if msg.Paste || len(msg.Runes) == 1 {
+				m.searchQuery += string(msg.Runes)
+			}
+			return m, nil
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if m.searchCursor > 0 {
+			m.searchCursor--
+		}
+	case "down", "j":
+		if m.searchCursor < len(m.searchResults)-1 {
+			m.searchCursor++
+		}
+	case "/":
+		m.searchEditing = true
+	case "enter":
+		return m.handleAddSearchResult(true)
+	case "a":
+		return m.handleAddSearchResult(false)
+	}
+
+	return m, nil
+}
+
+// handleAddSearchResult ajoute le résultat sélectionné à la bibliothèque
+func (m Model) handleAddSearchResult(battle bool) (tea.Model, tea.Cmd) {
+	if len(m.searchResults) == 0 || m.searchCursor >= len(m.searchResults) {
+		return m, nil
+	}
+
+	track := m.searchResults[m.searchCursor]
+	m.statusMessage = "➕ Ajout de " + track.Name + "..."
+	return m.runInBackground(m.addSearchResult(track, battle))
+}
+
+// handleTrackAdded traite l'ajout d'un track et lance éventuellement un duel
+func (m Model) handleTrackAdded(msg TrackAddedMsg) (tea.Model, tea.Cmd) {
+	m.libraryStats = m.refreshLibraryStats()
+	m.audioAverages = nil // Moyenne à recalculer avec les tracks ajoutés
+
+	if !msg.Battle {
+		m.statusMessage = "✅ " + msg.Track.Track.Name + " ajouté à la bibliothèque"
+		return m, nil
+	}
+
+	opponent, err := m.matchmaker.FindOpponentFor(msg.Track)
+	if err != nil {
+		m.statusMessage = "✅ " + msg.Track.Track.Name + " ajouté (pas d'adversaire disponible)"
+		return m, nil
+	}
+
+	m.leftTrack = msg.Track
+	m.rightTrack = opponent
+	m.listenStart = time.Time{}
+	m.updateWinProbability()
+	m.focus = FocusLeft
+	m.currentView = ViewDuel
+	m.statusMessage = "⚔️ " + msg.Track.Track.Name + " entre dans l'arène !"
+	return m, nil
+}
+
+// searchTracks lance la recherche Spotify
+func (m Model) searchTracks(query string) tea.Cmd {
+	return func() tea.Msg {
+		results, err := m.spotifyClient.SearchTracks(query, 15)
+		if err != nil {
+			return ErrorMsg{Err: fmt.Errorf("erreur recherche Spotify: %w", err)}
+		}
+		return SearchResultsMsg{Query: query, Results: results}
+	}
+}
+
+// addSearchResult enrichit et sauvegarde un track (ou réutilise l'existant)
+func (m Model) addSearchResult(track *models.Track, battle bool) tea.Cmd {
+	return func() tea.Msg {
+		// Déjà présent, éventuellement sous un autre ID Spotify
+		existing, err := m.db.FindExistingTrack(track)
+		if err != nil {
+			return ErrorMsg{Err: fmt.Errorf("erreur lecture track: %w", err)}
+		}
+		if existing != nil {
+			saved, err := m.db.GetTrackWithRating(existing.ID)
+			if err != nil {
+				return ErrorMsg{Err: fmt.Errorf("erreur lecture track: %w", err)}
+			}
+			return TrackAddedMsg{Track: saved, Battle: battle}
+		}
+
+		// Les audio features sont optionnelles
+		_ = m.spotifyClient.EnrichTrackWithAudioFeatures(track)
+		track.Source = models.SourceSearch
+
+		if err := m.db.CreateTrack(track); err != nil {
+			return ErrorMsg{Err: fmt.Errorf("erreur sauvegarde track %s: %w", track.Name, err)}
+		}
+
+		saved, err := m.db.GetTrackWithRating(track.ID)
+		if err != nil {
+			return ErrorMsg{Err: fmt.Errorf("erreur lecture track: %w", err)}
+		}
+		return TrackAddedMsg{Track: saved, Battle: battle}
+	}
+}
+
+// renderSearch affiche la recherche et ses résultats
+func (m Model) renderSearch() string {
+	inputStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true)
+
+	cursor := ""
+	if m.searchEditing {
+		cursor = "█"
+	}
+
+	nameStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Width(40)
+
+	artistStyle := lipgloss.NewStyle().
+		Foreground(ColorSecondary).
+		Width(30)
+
+	yearStyle := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		Width(8).
+		Align(lipgloss.Right)
+
+	selectedStyle := lipgloss.NewStyle().
+		Background(ColorPrimary).
+		Foreground(lipgloss.Color("#000000")).
+		Bold(true)
+
+	var lines []string
+	for i, track := range m.searchResults {
+		year := ""
+		if track.Year > 0 {
+			year = fmt.Sprintf("%d", track.Year)
+		}
+
+		line := lipgloss.JoinHorizontal(
+			lipgloss.Top,
+			nameStyle.Render(Truncate(track.Name, 38)),
+			artistStyle.Render(Truncate(track.Artist, 28)),
+			yearStyle.Render(year),
+		)
+		if !m.searchEditing && i == m.searchCursor {
+			line = selectedStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) == 0 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(ColorMuted).Render("No results yet"))
+	}
+
+	help := "type a query  ↵ search  esc back"
+	if !m.searchEditing {
+		help = "↑↓ navigate  ↵ add & battle  a add  / edit query  esc back"
+	}
+
+	controls := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		Padding(1, 0).
+		Render(help)
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		RenderHeader(),
+		"",
+		inputStyle.Render("🔍 "+m.searchQuery+cursor),
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, lines...),
+		"",
+		controls,
+		RenderFooter(m.statusMessage),
+	)
+}
@@ -0,0 +1,129 @@
+package ui
+
+import (
+	"fmt"
+	"songbattle/internal/export"
+	"songbattle/internal/spotify"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// PlaylistExportedMsg signale qu'une playlist a été créée : top du classement (p)
+// ou sélection du leaderboard
+type PlaylistExportedMsg struct {
+	Info      *export.PlaylistInfo
+	Selection bool // Export de la sélection, à vider une fois la playlist créée
+}
+
+// handleToggleSelectMode active ou désactive la sélection multiple dans le leaderboard
+// La sélection est conservée en quittant le mode, jusqu'à un export réussi
+func (m Model) handleToggleSelectMode() (tea.Model, tea.Cmd) {
+	m.leaderboardSelecting = !m.leaderboardSelecting
+	return m, nil
+}
+
+// handleToggleSelection coche ou décoche le track sous le curseur
+func (m Model) handleToggleSelection() (tea.Model, tea.Cmd) {
+	if len(m.leaderboard) == 0 || m.leaderboardCursor >= len(m.leaderboard) {
+		return m, nil
+	}
+
+	// Copie de la map : le modèle est passé par valeur
+	selected := make(map[int64]bool, len(m.leaderboardSelected)+1)
+	for id := range m.leaderboardSelected {
+		selected[id] = true
+	}
+
+	trackID := m.leaderboard[m.leaderboardCursor].Track.ID
+	if selected[trackID] {
+		delete(selected, trackID)
+	} else {
+		selected[trackID] = true
+	}
+	m.leaderboardSelected = selected
+
+	// Descendre automatiquement pour enchaîner les sélections
+	if m.leaderboardCursor < len(m.leaderboard)-1 {
+		m.leaderboardCursor++
+	}
+	return m, nil
+}
+
+// handleExportSelection exporte les tracks sélectionnés vers une playlist Spotify
+func (m Model) handleExportSelection() (tea.Model, tea.Cmd) {
+	if m.offlineBlocked() {
+		return m, nil
+	}
+
+	if len(m.leaderboardSelected) == 0 {
+		m.statusMessage = "⚠️  Aucun track sélectionné"
+		return m, nil
+	}
+	if m.spotifyClient == nil {
+		m.statusMessage = "⚠️  Export indisponible (client Spotify non initialisé)"
+		return m, nil
+	}
+
+	// Retour au duel : le résultat de l'export s'affiche dans la barre de statut
+	m.currentView = ViewDuel
+	m.statusMessage = fmt.Sprintf("📝 Export de %d tracks en cours...", len(m.leaderboardSelected))
+	return m.runInBackground(m.exportSelection(m.selectedTrackIDs()))
+}
+
+// selectedTrackIDs retourne les IDs sélectionnés dans l'ordre du classement affiché
+func (m Model) selectedTrackIDs() []int64 {
+	ids := make([]int64, 0, len(m.leaderboardSelected))
+	seen := make(map[int64]bool, len(m.leaderboardSelected))
+	for _, track := range m.leaderboard {
+		if m.leaderboardSelected[track.Track.ID] {
+			ids = append(ids, track.Track.ID)
+			seen[track.Track.ID] = true
+		}
+	}
+
+	// Tracks sélectionnés dans un autre classement (genre, disputés)
+	for id := range m.leaderboardSelected {
+		if !seen[id] {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// exportSelection crée la playlist à partir des IDs sélectionnés
+func (m Model) exportSelection(trackIDs []int64) tea.Cmd {
+	return func() tea.Msg {
+		exporter := export.NewPlaylistExporterWithConfig(m.db, m.spotifyClient, m.ctx, m.exportConfig)
+
+		name := fmt.Sprintf("Song Battle Selection %s", time.Now().Format("2006-01-02"))
+		info, err := exporter.ExportCustomPlaylist(trackIDs, name, "")
+		if err != nil {
+			return playlistExportError("erreur export sélection", err)
+		}
+
+		return PlaylistExportedMsg{Info: info, Selection: true}
+	}
+}
+
+// playlistExportError explique l'échec d'un export de playlist, en guidant vers -reauth
+// quand le token n'a pas la permission de créer des playlists
+func playlistExportError(context string, err error) ErrorMsg {
+	if spotify.IsMissingScope(err) {
+		return ErrorMsg{Err: fmt.Errorf("Spotify n'autorise pas encore la création de playlists : relancez avec -reauth pour accorder la permission")}
+	}
+	return ErrorMsg{Err: fmt.Errorf("%s: %w", context, err)}
+}
+
+// handlePlaylistExported affiche la playlist créée, et vide la sélection exportée
+func (m Model) handlePlaylistExported(msg PlaylistExportedMsg) (tea.Model, tea.Cmd) {
+	if msg.Selection {
+		m.leaderboardSelected = nil
+		m.leaderboardSelecting = false
+	}
+	m.statusMessage = fmt.Sprintf("✅ Playlist \"%s\" créée (%d tracks)", msg.Info.Name, msg.Info.TrackCount)
+	if msg.Info.URL != "" {
+		m.statusMessage += " : " + msg.Info.URL
+	}
+	return m, nil
+}
@@ -0,0 +1,184 @@
+package ui
+
+import (
+	"fmt"
+	"songbattle/internal/elo"
+	"songbattle/internal/models"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// sessionStats conserve les données de la session en cours
+type sessionStats struct {
+	startedAt   time.Time
+	startElo    map[int64]int // Elo de chaque track au lancement
+	startLeader string        // Nom du leader au lancement
+	duels       int
+	skips       int
+
+	// Compteurs à la dernière sauvegarde automatique
+	savedDuels int
+	savedSkips int
+}
+
+// TrackMovement décrit la variation d'Elo d'un track pendant la session
+type TrackMovement struct {
+	Track  models.TrackWithRating
+	Change int
+}
+
+// SessionSummary résume la session affichée avant de quitter
+type SessionSummary struct {
+	Duels       int
+	Skips       int
+	Duration    time.Duration
+	Riser       *TrackMovement
+	Faller      *TrackMovement
+	Leader      string
+	LeaderIsNew bool
+	Rounds      int // Duels prévus de la session rapide résumée (0 pour un bilan de fin de session)
+}
+
+// newSessionStats prend un instantané des Elo au démarrage
+func newSessionStats(tracks []models.TrackWithRating) sessionStats {
+	stats := sessionStats{
+		startedAt: time.Now(),
+		startElo:  make(map[int64]int, len(tracks)),
+	}
+
+	var leaderElo int
+	for _, track := range tracks {
+		stats.startElo[track.Track.ID] = track.Rating.Elo
+		if stats.startLeader == "" || track.Rating.Elo > leaderElo {
+			stats.startLeader = track.Track.Name
+			leaderElo = track.Rating.Elo
+		}
+	}
+
+	return stats
+}
+
+// summarize compare l'état actuel à l'instantané de début de session
+func (s sessionStats) summarize(tracks []models.TrackWithRating) SessionSummary {
+	summary := SessionSummary{
+		Duels:    s.duels,
+		Skips:    s.skips,
+		Duration: time.Since(s.startedAt),
+	}
+
+	var leaderElo int
+	for _, track := range tracks {
+		if summary.Leader == "" || track.Rating.Elo > leaderElo {
+			summary.Leader = track.Track.Name
+			leaderElo = track.Rating.Elo
+		}
+
+		startElo, ok := s.startElo[track.Track.ID]
+		if !ok {
+			// Track ajouté pendant la session
+			startElo = elo.InitialElo
+		}
+
+		change := track.Rating.Elo - startElo
+		if change > 0 && (summary.Riser == nil || change > summary.Riser.Change) {
+			summary.Riser = &TrackMovement{Track: track, Change: change}
+		}
+		if change < 0 && (summary.Faller == nil || change < summary.Faller.Change) {
+			summary.Faller = &TrackMovement{Track: track, Change: change}
+		}
+	}
+
+	summary.LeaderIsNew = summary.Leader != "" && summary.Leader != s.startLeader
+
+	return summary
+}
+
+// handleQuit quitte l'application, en passant par le résumé de session si activé
+func (m Model) handleQuit() (tea.Model, tea.Cmd) {
+	if !m.confirmQuit() {
+		return m, nil
+	}
+
+	if !m.showSessionSummary || m.session.duels == 0 {
+		return m, tea.Quit
+	}
+
+	tracks, err := m.db.GetAllTracksWithRatings()
+	if err != nil {
+		// Le résumé est facultatif : ne pas bloquer la sortie
+		return m, tea.Quit
+	}
+
+	summary := m.session.summarize(tracks)
+	m.sessionSummary = &summary
+	m.currentView = ViewSessionSummary
+	return m, nil
+}
+
+// renderSessionSummary affiche le bilan de la session
+func (m Model) renderSessionSummary() string {
+	if m.sessionSummary == nil {
+		return m.renderLoading()
+	}
+	summary := m.sessionSummary
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		Width(18)
+
+	valueStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true)
+
+	line := func(label, value string) string {
+		return lipgloss.JoinHorizontal(lipgloss.Top, labelStyle.Render(label), valueStyle.Render(value))
+	}
+
+	duels := fmt.Sprintf("%d", summary.Duels)
+	if summary.Skips > 0 {
+		duels += fmt.Sprintf(" (+%d skipped)", summary.Skips)
+	}
+
+	lines := []string{
+		line("Duels", duels),
+		line("Time spent", summary.Duration.Round(time.Second).String()),
+	}
+
+	if summary.Riser != nil {
+		lines = append(lines, line("Biggest riser", fmt.Sprintf("%s  %+d",
+			Truncate(summary.Riser.Track.Track.Name, 35), summary.Riser.Change)))
+	}
+	if summary.Faller != nil {
+		lines = append(lines, line("Biggest faller", fmt.Sprintf("%s  %+d",
+			Truncate(summary.Faller.Track.Track.Name, 35), summary.Faller.Change)))
+	}
+
+	leader := Truncate(summary.Leader, 35)
+	if summary.LeaderIsNew {
+		leader = "👑 " + leader + " (new!)"
+	}
+	lines = append(lines, line("Leader", leader))
+
+	help := "Press any key to exit"
+	footer := "Session summary"
+	if summary.Rounds > 0 {
+		help = fmt.Sprintf("Press %s to keep battling  •  any other key to exit", m.keys.label(ActionVote))
+		footer = fmt.Sprintf("☕ Quick session complete: %d battles", summary.Rounds)
+	}
+	controls := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		Padding(1, 0).
+		Render(help)
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		RenderHeader(),
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, lines...),
+		"",
+		controls,
+		RenderFooter(footer),
+	)
+}
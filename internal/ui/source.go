@@ -0,0 +1,54 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sourceLabel retourne la source d'import d'un track telle qu'affichée
+func sourceLabel(source string) string {
+	if source == "" {
+		return "unknown"
+	}
+	return source
+}
+
+// handleCycleSource filtre le classement par source d'import, en passant à la source suivante
+// Après la dernière source, le classement complet est de nouveau affiché
+func (m Model) handleCycleSource() (tea.Model, tea.Cmd) {
+	sources, err := m.db.GetSources()
+	if err != nil {
+		m.statusMessage = "⚠️  Impossible de charger les sources d'import"
+		return m, nil
+	}
+
+	if len(sources) == 0 {
+		m.statusMessage = "Aucune source d'import enregistrée (tracks importés avant le suivi des sources)"
+		return m, nil
+	}
+
+	next := 0
+	for i, source := range sources {
+		if source == m.leaderboardSource {
+			next = i + 1
+			break
+		}
+	}
+	if next >= len(sources) {
+		return m.handleShowLeaderboard()
+	}
+
+	source := sources[next]
+	tracks, err := m.db.GetLeaderboardBySource(source, 500)
+	if err != nil {
+		m.statusMessage = "⚠️  Impossible de charger le classement " + source
+		return m, nil
+	}
+
+	m.setLeaderboard(tracks)
+	m.leaderboardCursor = 0
+	m.leaderboardGenre = ""
+	m.leaderboardSource = source
+	m.leaderboardMood = ""
+	m.leaderboardMode = leaderboardByElo
+	return m, nil
+}
@@ -0,0 +1,240 @@
+package ui
+
+import (
+	"fmt"
+	"songbattle/internal/elo"
+	"songbattle/internal/models"
+	"songbattle/internal/store"
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// histogramBucketSize est la largeur des tranches d'Elo de l'histogramme
+const histogramBucketSize = 50
+
+// sessionBusiestRows est le nombre de tracks les plus vus affichés dans les statistiques
+const sessionBusiestRows = 5
+
+// sessionAppearance associe un track à son nombre de duels proposés dans la session
+type sessionAppearance struct {
+	name  string
+	count int
+}
+
+// busiestThisSession retourne les tracks les plus proposés depuis le lancement,
+// ainsi que le nombre minimum et maximum d'apparitions sur la bibliothèque
+func busiestThisSession(tracks []models.TrackWithRating, counts map[int64]int, limit int) ([]sessionAppearance, [2]int) {
+	var busiest []sessionAppearance
+	span := [2]int{-1, 0}
+	for _, track := range tracks {
+		count := counts[track.Track.ID]
+		if span[0] < 0 || count < span[0] {
+			span[0] = count
+		}
+		if count > span[1] {
+			span[1] = count
+		}
+		if count > 0 {
+			busiest = append(busiest, sessionAppearance{name: track.Track.Name, count: count})
+		}
+	}
+	if span[0] < 0 {
+		span[0] = 0
+	}
+
+	sort.SliceStable(busiest, func(i, j int) bool {
+		return busiest[i].count > busiest[j].count
+	})
+	if len(busiest) > limit {
+		busiest = busiest[:limit]
+	}
+	return busiest, span
+}
+
+// mostSkippedRows est le nombre de tracks les plus passés affichés dans les statistiques
+const mostSkippedRows = 5
+
+// mostSkipped retourne les tracks passés le plus souvent sans vote, depuis toujours
+// (à égalité, le moins proposé d'abord : la part de skips est plus forte)
+func mostSkipped(tracks []models.TrackWithRating, limit int) []models.TrackWithRating {
+	var skipped []models.TrackWithRating
+	for _, track := range tracks {
+		if track.Rating.Skips > 0 {
+			skipped = append(skipped, track)
+		}
+	}
+
+	sort.SliceStable(skipped, func(i, j int) bool {
+		if skipped[i].Rating.Skips != skipped[j].Rating.Skips {
+			return skipped[i].Rating.Skips > skipped[j].Rating.Skips
+		}
+		return skipped[i].Rating.Appearances < skipped[j].Rating.Appearances
+	})
+	if len(skipped) > limit {
+		skipped = skipped[:limit]
+	}
+	return skipped
+}
+
+// appearanceLabel résume les apparitions d'un track ("shown 40 times, skipped 12")
+func appearanceLabel(rating models.Rating) string {
+	return fmt.Sprintf("shown %d times, skipped %d", rating.Appearances, rating.Skips)
+}
+
+// libraryStats résume la bibliothèque (nombre de tracks, plage d'Elo, duels)
+type libraryStats struct {
+	tracks      int
+	minElo      int
+	maxElo      int
+	duels       int
+	unavailable int                // Tracks retirés du catalogue Spotify
+	tiers       elo.TierThresholds // Seuils des tiers S/A/B/C/D de la bibliothèque
+}
+
+// loadLibraryStats calcule le résumé à partir de tracks déjà chargés
+func loadLibraryStats(db *store.DB, tracks []models.TrackWithRating) libraryStats {
+	stats := elo.ComputeEloStats(tracks)
+	duels, _ := db.GetDuelCount()
+
+	return libraryStats{
+		tracks:      stats["total_tracks"].(int),
+		minElo:      stats["min_elo"].(int),
+		maxElo:      stats["max_elo"].(int),
+		duels:       duels,
+		unavailable: len(tracks) - len(models.WithoutUnavailable(tracks)),
+		tiers:       stats["tiers"].(elo.TierThresholds),
+	}
+}
+
+// refreshLibraryStats recalcule le résumé après un vote ou un ajout
+// En cas d'erreur, le résumé précédent est conservé
+func (m Model) refreshLibraryStats() libraryStats {
+	tracks, err := m.db.GetAllTracksWithRatings()
+	if err != nil {
+		return m.libraryStats
+	}
+	return loadLibraryStats(m.db, tracks)
+}
+
+// render affiche le résumé sur une ligne ("42 tracks • Elo 980–1480 • 137 battles")
+func (s libraryStats) render() string {
+	line := fmt.Sprintf("%d tracks", s.tracks)
+	if s.tracks > 0 {
+		line += fmt.Sprintf(" • Elo %d–%d", s.minElo, s.maxElo)
+	}
+	line += fmt.Sprintf(" • %d battles", s.duels)
+	if s.unavailable > 0 {
+		line += fmt.Sprintf(" • %s%d unavailable", unavailableMarker, s.unavailable)
+	}
+
+	return lipgloss.NewStyle().Foreground(ColorMuted).Render(line)
+}
+
+// handleShowStats affiche les statistiques Elo de la bibliothèque
+func (m Model) handleShowStats() (tea.Model, tea.Cmd) {
+	// Un seul chargement pour les statistiques et l'histogramme
+	tracks, err := m.db.GetAllTracksWithRatings()
+	if err != nil {
+		m.statusMessage = "⚠️  Impossible de charger les statistiques"
+		return m, nil
+	}
+
+	m.eloStats = elo.ComputeEloStats(tracks)
+	m.eloHistogram = elo.BuildEloHistogram(tracks, histogramBucketSize)
+	m.sessionBusiest, m.sessionDuelSpan = busiestThisSession(tracks, m.matchmaker.SessionAppearances(), sessionBusiestRows)
+	m.mostSkipped = mostSkipped(tracks, mostSkippedRows)
+	m.currentView = ViewStats
+	return m, nil
+}
+
+// renderStats affiche les statistiques Elo et leur distribution
+func (m Model) renderStats() string {
+	labelStyle := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		Width(14)
+
+	valueStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true)
+
+	line := func(label string, value interface{}) string {
+		return lipgloss.JoinHorizontal(lipgloss.Top, labelStyle.Render(label), valueStyle.Render(fmt.Sprint(value)))
+	}
+
+	lines := []string{
+		line("Tracks", m.eloStats["total_tracks"]),
+		line("Average Elo", m.eloStats["average_elo"]),
+		line("Min Elo", m.eloStats["min_elo"]),
+		line("Max Elo", m.eloStats["max_elo"]),
+		"",
+		lipgloss.NewStyle().Bold(true).Render("Elo distribution"),
+	}
+	lines = append(lines, renderHistogram(m.eloHistogram, histogramBucketSize, 40)...)
+
+	lines = append(lines, "", lipgloss.NewStyle().Bold(true).Render("This session"))
+	if len(m.sessionBusiest) == 0 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(ColorMuted).Render("No battles yet"))
+	} else {
+		lines = append(lines, line("Per track", fmt.Sprintf("%d–%d battles", m.sessionDuelSpan[0], m.sessionDuelSpan[1])))
+		for _, appearance := range m.sessionBusiest {
+			lines = append(lines, line(fmt.Sprintf("%d battles", appearance.count), Truncate(appearance.name, 38)))
+		}
+	}
+
+	lines = append(lines, "", lipgloss.NewStyle().Bold(true).Render("Most skipped"))
+	if len(m.mostSkipped) == 0 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(ColorMuted).Render("No skips yet"))
+	}
+	for _, track := range m.mostSkipped {
+		lines = append(lines, line(fmt.Sprintf("%d/%d shown", track.Rating.Skips, track.Rating.Appearances), Truncate(track.Track.Name, 38)))
+	}
+
+	controls := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		Padding(1, 0).
+		Render("q back")
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		RenderHeader(),
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, lines...),
+		"",
+		controls,
+		RenderFooter(m.statusMessage),
+	)
+}
+
+// renderHistogram génère une barre horizontale par tranche, à l'échelle de la plus grande
+func renderHistogram(histogram map[int]int, bucketSize, width int) []string {
+	if len(histogram) == 0 {
+		return []string{lipgloss.NewStyle().Foreground(ColorMuted).Render("No tracks yet")}
+	}
+
+	buckets := make([]int, 0, len(histogram))
+	maxCount := 0
+	for bucket, count := range histogram {
+		buckets = append(buckets, bucket)
+		if count > maxCount {
+			maxCount = count
+		}
+	}
+	sort.Ints(buckets)
+
+	rangeStyle := lipgloss.NewStyle().
+		Foreground(ColorSecondary).
+		Width(12)
+
+	// Afficher aussi les tranches vides entre le min et le max
+	var lines []string
+	for bucket := buckets[0]; bucket <= buckets[len(buckets)-1]; bucket += bucketSize {
+		count := histogram[bucket]
+		bar := renderProgressBar(float64(count)/float64(maxCount), width)
+		label := fmt.Sprintf("%d-%d", bucket, bucket+bucketSize-1)
+		lines = append(lines, fmt.Sprintf("%s %s %d", rangeStyle.Render(label), bar, count))
+	}
+
+	return lines
+}
@@ -2,8 +2,12 @@ package ui
 
 import (
 	"fmt"
+	"songbattle/internal/elo"
+	"songbattle/internal/models"
+	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
 // Theme colors
@@ -32,13 +36,11 @@ var (
 			Border(lipgloss.RoundedBorder()).
 			BorderForeground(ColorBorder)
 
-	// Card pour les tracks
+	// Card pour les tracks (dimensions fixées par LayoutConfig)
 	TrackCardStyle = lipgloss.NewStyle().
 			Padding(1, 2).
 			Border(lipgloss.RoundedBorder()).
-			BorderForeground(ColorBorder).
-			Width(40).
-			Height(8)
+			BorderForeground(ColorBorder)
 
 	// Card active (focus)
 	TrackCardActiveStyle = TrackCardStyle.Copy().
@@ -146,47 +148,111 @@ var (
 
 // Fonctions utilitaires pour les styles
 
+// versusWidth is the width of the "VS" column between the cards
+const versusWidth = 6
+
 // RenderTrackCard generates the rendering of a track card
-func RenderTrackCard(name, artist, album string, year, elo, wins, losses int, active bool) string {
+// tier is the S/A/B/C/D badge shown after the Elo ("" for none)
+// hideStats replaces the Elo, tier and W/L lines with a placeholder (fair start)
+func RenderTrackCard(layout LayoutConfig, name, artist, album string, year, elo int, deviation float64, tier string, wins, losses int, active, hideStats bool) string {
 	style := TrackCardStyle
 	if active {
 		style = TrackCardActiveStyle
 	}
+	style = style.Width(layout.CardWidth).Height(layout.CardHeight)
+
+	// Card width minus horizontal padding
+	inner := layout.CardWidth - 4
 
 	yearStr := ""
 	if year > 0 {
 		yearStr = fmt.Sprintf(" (%d)", year)
 	}
 
+	eloLine := EloStyle.Width(inner).Render(fmt.Sprintf("Elo: %d", elo) + deviationSuffix(deviation) + tierSuffix(tier))
+	statsLine := StatsStyle.Width(inner).Render(fmt.Sprintf("%d W • %d L", wins, losses))
+	if hideStats {
+		eloLine = EloStyle.Width(inner).Render("Elo: ?")
+		statsLine = StatsStyle.Width(inner).Render("calibrating…")
+	}
+
 	content := lipgloss.JoinVertical(lipgloss.Center,
-		TrackNameStyle.Render(truncate(name, 34)),
-		ArtistStyle.Render(truncate(artist, 34)),
-		AlbumStyle.Render(truncate(album, 30)+yearStr),
+		TrackNameStyle.Width(inner).Render(Truncate(name, inner-2)),
+		ArtistStyle.Width(inner).Render(Truncate(artist, inner-2)),
+		AlbumStyle.Width(inner).Render(Truncate(album, inner-2-lipgloss.Width(yearStr))+yearStr),
 		"",
-		EloStyle.Render(fmt.Sprintf("Elo: %d", elo)),
-		StatsStyle.Render(fmt.Sprintf("%d W • %d L", wins, losses)),
+		eloLine,
+		statsLine,
 	)
 
 	return style.Render(content)
 }
 
+// tierColors associe chaque tier à sa couleur de badge
+var tierColors = map[string]lipgloss.AdaptiveColor{
+	elo.TierS: ColorWarning,
+	elo.TierA: ColorSuccess,
+	elo.TierB: ColorSecondary,
+	elo.TierC: ColorMuted,
+	elo.TierD: ColorError,
+}
+
+// RenderTierBadge renders a colored tier letter (empty when there is no tier)
+func RenderTierBadge(tier string) string {
+	color, ok := tierColors[tier]
+	if !ok {
+		return ""
+	}
+	return lipgloss.NewStyle().
+		Background(color).
+		Foreground(lipgloss.Color("#000000")).
+		Bold(true).
+		Padding(0, 1).
+		Render(tier)
+}
+
+// tierSuffix sépare le badge du texte qui le précède
+func tierSuffix(tier string) string {
+	if badge := RenderTierBadge(tier); badge != "" {
+		return " " + badge
+	}
+	return ""
+}
+
+// deviationSuffix affiche l'incertitude de l'Elo (" ±45"), vide si elle est inconnue
+func deviationSuffix(deviation float64) string {
+	if deviation <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" ±%.0f", deviation)
+}
+
 // RenderVersus generates the "VS" display with aligned fixed height
-func RenderVersus() string {
-	// Same height as cards (8) for perfect alignment
+func RenderVersus(layout LayoutConfig) string {
+	// Same height as cards for perfect alignment
 	vs := lipgloss.NewStyle().
 		Foreground(ColorPrimary).
 		Bold(true).
 		AlignVertical(lipgloss.Center).
 		AlignHorizontal(lipgloss.Center).
-		Width(6).
-		Height(8).
+		Width(versusWidth).
+		Height(layout.CardHeight).
 		Render("VS")
 
 	return vs
 }
 
+// RenderVersusRow generates the "VS" line between stacked cards (vertical layout)
+func RenderVersusRow() string {
+	return lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true).
+		Render("VS")
+}
+
 // RenderControls renders the controls display
-func RenderControls() string {
+// vertical shows the up/down navigation of stacked cards
+func RenderControls(keys Keymap, vertical bool) string {
 	// Shortcut style
 	keyStyle := lipgloss.NewStyle().
 		Foreground(ColorPrimary).
@@ -195,37 +261,62 @@ func RenderControls() string {
 	labelStyle := lipgloss.NewStyle().
 		Foreground(ColorMuted)
 
+	// control renders one shortcut, or nothing when the action has no key
+	control := func(key, label string) string {
+		if key == "" {
+			return ""
+		}
+		return keyStyle.Render(key) + " " + labelStyle.Render(label)
+	}
+	navigate := keys.navigationLabel(ActionFocusLeft, ActionFocusRight)
+	if vertical {
+		navigate = keys.navigationLabel(ActionUp, ActionDown)
+	}
+
 	// Main controls
-	mainControls := fmt.Sprintf("%s %s  %s %s  %s %s",
-		keyStyle.Render("←→"),
-		labelStyle.Render("navigate"),
-		keyStyle.Render("␣"),
-		labelStyle.Render("play"),
-		keyStyle.Render("↵"),
-		labelStyle.Render("vote"),
+	mainControls := joinHints(
+		control(navigate, "navigate"),
+		control(keys.label(ActionPlay), "play"),
+		control(keys.label(ActionVote), "vote"),
+		control(keys.label(ActionVoteLeft), "vote left"),
+		control(keys.label(ActionVoteRight), "vote right"),
+		control(keys.label(ActionDraw), "draw"),
 	)
 
 	// Secondary controls
-	secondaryControls := fmt.Sprintf("%s %s  %s %s  %s %s  %s %s",
-		keyStyle.Render("s"),
-		labelStyle.Render("skip"),
-		keyStyle.Render("c"),
-		labelStyle.Render("leaderboard"),
-		keyStyle.Render("g"),
-		labelStyle.Render("spotify"),
-		keyStyle.Render("q"),
-		labelStyle.Render("quit"),
+	skipControls := joinHints(
+		control(keys.label(ActionSkip), "skip (logged)"),
+		control(keys.label(ActionReshuffle), "reshuffle (not logged)"),
+	)
+	otherControls := joinHints(
+		control(keys.label(ActionLeaderboard), "leaderboard"),
+		control(keys.label(ActionOpenSpotify), "spotify"),
+		control(keys.label(ActionQuit), "quit"),
 	)
 
+	// Stacked cards are narrow: one line per group of controls
+	if vertical {
+		return lipgloss.JoinVertical(lipgloss.Center, mainControls, skipControls, otherControls)
+	}
+
 	return lipgloss.JoinVertical(
 		lipgloss.Center,
 		mainControls,
-		secondaryControls,
+		skipControls+"  "+otherControls,
 	)
 }
 
+// headerWidth is the width of the separator under the header title
+const headerWidth = 64
+
 // RenderHeader generates the application header
 func RenderHeader() string {
+	return RenderHeaderWidth(headerWidth)
+}
+
+// RenderHeaderWidth generates the application header with a separator of the given width,
+// never wider than the default one (narrow terminals)
+func RenderHeaderWidth(width int) string {
 	title := lipgloss.NewStyle().
 		Foreground(ColorPrimary).
 		Bold(true).
@@ -233,7 +324,7 @@ func RenderHeader() string {
 
 	separator := lipgloss.NewStyle().
 		Foreground(ColorBorder).
-		Render("────────────────────────────────────────────────────────────────")
+		Render(strings.Repeat("─", min(width, headerWidth)))
 
 	return lipgloss.JoinVertical(lipgloss.Center, title, separator)
 }
@@ -270,19 +361,22 @@ func RenderSeparator() string {
 
 // Fonctions utilitaires
 
-// truncate truncates a string if it's too long
-func truncate(s string, max int) string {
-	if len(s) <= max {
+// Truncate shortens a string to at most max terminal columns, ending with "..." when cut
+// Widths are measured like lipgloss does: runes are never split and full-width
+// (CJK) characters count as two columns, so truncated names keep columns aligned
+func Truncate(s string, max int) string {
+	if lipgloss.Width(s) <= max {
 		return s
 	}
 	if max <= 3 {
-		return s[:max]
+		return ansi.Truncate(s, max, "")
 	}
-	return s[:max-3] + "..."
+	return ansi.Truncate(s, max, "...")
 }
 
-// RenderAudioFeatures generates the audio features display
-func RenderAudioFeatures(af map[string]float64) string {
+// RenderAudioFeatures generates the audio features display, with each value's gap to the
+// library average when avg is known
+func RenderAudioFeatures(af map[string]float64, avg *models.AudioFeatures) string {
 	if len(af) == 0 {
 		return ErrorStyle.Render("Aucune caractéristique audio disponible")
 	}
@@ -292,20 +386,32 @@ func RenderAudioFeatures(af map[string]float64) string {
 		"",
 	}
 
+	// delta shows how far a value is from the library average, when known
+	var average models.AudioFeatures
+	if avg != nil {
+		average = *avg
+	}
+	delta := func(text string) string {
+		if avg == nil {
+			return ""
+		}
+		return lipgloss.NewStyle().Foreground(ColorMuted).Render(" (" + text + " vs your avg)")
+	}
+
 	if val, ok := af["danceability"]; ok {
-		features = append(features, renderFeature("💃 Danceability", val))
+		features = append(features, renderFeature("💃 Danceability", val)+delta(percentDelta(val, average.Danceability)))
 	}
 	if val, ok := af["energy"]; ok {
-		features = append(features, renderFeature("⚡ Energy", val))
+		features = append(features, renderFeature("⚡ Energy", val)+delta(percentDelta(val, average.Energy)))
 	}
 	if val, ok := af["valence"]; ok {
-		features = append(features, renderFeature("😊 Valence", val))
+		features = append(features, renderFeature("😊 Valence", val)+delta(percentDelta(val, average.Valence)))
 	}
 	if val, ok := af["acousticness"]; ok {
-		features = append(features, renderFeature("🎸 Acousticness", val))
+		features = append(features, renderFeature("🎸 Acousticness", val)+delta(percentDelta(val, average.Acousticness)))
 	}
 	if val, ok := af["tempo"]; ok {
-		features = append(features, renderTempoFeature("🥁 Tempo", val))
+		features = append(features, renderTempoFeature("🥁 Tempo", val)+delta(tempoDelta(val, average.Tempo)))
 	}
 
 	return ContainerStyle.Render(
@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		max  int
+		want string
+	}{
+		{"ascii fits", "Song", 4, "Song"},
+		{"ascii cut", "Long song name", 8, "Long ..."},
+
+		// Caractères pleine largeur : 2 colonnes chacun, jamais coupés en deux
+		{"cjk fits exactly", "夜に駆ける", 10, "夜に駆ける"},
+		{"cjk one column short", "夜に駆ける", 9, "夜に駆..."},
+		{"cjk odd boundary", "夜に駆ける", 8, "夜に..."},
+		{"cjk even boundary", "夜に駆ける", 7, "夜に..."},
+		{"cjk only ellipsis", "夜に駆ける", 4, "..."},
+		{"cjk no room for ellipsis", "夜に駆ける", 3, "夜"},
+		{"cjk narrower than a character", "夜に駆ける", 1, ""},
+		{"cjk zero width", "夜に駆ける", 0, ""},
+
+		{"emoji fits", "🎵🎶 Song", 9, "🎵🎶 Song"},
+		{"emoji kept whole", "🎵🎶 Song", 7, "🎵🎶..."},
+		{"emoji boundary", "🎵🎶 Song", 6, "🎵..."},
+		{"emoji no room for ellipsis", "🎵🎶 Song", 3, "🎵"},
+
+		{"arabic fits", "أغنية جميلة", 11, "أغنية جميلة"},
+		{"arabic cut", "أغنية جميلة", 6, "أغن..."},
+
+		// Les séquences ANSI ne comptent pas dans la largeur et restent fermées
+		{"ansi fits", "\x1b[1mBold title\x1b[0m", 10, "\x1b[1mBold title\x1b[0m"},
+		{"ansi cut", "\x1b[1mBold title\x1b[0m", 9, "\x1b[1mBold t...\x1b[0m"},
+		{"ansi cjk cut", "\x1b[31m夜に駆ける\x1b[0m", 7, "\x1b[31m夜に...\x1b[0m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Truncate(tt.in, tt.max)
+			if got != tt.want {
+				t.Errorf("Truncate(%q, %d) = %q, want %q", tt.in, tt.max, got, tt.want)
+			}
+			if width := lipgloss.Width(got); width > tt.max {
+				t.Errorf("Truncate(%q, %d) is %d columns wide", tt.in, tt.max, width)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"songbattle/internal/models"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// unavailableMarker précède le nom des tracks retirés du catalogue Spotify
+const unavailableMarker = "⊘ "
+
+// TrackUnavailableMsg signale un track introuvable sur Spotify, désormais marqué indisponible
+type TrackUnavailableMsg struct {
+	TrackID int64
+	Name    string
+}
+
+// trackTitle retourne le nom affiché d'un track, marqué s'il est indisponible
+func trackTitle(track models.Track) string {
+	if track.Unavailable {
+		return unavailableMarker + track.Name
+	}
+	return track.Name
+}
+
+// handleTrackUnavailable marque le track dans les vues ouvertes sans recharger le duel
+func (m Model) handleTrackUnavailable(msg TrackUnavailableMsg) (tea.Model, tea.Cmd) {
+	m.leftTrack = withUnavailable(m.leftTrack, msg.TrackID)
+	m.rightTrack = withUnavailable(m.rightTrack, msg.TrackID)
+
+	// Copie du classement : le modèle est passé par valeur
+	leaderboard := make([]models.TrackWithRating, len(m.leaderboardAll))
+	copy(leaderboard, m.leaderboardAll)
+	for i := range leaderboard {
+		if leaderboard[i].Track.ID == msg.TrackID {
+			leaderboard[i].Track.Unavailable = true
+		}
+	}
+	m.setLeaderboard(leaderboard)
+	m.libraryStats = m.refreshLibraryStats()
+
+	m.statusMessage = unavailableMarker + msg.Name + " n'existe plus sur Spotify (écarté des exports, -clean-unavailable pour le supprimer)"
+	return m, nil
+}
+
+// withUnavailable retourne une copie du track marquée indisponible si c'est le track concerné
+func withUnavailable(track *models.TrackWithRating, trackID int64) *models.TrackWithRating {
+	if track == nil || track.Track.ID != trackID {
+		return track
+	}
+	marked := *track
+	marked.Track.Unavailable = true
+	return &marked
+}
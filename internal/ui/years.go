@@ -0,0 +1,147 @@
+package ui
+
+import (
+	"fmt"
+	"songbattle/internal/export"
+	"songbattle/internal/models"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// yearRow est une ligne du bilan par année : le champion d'une décennie ou d'une année
+type yearRow struct {
+	label  string
+	track  models.TrackWithRating
+	decade bool
+}
+
+// handleShowYears affiche le champion de chaque décennie et de chaque année
+func (m Model) handleShowYears() (tea.Model, tea.Cmd) {
+	perYear, err := m.db.GetTopTrackPerYear()
+	if err != nil {
+		m.statusMessage = "⚠️  Impossible de charger les champions par année"
+		return m, nil
+	}
+
+	var rows []yearRow
+	for _, decade := range export.BuildYearReport(perYear) {
+		rows = append(rows, yearRow{label: fmt.Sprintf("%ds", decade.Decade), track: decade.Champion, decade: true})
+		for _, year := range decade.Years {
+			rows = append(rows, yearRow{label: fmt.Sprint(year.Track.Year), track: year})
+		}
+	}
+
+	m.yearRows = rows
+	m.yearCursor = 0
+	m.currentView = ViewYears
+	m.statusMessage = ""
+	return m, nil
+}
+
+// handleYearsKey gère le clavier du bilan par année (défilement et retour uniquement)
+func (m Model) handleYearsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "up", "k":
+		if m.yearCursor > 0 {
+			m.yearCursor--
+		}
+
+	case "down", "j":
+		if m.yearCursor < len(m.yearRows)-1 {
+			m.yearCursor++
+		}
+
+	case "q", "esc", "escape", "Y":
+		m.yearRows = nil
+		m.currentView = ViewDuel
+		m.statusMessage = "Back to battles"
+	}
+
+	return m, nil
+}
+
+// renderYears affiche les champions, chaque décennie suivie de ses années
+func (m Model) renderYears() string {
+	if len(m.yearRows) == 0 {
+		return lipgloss.JoinVertical(
+			lipgloss.Center,
+			RenderHeader(),
+			"",
+			"No release years known yet - import tracks from Spotify to fill them in",
+			"",
+			"Press Escape to return",
+		)
+	}
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		Width(9)
+
+	decadeStyle := labelStyle.
+		Foreground(ColorPrimary).
+		Bold(true)
+
+	nameStyle := lipgloss.NewStyle().
+		Width(40)
+
+	artistStyle := lipgloss.NewStyle().
+		Foreground(ColorSecondary).
+		Width(30)
+
+	eloStyle := lipgloss.NewStyle().
+		Foreground(ColorSuccess).
+		Width(8).
+		Align(lipgloss.Right)
+
+	selectedStyle := lipgloss.NewStyle().
+		Background(ColorPrimary).
+		Foreground(lipgloss.Color("#000000")).
+		Bold(true)
+
+	rows := m.layout().LeaderboardRows
+	start, end := scrollWindow(m.yearCursor, len(m.yearRows), rows)
+
+	var lines []string
+	for i := start; i < end; i++ {
+		row := m.yearRows[i]
+
+		label := labelStyle.Render("  " + row.label)
+		name := Truncate(trackTitle(row.track.Track), 38)
+		if row.decade {
+			label = decadeStyle.Render(row.label)
+			name = Truncate("🏆 "+trackTitle(row.track.Track), 38)
+		}
+
+		line := lipgloss.JoinHorizontal(
+			lipgloss.Top,
+			label,
+			nameStyle.Render(name),
+			artistStyle.Render(Truncate(row.track.Track.Artist, 28)),
+			eloStyle.Render(fmt.Sprintf("%d", row.track.Rating.Elo)),
+		)
+		if i == m.yearCursor {
+			line = selectedStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	controls := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		Padding(1, 0).
+		Render("↑↓ scroll  q back  •  -export-years writes this report to a text file")
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		RenderHeader(),
+		"",
+		lipgloss.NewStyle().Foreground(ColorPrimary).Bold(true).Render("📅 Champions by year"),
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, lines...),
+		controls,
+		RenderFooter(m.statusMessage),
+	)
+}